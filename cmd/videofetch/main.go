@@ -9,14 +9,19 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strconv"
 	"syscall"
 	"time"
 
 	"videofetch/internal/config"
 	"videofetch/internal/download"
 	"videofetch/internal/logging"
+	"videofetch/internal/retention"
 	"videofetch/internal/server"
+	"videofetch/internal/sink"
 	"videofetch/internal/store"
+	"videofetch/internal/thumbs"
+	"videofetch/internal/transcripts"
 )
 
 func main() {
@@ -29,6 +34,27 @@ func main() {
 	flag.StringVar(&cfg.Host, "host", cfg.Host, "Host address to bind")
 	flag.IntVar(&cfg.Workers, "workers", cfg.Workers, "Number of concurrent download workers")
 	flag.IntVar(&cfg.QueueCap, "queue", cfg.QueueCap, "Download queue capacity")
+	flag.Int64Var(&cfg.DiskReserveMB, "disk-reserve-mb", cfg.DiskReserveMB, "Free space (MiB) to keep on the output volume; downloads pause below it (0 disables)")
+	flag.DurationVar(&cfg.StallTimeout, "stall-timeout", cfg.StallTimeout, "Restart downloads that make no progress and print nothing for this long (0 disables)")
+	flag.DurationVar(&cfg.MaxJobDuration, "max-job-duration", cfg.MaxJobDuration, "Fail downloads that run longer than this (0 = unlimited)")
+	flag.DurationVar(&cfg.MetadataTimeout, "metadata-timeout", cfg.MetadataTimeout, "Time limit for each metadata probe attempt")
+	flag.IntVar(&cfg.MetadataWorkers, "metadata-workers", cfg.MetadataWorkers, "Concurrent metadata probes of pending URLs")
+	flag.IntVar(&cfg.MetadataQueue, "metadata-queue", cfg.MetadataQueue, "Pending URLs held in memory waiting for a metadata probe")
+	flag.DurationVar(&cfg.InfoJSONTTL, "info-json-ttl", cfg.InfoJSONTTL, "Reuse the metadata probe's info JSON for downloads starting within this long (0 = always extract again)")
+	flag.IntVar(&cfg.BreakerThreshold, "breaker-threshold", cfg.BreakerThreshold, "Defer a site's downloads after this many consecutive failures (0 disables)")
+	flag.DurationVar(&cfg.BreakerCooldown, "breaker-cooldown", cfg.BreakerCooldown, "How long a site's downloads stay deferred before one is tried again")
+	flag.IntVar(&cfg.RetentionDays, "retention-days", cfg.RetentionDays, "Delete completed downloads older than N days (0 disables)")
+	flag.IntVar(&cfg.RetentionKeepPerDomain, "retention-keep-per-site", cfg.RetentionKeepPerDomain, "Keep only the newest N completed downloads per site (0 disables)")
+	flag.Int64Var(&cfg.QuotaMB, "quota-mb", cfg.QuotaMB, "Cap total size (MiB) of completed downloads, evicting least recently used (0 disables)")
+	flag.DurationVar(&cfg.RetentionInterval, "retention-interval", cfg.RetentionInterval, "How often retention rules are evaluated")
+	flag.DurationVar(&cfg.TempGCGrace, "tmp-gc-grace", cfg.TempGCGrace, "Minimum age before orphaned temp dirs and partial files are removed")
+	flag.DurationVar(&cfg.TempGCInterval, "tmp-gc-interval", cfg.TempGCInterval, "How often orphaned temp dirs and partial files are swept")
+	flag.Var(cfg.LibraryFlag(), "library", `Named storage library "name=path[;template=...][;retention-days=N][;keep-per-site=N][;quota-mb=N][;max-duration=D][;archive=...]" (repeatable)`)
+	flag.Var(cfg.ArchiveFlag(), "archive", "Sidecars kept next to each download: comma list of description, info-json, comments")
+	flag.StringVar(&cfg.UploadSink, "upload-sink", cfg.UploadSink, "Upload completed downloads to s3://bucket/prefix?endpoint=...&region=... or webdav+https://host/path")
+	flag.BoolVar(&cfg.UploadDeleteLocal, "upload-delete-local", cfg.UploadDeleteLocal, "Delete local files after a successful upload")
+	flag.DurationVar(&cfg.UploadURLTTL, "upload-url-ttl", cfg.UploadURLTTL, "Lifetime of presigned links to uploaded files")
+	flag.StringVar(&cfg.TranscriptLangs, "transcript-langs", cfg.TranscriptLangs, "Subtitle languages to index for transcript search (yt-dlp --sub-langs); empty disables")
 	flag.StringVar(&cfg.DBPath, "db", "", "Path to SQLite database (default: OS cache dir: videofetch/videofetch.db)")
 	flag.StringVar(&cfg.LogLevel, "log-level", cfg.LogLevel, "Log level: debug, info, warn, error")
 	flag.BoolVar(&cfg.UnsafeLogPayloads, "unsafe-log-payloads", cfg.UnsafeLogPayloads, "Enable unsafe raw API payload logging (may leak secrets)")
@@ -48,6 +74,10 @@ func main() {
 		slog.Error("failed to resolve output directory", "error", err)
 		os.Exit(1)
 	}
+	if err := cfg.ResolveLibraries(); err != nil {
+		slog.Error("failed to resolve library paths", "error", err)
+		os.Exit(1)
+	}
 	if err := cfg.ResolveDBPath(); err != nil {
 		slog.Error("failed to resolve database path", "error", err)
 		os.Exit(1)
@@ -58,12 +88,26 @@ func main() {
 		slog.Error("failed to create output directory", "path", cfg.AbsOutputDir, "error", err)
 		os.Exit(1)
 	}
+	for _, lib := range cfg.Libraries {
+		if err := os.MkdirAll(lib.AbsPath, 0o755); err != nil {
+			slog.Error("failed to create library directory", "library", lib.Name, "path", lib.AbsPath, "error", err)
+			os.Exit(1)
+		}
+	}
+
+	// Prefer a managed yt-dlp installed by a previous self-update
+	ytdlp := download.NewYTDLPUpdater(filepath.Join(filepath.Dir(cfg.AbsDBPath), "bin"))
 
 	// Check yt-dlp presence early
 	if err := download.CheckYTDLP(); err != nil {
 		slog.Error("yt-dlp not found", "error", err)
 		os.Exit(1)
 	}
+	if v, err := ytdlp.Refresh(context.Background()); err != nil {
+		slog.Warn("failed to read yt-dlp version", "error", err)
+	} else {
+		cfg.YTDLPVersion = v
+	}
 
 	// Ensure DB directory exists
 	if err := os.MkdirAll(filepath.Dir(cfg.AbsDBPath), 0o755); err != nil {
@@ -79,13 +123,42 @@ func main() {
 	}
 	// Note: st.Close() is now called explicitly during shutdown
 
+	// Sizes changed at runtime win over defaults, but not over explicit flags
+	explicitFlags := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { explicitFlags[f.Name] = true })
+	applyPersistedPoolSize(st, cfg, explicitFlags)
+
 	// Create download manager with config
 	mgr := download.NewManager(cfg.AbsOutputDir, cfg.Workers, cfg.QueueCap)
+	if err := mgr.SetLibraries(managerLibraries(cfg)); err != nil {
+		slog.Error("invalid library configuration", "error", err)
+		os.Exit(1)
+	}
 	mgr.SetStore(st)
+	mgr.SetArchive(archiveOf(cfg.Archive))
+	mgr.SetStallTimeout(cfg.StallTimeout)
+	mgr.SetMaxJobDuration(cfg.MaxJobDuration)
+	mgr.SetMetadataTimeout(cfg.MetadataTimeout)
+	mgr.SetInfoJSONTTL(cfg.InfoJSONTTL)
+	mgr.SetCircuitBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown)
+	mgr.Events().Handle("ytdlp-breakage", download.SubscribeOptions{
+		Types:  []download.EventType{download.EventFailed},
+		Policy: download.DropOldest,
+		Buffer: 64,
+	}, func(e download.Event) { ytdlp.ObserveFailure(e.Item.Error) })
 	defer mgr.Shutdown()
 
+	// Pause and resume downloads around the free-space reserve
+	diskMon := download.NewDiskMonitor(cfg.AbsOutputDir, cfg.DiskReserveMB<<20)
+	mgr.SetDiskMonitor(diskMon)
+
+	// Restore maintenance mode before anything is dispatched
+	mgr.SetMaintenanceStore(st)
+	restoreMaintenance(st, mgr)
+
 	// Start database worker to process pending URLs
 	dbWorker := download.NewDBWorker(st, mgr)
+	dbWorker.SetMetadataPool(cfg.MetadataWorkers, cfg.MetadataQueue)
 
 	// Retry any incomplete downloads from previous sessions
 	if err := dbWorker.RetryIncompleteDownloads(); err != nil {
@@ -95,10 +168,89 @@ func main() {
 	dbWorker.Start()
 	defer dbWorker.Stop()
 
+	// Sweep temp dirs and partial files left behind by crashes or kills
+	tempJanitor := download.NewTempJanitor(mgr, st, cfg.TempGCGrace)
+	tempJanitor.Start(cfg.TempGCInterval)
+
+	// Keep local thumbnails so the dashboard never hotlinks source sites
+	thumbCache, err := thumbs.New(filepath.Join(filepath.Dir(cfg.AbsDBPath), "thumbs"), thumbs.Options{})
+	if err != nil {
+		slog.Error("failed to create thumbnail cache", "error", err)
+		os.Exit(1)
+	}
+	thumbCache.Start()
+	mgr.Events().Handle("thumbnails", download.SubscribeOptions{
+		Types:  []download.EventType{download.EventMetadata, download.EventCompleted},
+		Policy: download.DropOldest,
+		Buffer: 256,
+	}, func(e download.Event) { warmThumbnail(thumbCache, mgr, e) })
+
+	// Prune completed downloads according to retention rules
+	janitor := retention.NewWithScopes(st, retentionScopes(cfg))
+	janitor.OnRemoved(func(id int64) {
+		if err := thumbCache.Remove(id); err != nil {
+			slog.Warn("failed to remove cached thumbnail", "event", "thumbnail_remove_error", "id", id, "error", err)
+		}
+	})
+	janitor.Start(cfg.RetentionInterval)
+
+	// Copy completed downloads to remote storage
+	var uploader *sink.Uploader
+	if cfg.UploadSink != "" {
+		remote, err := sink.FromURL(cfg.UploadSink, os.Getenv)
+		if err != nil {
+			slog.Error("invalid upload sink", "error", err)
+			os.Exit(1)
+		}
+		uploader = sink.NewUploader(st, remote, cfg.AbsOutputDir, sink.UploaderOptions{
+			DeleteLocal: cfg.UploadDeleteLocal,
+			URLTTL:      cfg.UploadURLTTL,
+		})
+		mgr.Events().Handle("uploader", download.SubscribeOptions{
+			Types:  []download.EventType{download.EventCompleted},
+			Policy: download.BlockWhenFull,
+			Buffer: 256,
+		}, func(e download.Event) { uploader.Enqueue(e.Item.DBID) })
+		uploader.Start()
+	}
+
+	// Index subtitles of completed downloads for transcript search
+	var indexer *transcripts.Indexer
+	if cfg.TranscriptLangs != "" {
+		indexer = transcripts.NewIndexer(st, cfg.AbsOutputDir, transcripts.Options{Languages: cfg.TranscriptLangs})
+		mgr.Events().Handle("transcripts", download.SubscribeOptions{
+			Types:  []download.EventType{download.EventCompleted},
+			Policy: download.BlockWhenFull,
+			Buffer: 256,
+		}, func(e download.Event) { indexer.Enqueue(e.Item.DBID) })
+		indexer.Start()
+	}
+
 	// Create HTTP server
-	mux := server.New(mgr, st, cfg.AbsOutputDir, server.Options{
+	serverOpts := server.Options{
 		UnsafeLogPayloads: cfg.UnsafeLogPayloads,
-	})
+		Disk:              diskMon,
+		Retention:         janitor,
+		TempGC:            tempJanitor,
+		YTDLP:             ytdlp,
+		Pool:              mgr,
+		Maintenance:       mgr,
+		Events:            mgr.Events(),
+		Libraries:         mgr,
+		Thumbnails:        thumbCache,
+		Metadata:          mgr,
+		Breakers:          mgr,
+		Metrics:           mgr,
+		Prober:            mgr,
+		MetadataPool:      dbWorker,
+	}
+	if uploader != nil {
+		serverOpts.Remote = uploader
+	}
+	if indexer != nil {
+		serverOpts.Transcripts = indexer
+	}
+	mux := server.New(mgr, st, cfg.AbsOutputDir, serverOpts)
 
 	srv := &http.Server{
 		Addr:              cfg.Addr,
@@ -124,6 +276,7 @@ func main() {
 	// Graceful shutdown
 	shutdownCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
+	go watchMaintenanceSignal(shutdownCtx, mgr)
 	<-shutdownCtx.Done()
 	slog.Info("shutdown signal received", "event", "shutdown_start")
 
@@ -136,7 +289,145 @@ func main() {
 		logging.LogServerShutdown("http shutdown error", err)
 	}
 	mgr.Shutdown()
+	// Stop background store writers, then close the store after them
+	if uploader != nil {
+		uploader.Stop()
+	}
+	if indexer != nil {
+		indexer.Stop()
+	}
+	janitor.Stop()
+	tempJanitor.Stop()
+	thumbCache.Stop()
 	// Close store after manager shutdown to avoid race conditions
 	st.Close()
 	logging.LogServerShutdown("shutdown complete", nil)
 }
+
+// managerLibraries maps configured libraries to download destinations.
+func managerLibraries(cfg *config.Config) []download.Library {
+	libs := make([]download.Library, 0, len(cfg.Libraries))
+	for _, lib := range cfg.Libraries {
+		libs = append(libs, download.Library{Name: lib.Name, Root: lib.AbsPath, Template: lib.Template, MaxDuration: lib.MaxDuration, Archive: archiveOf(lib.Archive)})
+	}
+	return libs
+}
+
+// retentionScopes gives the default library the global rules and every named
+// library its own, each cleaned only within its root.
+func retentionScopes(cfg *config.Config) []retention.Scope {
+	scopes := []retention.Scope{{
+		Library: store.DefaultLibrary,
+		Root:    cfg.AbsOutputDir,
+		Cleaner: download.NewDownloader(cfg.AbsOutputDir),
+		Policy: retention.Policy{
+			MaxAge:        time.Duration(cfg.RetentionDays) * 24 * time.Hour,
+			KeepPerDomain: cfg.RetentionKeepPerDomain,
+			MaxTotalBytes: cfg.QuotaMB << 20,
+		},
+	}}
+	for _, lib := range cfg.Libraries {
+		scopes = append(scopes, retention.Scope{
+			Library: lib.Name,
+			Root:    lib.AbsPath,
+			Cleaner: download.NewDownloader(lib.AbsPath),
+			Policy: retention.Policy{
+				MaxAge:        time.Duration(lib.RetentionDays) * 24 * time.Hour,
+				KeepPerDomain: lib.KeepPerDomain,
+				MaxTotalBytes: lib.QuotaMB << 20,
+			},
+		})
+	}
+	return scopes
+}
+
+// warmThumbnail caches the source thumbnail once metadata is known, and
+// extracts a frame from the finished file when the site has none.
+func warmThumbnail(cache *thumbs.Cache, mgr *download.Manager, e download.Event) {
+	video := ""
+	if e.Type == download.EventCompleted && e.Item.Filename != "" {
+		if lib, ok := mgr.Library(e.Item.Library); ok {
+			video = filepath.Join(lib.Root, e.Item.Filename)
+		}
+	} else if e.Item.ThumbnailURL == "" {
+		return
+	}
+	_ = cache.Warm(context.Background(), e.Item.DBID, e.Item.ThumbnailURL, video)
+}
+
+// restoreMaintenance re-applies a maintenance hold that was on at shutdown.
+func restoreMaintenance(st *store.Store, mgr *download.Manager) {
+	raw, found, err := st.GetSetting(context.Background(), store.SettingMaintenance)
+	if err != nil {
+		slog.Warn("failed to load persisted setting", "key", store.SettingMaintenance, "error", err)
+		return
+	}
+	if !found || raw != "1" {
+		return
+	}
+	if _, err := mgr.EnterMaintenance(false); err != nil {
+		slog.Warn("failed to restore maintenance mode", "error", err)
+	}
+}
+
+// watchMaintenanceSignal toggles maintenance mode on SIGUSR1, pausing running
+// downloads when entering it. A no-op on platforms without the signal.
+func watchMaintenanceSignal(ctx context.Context, mgr *download.Manager) {
+	sigs := maintenanceSignals()
+	if len(sigs) == 0 {
+		return
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+	defer signal.Stop(ch)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ch:
+			st, err := mgr.ToggleMaintenance(true)
+			if err != nil {
+				slog.Error("maintenance toggle failed", "event", "maintenance_error", "error", err)
+				continue
+			}
+			slog.Info("maintenance toggled by signal", "event", "maintenance_signal", "active", st.Active)
+		}
+	}
+}
+
+// applyPersistedPoolSize loads worker/queue sizes saved via the admin API.
+func applyPersistedPoolSize(st *store.Store, cfg *config.Config, explicitFlags map[string]bool) {
+	ctx := context.Background()
+	for _, setting := range []struct {
+		key  string
+		flag string
+		dst  *int
+		max  int
+	}{
+		{store.SettingWorkers, "workers", &cfg.Workers, download.MaxWorkers},
+		{store.SettingQueueCapacity, "queue", &cfg.QueueCap, download.MaxQueueCapacity},
+	} {
+		if explicitFlags[setting.flag] {
+			continue
+		}
+		raw, found, err := st.GetSetting(ctx, setting.key)
+		if err != nil {
+			slog.Warn("failed to load persisted setting", "key", setting.key, "error", err)
+			continue
+		}
+		if !found {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > setting.max {
+			slog.Warn("ignoring invalid persisted setting", "key", setting.key, "value", raw)
+			continue
+		}
+		*setting.dst = n
+	}
+}
+
+// archiveOf maps configured sidecars to the downloader's archival options.
+func archiveOf(a config.Archive) download.Archive {
+	return download.Archive{Description: a.Description, InfoJSON: a.InfoJSON, Comments: a.Comments}
+}
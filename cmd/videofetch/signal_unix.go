@@ -0,0 +1,13 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// maintenanceSignals returns the signals that toggle maintenance mode.
+func maintenanceSignals() []os.Signal {
+	return []os.Signal{syscall.SIGUSR1}
+}
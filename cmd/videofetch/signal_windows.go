@@ -0,0 +1,10 @@
+//go:build windows
+
+package main
+
+import "os"
+
+// maintenanceSignals returns nil: Windows has no SIGUSR1.
+func maintenanceSignals() []os.Signal {
+	return nil
+}
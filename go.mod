@@ -4,13 +4,13 @@ go 1.23.0
 
 require (
 	github.com/a-h/templ v0.3.977
+	github.com/gorilla/websocket v1.5.3
 	modernc.org/sqlite v1.29.10
 )
 
 require (
 	github.com/dustin/go-humanize v1.0.1 // indirect
 	github.com/google/uuid v1.6.0 // indirect
-	github.com/gorilla/websocket v1.5.3 // indirect
 	github.com/hashicorp/golang-lru/v2 v2.0.7 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/ncruces/go-strftime v0.1.9 // indirect
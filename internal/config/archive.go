@@ -0,0 +1,77 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// Archive selects the sidecar files kept next to each download for archival.
+type Archive struct {
+	Description bool // the video description as <name>.description
+	InfoJSON    bool // yt-dlp's metadata as <name>.info.json
+	Comments    bool // the comment thread as <name>.comments.json
+}
+
+// archiveKinds are the accepted --archive / archive= entries, in display order.
+var archiveKinds = []string{"description", "info-json", "comments"}
+
+// ParseArchive parses a comma-separated list of sidecar kinds, e.g.
+// "description,info-json,comments". An empty list or "none" archives nothing.
+func ParseArchive(spec string) (Archive, error) {
+	var a Archive
+	for _, kind := range strings.Split(spec, ",") {
+		switch strings.TrimSpace(kind) {
+		case "", "none":
+		case "description":
+			a.Description = true
+		case "info-json":
+			a.InfoJSON = true
+		case "comments":
+			a.Comments = true
+		default:
+			return Archive{}, fmt.Errorf("unknown archive sidecar %q (want %s)", strings.TrimSpace(kind), strings.Join(archiveKinds, ", "))
+		}
+	}
+	return a, nil
+}
+
+// String returns the canonical list accepted by ParseArchive, or "none".
+func (a Archive) String() string {
+	var kinds []string
+	for _, kind := range archiveKinds {
+		if (kind == "description" && a.Description) || (kind == "info-json" && a.InfoJSON) || (kind == "comments" && a.Comments) {
+			kinds = append(kinds, kind)
+		}
+	}
+	if len(kinds) == 0 {
+		return "none"
+	}
+	return strings.Join(kinds, ",")
+}
+
+// ArchiveFlag returns a flag.Value that sets c.Archive, the sidecars kept for
+// the default library.
+func (c *Config) ArchiveFlag() flag.Value {
+	return &archiveFlag{cfg: c}
+}
+
+type archiveFlag struct {
+	cfg *Config
+}
+
+func (f *archiveFlag) String() string {
+	if f == nil || f.cfg == nil {
+		return ""
+	}
+	return f.cfg.Archive.String()
+}
+
+func (f *archiveFlag) Set(spec string) error {
+	a, err := ParseArchive(spec)
+	if err != nil {
+		return err
+	}
+	f.cfg.Archive = a
+	return nil
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -22,29 +23,80 @@ type Config struct {
 	DBPath       string // user-provided
 	AbsDBPath    string // resolved/absolute path
 
+	// Libraries are extra named destinations besides the default --output-dir
+	Libraries []Library
+
 	// Download behavior
-	Workers  int // concurrent workers
-	QueueCap int // max pending jobs
+	Workers       int           // concurrent workers
+	QueueCap      int           // max pending jobs
+	DiskReserveMB int64         // free space to keep on the output volume; 0 disables the guard
+	StallTimeout  time.Duration // restart downloads without progress or output for this long; 0 disables
+	Archive       Archive       // sidecars kept next to each download in the default library
+
+	// Time limits
+	MaxJobDuration  time.Duration // wall-clock limit per download; 0 means unlimited
+	MetadataTimeout time.Duration // limit per metadata probe attempt
+	InfoJSONTTL     time.Duration // reuse the probe's info JSON for downloads starting within this; 0 disables
+
+	// Metadata probes of pending rows
+	MetadataWorkers int // concurrent metadata probes
+	MetadataQueue   int // pending rows held in memory waiting for a probe
+
+	// Per-site circuit breaker
+	BreakerThreshold int           // consecutive failures that open a site's breaker; 0 disables
+	BreakerCooldown  time.Duration // how long an open breaker defers jobs before probing
+
+	// Retention (all zero values disable the corresponding rule)
+	RetentionDays          int           // delete completed downloads older than N days
+	RetentionKeepPerDomain int           // keep only the newest N completed downloads per site
+	QuotaMB                int64         // cap total bytes of completed downloads, evicting least recently used
+	RetentionInterval      time.Duration // how often the retention job runs
+
+	// Orphaned temp directory / partial file cleanup
+	TempGCGrace    time.Duration // leave orphans younger than this alone
+	TempGCInterval time.Duration // how often the temp janitor runs after startup
+
+	// Remote upload of completed downloads
+	UploadSink        string        // s3://... or webdav+https://...; empty disables uploads
+	UploadDeleteLocal bool          // remove local files once uploaded
+	UploadURLTTL      time.Duration // lifetime of presigned links served by /api/download_file
+
+	// Transcript indexing of completed downloads
+	TranscriptLangs string // yt-dlp --sub-langs for subtitles and auto-captions; empty disables indexing
 
 	// Logging
 	LogLevel          string // debug|info|warn|error
 	UnsafeLogPayloads bool
 
 	// Validation & computed
-	Version   string    // app version
-	StartTime time.Time // when the app started
+	Version      string    // app version
+	YTDLPVersion string    // detected at startup
+	StartTime    time.Time // when the app started
 }
 
 // New creates a Config with default values
 func New() *Config {
 	return &Config{
-		Host:      "0.0.0.0",
-		Port:      8080,
-		Workers:   4,
-		QueueCap:  128,
-		LogLevel:  "info",
-		StartTime: time.Now(),
-		Version:   "1.0.0", // TODO: could be set from build flags
+		Host:              "0.0.0.0",
+		Port:              8080,
+		Workers:           4,
+		QueueCap:          128,
+		DiskReserveMB:     1024,
+		StallTimeout:      10 * time.Minute,
+		MetadataTimeout:   30 * time.Second,
+		InfoJSONTTL:       30 * time.Minute,
+		MetadataWorkers:   4,
+		MetadataQueue:     64,
+		BreakerThreshold:  5,
+		BreakerCooldown:   5 * time.Minute,
+		RetentionInterval: time.Hour,
+		TempGCGrace:       time.Hour,
+		TempGCInterval:    30 * time.Minute,
+		UploadURLTTL:      time.Hour,
+		TranscriptLangs:   "en.*,en",
+		LogLevel:          "info",
+		StartTime:         time.Now(),
+		Version:           "1.0.0", // TODO: could be set from build flags
 	}
 }
 
@@ -68,6 +120,95 @@ func (c *Config) Validate() error {
 		c.QueueCap = 128
 	}
 
+	// Validate disk reserve
+	if c.DiskReserveMB < 0 {
+		return fmt.Errorf("invalid disk reserve: %d (must be >= 0)", c.DiskReserveMB)
+	}
+
+	// Validate stall watchdog
+	if c.StallTimeout < 0 {
+		return fmt.Errorf("invalid stall timeout: %s (must be >= 0)", c.StallTimeout)
+	}
+
+	// Validate time limits
+	if c.MaxJobDuration < 0 {
+		return fmt.Errorf("invalid max job duration: %s (must be >= 0)", c.MaxJobDuration)
+	}
+	if c.MetadataTimeout < 0 {
+		return fmt.Errorf("invalid metadata timeout: %s (must be >= 0)", c.MetadataTimeout)
+	}
+	if c.MetadataTimeout == 0 {
+		c.MetadataTimeout = 30 * time.Second
+	}
+	if c.InfoJSONTTL < 0 {
+		return fmt.Errorf("invalid info JSON TTL: %s (must be >= 0)", c.InfoJSONTTL)
+	}
+
+	// Validate metadata pool
+	if c.MetadataWorkers < 1 {
+		c.MetadataWorkers = 4
+	}
+	if c.MetadataQueue < 1 {
+		c.MetadataQueue = 64
+	}
+
+	// Validate circuit breaker
+	if c.BreakerThreshold < 0 {
+		return fmt.Errorf("invalid breaker threshold: %d (must be >= 0)", c.BreakerThreshold)
+	}
+	if c.BreakerThreshold > 0 && c.BreakerCooldown <= 0 {
+		return fmt.Errorf("invalid breaker cooldown: %s (must be > 0)", c.BreakerCooldown)
+	}
+
+	// Validate retention rules
+	if c.RetentionDays < 0 {
+		return fmt.Errorf("invalid retention days: %d (must be >= 0)", c.RetentionDays)
+	}
+	if c.RetentionKeepPerDomain < 0 {
+		return fmt.Errorf("invalid retention keep-per-domain: %d (must be >= 0)", c.RetentionKeepPerDomain)
+	}
+	if c.QuotaMB < 0 {
+		return fmt.Errorf("invalid quota: %d (must be >= 0)", c.QuotaMB)
+	}
+	if c.RetentionInterval <= 0 {
+		c.RetentionInterval = time.Hour
+	}
+
+	// Validate temp janitor timings
+	if c.TempGCGrace < 0 {
+		return fmt.Errorf("invalid temp gc grace: %s (must be >= 0)", c.TempGCGrace)
+	}
+	if c.TempGCInterval <= 0 {
+		c.TempGCInterval = 30 * time.Minute
+	}
+
+	// Validate named libraries
+	if err := c.validateLibraries(); err != nil {
+		return err
+	}
+
+	// Validate upload sink
+	if c.UploadSink != "" {
+		scheme, _, _ := strings.Cut(c.UploadSink, "://")
+		if scheme != "s3" && scheme != "webdav+http" && scheme != "webdav+https" {
+			return fmt.Errorf("invalid upload sink: %q (want s3://, webdav+http:// or webdav+https://)", redactSink(c.UploadSink))
+		}
+	} else if c.UploadDeleteLocal {
+		return fmt.Errorf("--upload-delete-local requires --upload-sink")
+	}
+	if c.UploadURLTTL <= 0 {
+		c.UploadURLTTL = time.Hour
+	}
+	if c.UploadURLTTL > 7*24*time.Hour {
+		return fmt.Errorf("invalid upload URL TTL: %s (must be <= 168h)", c.UploadURLTTL)
+	}
+
+	// Validate transcript languages; they are passed to yt-dlp as one argument
+	c.TranscriptLangs = strings.TrimSpace(c.TranscriptLangs)
+	if strings.HasPrefix(c.TranscriptLangs, "-") || strings.ContainsAny(c.TranscriptLangs, " \t\n") {
+		return fmt.Errorf("invalid transcript languages: %q (want a comma-separated list such as en.*,de)", c.TranscriptLangs)
+	}
+
 	// Validate log level
 	validLevels := []string{"debug", "info", "warn", "error"}
 	c.LogLevel = strings.ToLower(c.LogLevel)
@@ -171,21 +312,55 @@ func (c *Config) String() string {
   Files:
     OutputDir: %s (resolved: %s)
     DBPath: %s (resolved: %s)
+    Libraries: %s
   Download:
     Workers: %d
     QueueCap: %d
+    DiskReserveMB: %d
+    StallTimeout: %s
+    Archive: %s
+    MaxJobDuration: %s
+    MetadataTimeout: %s
+    InfoJSONTTL: %s
+    MetadataWorkers: %d
+    MetadataQueue: %d
+    BreakerThreshold: %d
+    BreakerCooldown: %s
+  Retention:
+    RetentionDays: %d
+    RetentionKeepPerDomain: %d
+    QuotaMB: %d
+    RetentionInterval: %s
+  TempGC:
+    Grace: %s
+    Interval: %s
+  Upload:
+    Sink: %s
+    DeleteLocal: %t
+    URLTTL: %s
+  Transcripts:
+    Languages: %s
   Logging:
     LogLevel: %s
     UnsafeLogPayloads: %t
   Meta:
     Version: %s
+    YTDLPVersion: %s
     StartTime: %s
 }`, c.Host, c.Port, c.Addr,
 		c.OutputDir, c.AbsOutputDir,
 		c.DBPath, c.AbsDBPath,
-		c.Workers, c.QueueCap,
+		strings.Join(c.LibraryNames(), ", "),
+		c.Workers, c.QueueCap, c.DiskReserveMB, c.StallTimeout, c.Archive,
+		c.MaxJobDuration, c.MetadataTimeout, c.InfoJSONTTL,
+		c.MetadataWorkers, c.MetadataQueue,
+		c.BreakerThreshold, c.BreakerCooldown,
+		c.RetentionDays, c.RetentionKeepPerDomain, c.QuotaMB, c.RetentionInterval,
+		c.TempGCGrace, c.TempGCInterval,
+		redactSink(c.UploadSink), c.UploadDeleteLocal, c.UploadURLTTL,
+		c.TranscriptLangs,
 		c.LogLevel, c.UnsafeLogPayloads,
-		c.Version, c.StartTime.Format(time.RFC3339))
+		c.Version, c.YTDLPVersion, c.StartTime.Format(time.RFC3339))
 }
 
 // Summary returns a one-line summary of key configuration
@@ -194,12 +369,41 @@ func (c *Config) Summary() map[string]any {
 		"addr":                c.Addr,
 		"output_dir":          c.AbsOutputDir,
 		"db_path":             c.AbsDBPath,
+		"libraries":           c.LibraryNames(),
 		"workers":             c.Workers,
 		"queue":               c.QueueCap,
+		"disk_reserve_mb":     c.DiskReserveMB,
+		"stall_timeout":       c.StallTimeout.String(),
+		"archive":             c.Archive.String(),
+		"max_job_duration":    c.MaxJobDuration.String(),
+		"metadata_timeout":    c.MetadataTimeout.String(),
+		"info_json_ttl":       c.InfoJSONTTL.String(),
+		"metadata_workers":    c.MetadataWorkers,
+		"metadata_queue":      c.MetadataQueue,
+		"breaker_threshold":   c.BreakerThreshold,
+		"breaker_cooldown":    c.BreakerCooldown.String(),
+		"retention_days":      c.RetentionDays,
+		"retention_per_site":  c.RetentionKeepPerDomain,
+		"quota_mb":            c.QuotaMB,
+		"upload_sink":         redactSink(c.UploadSink),
+		"transcript_langs":    c.TranscriptLangs,
 		"log_level":           c.LogLevel,
 		"unsafe_log_payloads": c.UnsafeLogPayloads,
 		"version":             c.Version,
+		"ytdlp_version":       c.YTDLPVersion,
+	}
+}
+
+// redactSink drops credentials and options from an upload sink URL for display.
+func redactSink(spec string) string {
+	if spec == "" {
+		return ""
+	}
+	u, err := url.Parse(spec)
+	if err != nil {
+		return "<invalid>"
 	}
+	return u.Scheme + "://" + u.Host + u.Path
 }
 
 // defaultCacheDBPath returns the cross-platform default path for the SQLite DB
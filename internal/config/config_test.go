@@ -6,6 +6,7 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestNew(t *testing.T) {
@@ -23,6 +24,27 @@ func TestNew(t *testing.T) {
 	if cfg.QueueCap != 128 {
 		t.Errorf("expected default QueueCap = 128, got %d", cfg.QueueCap)
 	}
+	if cfg.DiskReserveMB != 1024 {
+		t.Errorf("expected default DiskReserveMB = 1024, got %d", cfg.DiskReserveMB)
+	}
+	if cfg.MaxJobDuration != 0 || cfg.MetadataTimeout != 30*time.Second {
+		t.Errorf("expected no job limit and a 30s metadata timeout by default, got %s and %s", cfg.MaxJobDuration, cfg.MetadataTimeout)
+	}
+	if cfg.MetadataWorkers != 4 || cfg.MetadataQueue != 64 {
+		t.Errorf("expected metadata pool defaults 4 workers / 64 queued, got %d / %d", cfg.MetadataWorkers, cfg.MetadataQueue)
+	}
+	if cfg.InfoJSONTTL != 30*time.Minute {
+		t.Errorf("expected default InfoJSONTTL = 30m, got %s", cfg.InfoJSONTTL)
+	}
+	if cfg.BreakerThreshold != 5 || cfg.BreakerCooldown != 5*time.Minute {
+		t.Errorf("expected breaker defaults 5 failures / 5m, got %d / %s", cfg.BreakerThreshold, cfg.BreakerCooldown)
+	}
+	if cfg.StallTimeout != 10*time.Minute {
+		t.Errorf("expected default StallTimeout = 10m, got %s", cfg.StallTimeout)
+	}
+	if cfg.RetentionInterval != time.Hour {
+		t.Errorf("expected default RetentionInterval = 1h, got %s", cfg.RetentionInterval)
+	}
 	if cfg.LogLevel != "info" {
 		t.Errorf("expected default LogLevel = info, got %s", cfg.LogLevel)
 	}
@@ -76,6 +98,56 @@ func TestValidate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "invalid log level",
 		},
+		{
+			name: "invalid disk reserve",
+			cfg: &Config{
+				Port:          8080,
+				DiskReserveMB: -1,
+				LogLevel:      "info",
+			},
+			wantErr: true,
+			errMsg:  "invalid disk reserve",
+		},
+		{
+			name: "invalid stall timeout",
+			cfg: &Config{
+				Port:         8080,
+				StallTimeout: -time.Second,
+				LogLevel:     "info",
+			},
+			wantErr: true,
+			errMsg:  "invalid stall timeout",
+		},
+		{
+			name: "invalid max job duration",
+			cfg: &Config{
+				Port:           8080,
+				MaxJobDuration: -time.Hour,
+				LogLevel:       "info",
+			},
+			wantErr: true,
+			errMsg:  "invalid max job duration",
+		},
+		{
+			name: "breaker without cooldown",
+			cfg: &Config{
+				Port:             8080,
+				BreakerThreshold: 3,
+				LogLevel:         "info",
+			},
+			wantErr: true,
+			errMsg:  "invalid breaker cooldown",
+		},
+		{
+			name: "invalid quota",
+			cfg: &Config{
+				Port:     8080,
+				QuotaMB:  -5,
+				LogLevel: "info",
+			},
+			wantErr: true,
+			errMsg:  "invalid quota",
+		},
 		{
 			name: "auto-fix workers",
 			cfg: &Config{
@@ -321,3 +393,124 @@ func TestSummary(t *testing.T) {
 		t.Errorf("Summary() unsafe_log_payloads = %v, want true", summary["unsafe_log_payloads"])
 	}
 }
+
+func TestParseLibraryAndValidate(t *testing.T) {
+	lib, err := ParseLibrary("music=~/Music;template=%(artist)s/%(title)s.%(ext)s;retention-days=30;quota-mb=512;max-duration=2h")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if lib.Name != "music" || lib.Path != "~/Music" || lib.Template != "%(artist)s/%(title)s.%(ext)s" || lib.RetentionDays != 30 || lib.QuotaMB != 512 || lib.MaxDuration != 2*time.Hour {
+		t.Fatalf("unexpected library: %+v", lib)
+	}
+	for _, bad := range []string{"music", "=/x", "music=/x;bogus=1", "music=/x;quota-mb=lots", "music=/x;max-duration=forever"} {
+		if _, err := ParseLibrary(bad); err == nil {
+			t.Errorf("expected parse error for %q", bad)
+		}
+	}
+
+	tests := []struct {
+		name string
+		libs []Library
+	}{
+		{"reserved", []Library{{Name: "default", Path: "/x"}}},
+		{"bad name", []Library{{Name: "Movies!", Path: "/x"}}},
+		{"duplicate", []Library{{Name: "a", Path: "/x"}, {Name: "a", Path: "/y"}}},
+		{"absolute template", []Library{{Name: "a", Path: "/x", Template: "/etc/%(id)s"}}},
+		{"escaping template", []Library{{Name: "a", Path: "/x", Template: "../%(id)s"}}},
+		{"negative", []Library{{Name: "a", Path: "/x", RetentionDays: -1}}},
+		{"negative max duration", []Library{{Name: "a", Path: "/x", MaxDuration: -time.Minute}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := New()
+			cfg.Libraries = tt.libs
+			if err := cfg.Validate(); err == nil {
+				t.Fatalf("expected validation error")
+			}
+		})
+	}
+
+	cfg := New()
+	if err := cfg.LibraryFlag().Set("scratch=" + t.TempDir()); err != nil {
+		t.Fatalf("flag set: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if err := cfg.ResolveLibraries(); err != nil || !filepath.IsAbs(cfg.Libraries[0].AbsPath) {
+		t.Fatalf("expected absolute library path, got %+v (%v)", cfg.Libraries, err)
+	}
+	if names := cfg.LibraryNames(); strings.Join(names, ",") != "default,scratch" {
+		t.Fatalf("unexpected names %v", names)
+	}
+}
+
+func TestValidateUploadSink(t *testing.T) {
+	cfg := New()
+	cfg.UploadSink = "ftp://host/archive"
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("expected unsupported sink scheme rejected")
+	}
+	cfg = New()
+	cfg.UploadDeleteLocal = true
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("expected --upload-delete-local without a sink rejected")
+	}
+	cfg = New()
+	cfg.UploadSink = "s3://bucket/videos?endpoint=http://127.0.0.1:9000"
+	cfg.UploadURLTTL = 0
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if cfg.UploadURLTTL != time.Hour {
+		t.Fatalf("expected default url ttl, got %v", cfg.UploadURLTTL)
+	}
+	if got := redactSink("webdav+https://alice:pw@dav.example.com/archive"); strings.Contains(got, "pw") {
+		t.Fatalf("expected credentials redacted, got %q", got)
+	}
+}
+
+func TestValidateTranscriptLangs(t *testing.T) {
+	for _, bad := range []string{"--exec rm", "en, de"} {
+		cfg := New()
+		cfg.TranscriptLangs = bad
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("expected %q rejected", bad)
+		}
+	}
+	cfg := New()
+	cfg.TranscriptLangs = " "
+	if err := cfg.Validate(); err != nil || cfg.TranscriptLangs != "" {
+		t.Fatalf("expected blank languages to disable indexing, got %q (%v)", cfg.TranscriptLangs, err)
+	}
+}
+
+func TestParseArchive(t *testing.T) {
+	lib, err := ParseLibrary("archive=/srv/archive;archive=description, comments")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if want := (Archive{Description: true, Comments: true}); lib.Archive != want {
+		t.Fatalf("unexpected archive: %+v", lib.Archive)
+	}
+	if got := lib.Archive.String(); got != "description,comments" {
+		t.Fatalf("String() = %q", got)
+	}
+	if _, err := ParseLibrary("a=/x;archive=thumbnail"); err == nil {
+		t.Fatalf("expected unknown sidecar to be rejected")
+	}
+
+	cfg := New()
+	if cfg.Archive.String() != "none" {
+		t.Fatalf("expected no sidecars by default, got %q", cfg.Archive.String())
+	}
+	if err := cfg.ArchiveFlag().Set("info-json,description"); err != nil {
+		t.Fatalf("flag set: %v", err)
+	}
+	if want := (Archive{Description: true, InfoJSON: true}); cfg.Archive != want {
+		t.Fatalf("unexpected archive: %+v", cfg.Archive)
+	}
+	if cfg.Summary()["archive"] != "description,info-json" {
+		t.Fatalf("unexpected summary archive %v", cfg.Summary()["archive"])
+	}
+}
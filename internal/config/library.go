@@ -0,0 +1,178 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultLibraryName is the library backed by --output-dir.
+const DefaultLibraryName = "default"
+
+var libraryNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// Library is a named download destination added with --library.
+// Retention fields apply to this library only; zero disables a rule.
+type Library struct {
+	Name          string
+	Path          string // user-provided
+	AbsPath       string // resolved/absolute path
+	Template      string // yt-dlp output template; empty uses the default
+	RetentionDays int
+	KeepPerDomain int
+	QuotaMB       int64
+	MaxDuration   time.Duration // per-job wall-clock limit; 0 uses --max-job-duration
+	Archive       Archive       // sidecars kept next to each download
+}
+
+// LibraryFlag returns a flag.Value that appends each
+// "name=path[;template=...][;retention-days=N][;keep-per-site=N][;quota-mb=N][;max-duration=D][;archive=...]"
+// occurrence to c.Libraries.
+func (c *Config) LibraryFlag() flag.Value {
+	return &libraryFlag{cfg: c}
+}
+
+type libraryFlag struct {
+	cfg *Config
+}
+
+func (f *libraryFlag) String() string {
+	if f == nil || f.cfg == nil {
+		return ""
+	}
+	names := make([]string, 0, len(f.cfg.Libraries))
+	for _, lib := range f.cfg.Libraries {
+		names = append(names, lib.Name+"="+lib.Path)
+	}
+	return strings.Join(names, ",")
+}
+
+func (f *libraryFlag) Set(spec string) error {
+	lib, err := ParseLibrary(spec)
+	if err != nil {
+		return err
+	}
+	f.cfg.Libraries = append(f.cfg.Libraries, lib)
+	return nil
+}
+
+// ParseLibrary parses a --library value. Options are separated by ';' because
+// yt-dlp templates may contain commas.
+func ParseLibrary(spec string) (Library, error) {
+	parts := strings.Split(spec, ";")
+	name, path, ok := strings.Cut(parts[0], "=")
+	if !ok || strings.TrimSpace(name) == "" || strings.TrimSpace(path) == "" {
+		return Library{}, fmt.Errorf("invalid library %q (want name=path)", spec)
+	}
+	lib := Library{Name: strings.TrimSpace(name), Path: strings.TrimSpace(path)}
+	for _, opt := range parts[1:] {
+		if strings.TrimSpace(opt) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(opt, "=")
+		if !ok {
+			return Library{}, fmt.Errorf("invalid library option %q in %q", opt, spec)
+		}
+		key = strings.TrimSpace(key)
+		var err error
+		switch key {
+		case "template":
+			lib.Template = value
+		case "retention-days":
+			lib.RetentionDays, err = strconv.Atoi(value)
+		case "keep-per-site":
+			lib.KeepPerDomain, err = strconv.Atoi(value)
+		case "quota-mb":
+			lib.QuotaMB, err = strconv.ParseInt(value, 10, 64)
+		case "max-duration":
+			lib.MaxDuration, err = time.ParseDuration(value)
+		case "archive":
+			lib.Archive, err = ParseArchive(value)
+		default:
+			return Library{}, fmt.Errorf("unknown library option %q in %q", key, spec)
+		}
+		if err != nil {
+			return Library{}, fmt.Errorf("invalid library option %s=%q: %w", key, value, err)
+		}
+	}
+	return lib, nil
+}
+
+// validateLibraries checks names, templates and retention values.
+func (c *Config) validateLibraries() error {
+	seen := make(map[string]struct{}, len(c.Libraries))
+	for _, lib := range c.Libraries {
+		if !libraryNamePattern.MatchString(lib.Name) {
+			return fmt.Errorf("invalid library name: %q (lowercase letters, digits, '-' and '_', up to 32 chars)", lib.Name)
+		}
+		if lib.Name == DefaultLibraryName {
+			return fmt.Errorf("library name %q is reserved for --output-dir", lib.Name)
+		}
+		if _, dup := seen[lib.Name]; dup {
+			return fmt.Errorf("duplicate library: %s", lib.Name)
+		}
+		seen[lib.Name] = struct{}{}
+		if err := validateOutputTemplate(lib.Template); err != nil {
+			return fmt.Errorf("library %s: %w", lib.Name, err)
+		}
+		if lib.RetentionDays < 0 || lib.KeepPerDomain < 0 || lib.QuotaMB < 0 {
+			return fmt.Errorf("library %s: retention values must be >= 0", lib.Name)
+		}
+		if lib.MaxDuration < 0 {
+			return fmt.Errorf("library %s: max-duration must be >= 0", lib.Name)
+		}
+	}
+	return nil
+}
+
+// validateOutputTemplate keeps templates relative to the library root.
+func validateOutputTemplate(tpl string) error {
+	if tpl == "" {
+		return nil
+	}
+	if filepath.IsAbs(tpl) || strings.HasPrefix(tpl, "/") || strings.HasPrefix(tpl, `\`) {
+		return fmt.Errorf("output template must be relative: %q", tpl)
+	}
+	for _, seg := range strings.FieldsFunc(tpl, func(r rune) bool { return r == '/' || r == '\\' }) {
+		if seg == ".." {
+			return fmt.Errorf("output template must not leave the library root: %q", tpl)
+		}
+	}
+	return nil
+}
+
+// ResolveLibraries expands ~ and resolves each library path to an absolute path.
+func (c *Config) ResolveLibraries() error {
+	for i := range c.Libraries {
+		lib := &c.Libraries[i]
+		path := lib.Path
+		if path == "~" || strings.HasPrefix(path, "~/") {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return fmt.Errorf("expand home directory: %w", err)
+			}
+			path = filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return fmt.Errorf("resolve absolute path for library %s: %w", lib.Name, err)
+		}
+		lib.AbsPath = abs
+	}
+	return nil
+}
+
+// LibraryNames returns the configured names, default first.
+func (c *Config) LibraryNames() []string {
+	names := make([]string, 0, len(c.Libraries)+1)
+	names = append(names, DefaultLibraryName)
+	for _, lib := range c.Libraries {
+		names = append(names, lib.Name)
+	}
+	return names
+}
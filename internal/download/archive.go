@@ -0,0 +1,151 @@
+package download
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Sidecar kinds, as served by the sidecars endpoint.
+const (
+	SidecarDescription = "description"
+	SidecarInfoJSON    = "info_json"
+	SidecarComments    = "comments"
+)
+
+// Sidecar file suffixes. The comments file is written by the downloader from
+// the info JSON, since yt-dlp keeps comments inside it.
+const (
+	descriptionSuffix = ".description"
+	infoJSONSuffix    = ".info.json"
+	commentsSuffix    = ".comments.json"
+)
+
+// Archive selects the sidecar files kept next to each download.
+type Archive struct {
+	Description bool `json:"description,omitempty"`
+	InfoJSON    bool `json:"info_json,omitempty"`
+	Comments    bool `json:"comments,omitempty"`
+}
+
+// args returns the yt-dlp flags writing the sidecars. Comments need the info
+// JSON, which is removed again after the run unless it was asked for too.
+func (a Archive) args() []string {
+	var args []string
+	if a.Description {
+		args = append(args, "--write-description")
+	}
+	if a.InfoJSON || a.Comments {
+		args = append(args, "--write-info-json")
+	}
+	if a.Comments {
+		args = append(args, "--write-comments")
+	}
+	return args
+}
+
+// SetArchive selects the sidecars written next to each download.
+func (d *Downloader) SetArchive(a Archive) {
+	d.archive = a
+}
+
+// SetArchive selects the sidecars written next to downloads in the default library.
+func (m *Manager) SetArchive(a Archive) {
+	if m.downloader != nil {
+		m.downloader.SetArchive(a)
+	}
+}
+
+// Sidecar is an archival file written next to a download.
+type Sidecar struct {
+	Kind     string `json:"kind"`
+	Filename string `json:"filename"`
+	Path     string `json:"-"` // absolute path of the file
+}
+
+// SidecarKind classifies a path by its suffix; "" means it is no sidecar.
+func SidecarKind(path string) string {
+	name := filepath.Base(path)
+	switch {
+	case strings.HasSuffix(name, commentsSuffix):
+		return SidecarComments
+	case strings.HasSuffix(name, infoJSONSuffix):
+		return SidecarInfoJSON
+	case strings.HasSuffix(name, descriptionSuffix):
+		return SidecarDescription
+	}
+	return ""
+}
+
+// Sidecars picks the archival files out of a download's tracked artifact
+// paths, one per kind, ordered by kind.
+func Sidecars(artifacts []string) []Sidecar {
+	seen := make(map[string]bool)
+	var out []Sidecar
+	for _, p := range artifacts {
+		// Split chapter pieces never carry sidecars.
+		if strings.HasSuffix(filepath.Base(filepath.Dir(p)), chapterDirSuffix) {
+			continue
+		}
+		kind := SidecarKind(p)
+		if kind == "" || seen[kind] {
+			continue
+		}
+		seen[kind] = true
+		out = append(out, Sidecar{Kind: kind, Filename: filepath.Base(p), Path: p})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Kind < out[j].Kind })
+	return out
+}
+
+// writeCommentsSidecar copies the comments out of the info JSON among paths
+// into a comments file next to it, and drops the info JSON when it was only
+// written for its comments. It returns the run's artifact paths updated to
+// match.
+func (d *Downloader) writeCommentsSidecar(paths []string) ([]string, error) {
+	info := ""
+	for _, p := range paths {
+		if SidecarKind(p) == SidecarInfoJSON {
+			info = p
+			break
+		}
+	}
+	if info == "" {
+		return paths, nil
+	}
+	raw, err := os.ReadFile(info)
+	if err != nil {
+		return paths, fmt.Errorf("read info json: %w", err)
+	}
+	var doc struct {
+		Comments json.RawMessage `json:"comments"`
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return paths, fmt.Errorf("parse info json: %w", err)
+	}
+	comments := doc.Comments
+	if len(comments) == 0 || string(comments) == "null" {
+		comments = json.RawMessage("[]")
+	}
+	out := strings.TrimSuffix(info, infoJSONSuffix) + commentsSuffix
+	if err := os.WriteFile(out, comments, 0o644); err != nil {
+		return paths, fmt.Errorf("write comments: %w", err)
+	}
+	paths = append(paths, out)
+	if d.archive.InfoJSON {
+		return paths, nil
+	}
+	if err := os.Remove(info); err != nil && !os.IsNotExist(err) {
+		return paths, fmt.Errorf("remove info json: %w", err)
+	}
+	kept := paths[:0]
+	for _, p := range paths {
+		if p != info {
+			kept = append(kept, p)
+		}
+	}
+	return kept, nil
+}
@@ -0,0 +1,115 @@
+package download
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestArchive_Args(t *testing.T) {
+	tests := []struct {
+		archive Archive
+		want    string
+	}{
+		{Archive{}, ""},
+		{Archive{Description: true}, "--write-description"},
+		{Archive{InfoJSON: true}, "--write-info-json"},
+		{Archive{Comments: true}, "--write-info-json --write-comments"},
+		{Archive{Description: true, InfoJSON: true, Comments: true}, "--write-description --write-info-json --write-comments"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(tt.archive.args(), " "); got != tt.want {
+			t.Errorf("%+v: got %q, want %q", tt.archive, got, tt.want)
+		}
+	}
+}
+
+func TestExtractArtifactPaths_Sidecars(t *testing.T) {
+	out := strings.Join([]string{
+		"[info] Writing video description to: Title-id.description",
+		"[info] Writing video metadata as JSON to: /out/Title-id.info.json",
+		"[info] Video description is already present",
+		"[download] Destination: Title-id.mp4",
+	}, "\n")
+	got := extractArtifactPaths(out, "/out")
+	want := []string{"/out/Title-id.description", "/out/Title-id.info.json", "/out/Title-id.mp4"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestSidecars_PicksOnePerKind(t *testing.T) {
+	got := Sidecars([]string{
+		"/v/Title-id.mp4",
+		"/v/Title-id.info.json",
+		"/v/Title-id.comments.json",
+		"/v/Title-id.description",
+		"/v/Title-id.chapters/001 - Intro.description",
+		"/v/Other-id.description",
+	})
+	want := []Sidecar{
+		{Kind: SidecarComments, Filename: "Title-id.comments.json", Path: "/v/Title-id.comments.json"},
+		{Kind: SidecarDescription, Filename: "Title-id.description", Path: "/v/Title-id.description"},
+		{Kind: SidecarInfoJSON, Filename: "Title-id.info.json", Path: "/v/Title-id.info.json"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("sidecar %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestWriteCommentsSidecar(t *testing.T) {
+	dir := t.TempDir()
+	info := filepath.Join(dir, "Title-id.info.json")
+	video := filepath.Join(dir, "Title-id.mp4")
+	write := func() {
+		if err := os.WriteFile(info, []byte(`{"id":"id","comments":[{"text":"first"}]}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Comments only: the info JSON is dropped once the comments are copied out.
+	write()
+	d := NewDownloader(dir)
+	d.SetArchive(Archive{Comments: true})
+	paths, err := d.writeCommentsSidecar([]string{info, video})
+	if err != nil {
+		t.Fatalf("writeCommentsSidecar: %v", err)
+	}
+	comments := filepath.Join(dir, "Title-id.comments.json")
+	if strings.Join(paths, ",") != video+","+comments {
+		t.Fatalf("unexpected paths %v", paths)
+	}
+	if b, err := os.ReadFile(comments); err != nil || string(b) != `[{"text":"first"}]` {
+		t.Fatalf("comments file = %q (%v)", b, err)
+	}
+	if _, err := os.Stat(info); !os.IsNotExist(err) {
+		t.Fatalf("expected info json removed, got %v", err)
+	}
+
+	// With the info JSON requested too, both are kept.
+	write()
+	d.SetArchive(Archive{InfoJSON: true, Comments: true})
+	paths, err = d.writeCommentsSidecar([]string{info, video})
+	if err != nil || len(paths) != 3 {
+		t.Fatalf("unexpected paths %v (%v)", paths, err)
+	}
+	if _, err := os.Stat(info); err != nil {
+		t.Fatalf("expected info json kept: %v", err)
+	}
+
+	// Sidecars are tracked artifacts, so cancel cleanup removes them.
+	if err := d.CleanupArtifacts("id", "", paths); err != nil {
+		t.Fatalf("CleanupArtifacts: %v", err)
+	}
+	for _, p := range []string{info, comments} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Fatalf("expected %s removed, got %v", p, err)
+		}
+	}
+}
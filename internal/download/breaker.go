@@ -0,0 +1,371 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ReasonCircuitOpen is recorded on items deferred because their host's
+// circuit breaker is open.
+const ReasonCircuitOpen = "circuit_open"
+
+// Circuit breaker states as reported by BreakerStatus.
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half_open"
+)
+
+// breakerRequeueRetry spaces out attempts to requeue deferred jobs that did
+// not fit in a full queue.
+const breakerRequeueRetry = 5 * time.Second
+
+// BreakerStatus describes the circuit breaker of one host.
+type BreakerStatus struct {
+	Host                string     `json:"host"`
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastError           string     `json:"last_error,omitempty"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+	RetryAt             *time.Time `json:"retry_at,omitempty"`
+	Deferred            int        `json:"deferred"` // jobs held until the breaker closes
+}
+
+// hostBreaker is the breaker state of one host, guarded by Manager.breakerMu.
+type hostBreaker struct {
+	state    string
+	failures int
+	lastErr  string
+	openedAt time.Time
+	retryAt  time.Time
+	probe    string   // item let through while half-open; "" until one is dispatched
+	deferred []string // item IDs in the order they were deferred
+	timer    *time.Timer
+}
+
+// SetCircuitBreaker opens a host's breaker after threshold consecutive
+// failures of its downloads or metadata probes. While open, that host's jobs
+// are deferred; after cooldown a single job is let through as a probe.
+// A threshold of 0 disables the breakers.
+func (m *Manager) SetCircuitBreaker(threshold int, cooldown time.Duration) {
+	m.breakerMu.Lock()
+	m.breakerThreshold = max(threshold, 0)
+	m.breakerCooldown = max(cooldown, 0)
+	m.breakerMu.Unlock()
+}
+
+// Breakers lists hosts with recent failures or deferred jobs, sorted by host.
+func (m *Manager) Breakers() []BreakerStatus {
+	m.breakerMu.Lock()
+	defer m.breakerMu.Unlock()
+	out := make([]BreakerStatus, 0, len(m.breakers))
+	for host, b := range m.breakers {
+		st := BreakerStatus{
+			Host:                host,
+			State:               b.state,
+			ConsecutiveFailures: b.failures,
+			LastError:           b.lastErr,
+		}
+		for _, id := range b.deferred {
+			if it := m.registry.Get(id); it != nil && it.State == StateDeferred {
+				st.Deferred++
+			}
+		}
+		if b.state != BreakerClosed {
+			openedAt, retryAt := b.openedAt, b.retryAt
+			st.OpenedAt = &openedAt
+			st.RetryAt = &retryAt
+		}
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Host < out[j].Host })
+	return out
+}
+
+// ResetBreaker closes a host's breaker and requeues its deferred jobs.
+// Returns false when the host has no breaker state.
+func (m *Manager) ResetBreaker(host string) bool {
+	host = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(host)), "www.")
+	m.breakerMu.Lock()
+	b, ok := m.breakers[host]
+	if ok {
+		b.close()
+	}
+	m.breakerMu.Unlock()
+	if !ok {
+		return false
+	}
+	slog.Info("circuit breaker reset", "event", "breaker_reset", "host", host)
+	m.releaseDeferred(host)
+	return true
+}
+
+// breakerHost groups URLs by host, ignoring a leading "www.".
+func breakerHost(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Hostname() == "" {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// circuitOpen reports whether work for url's host is currently held back,
+// either by an open breaker or by a half-open one waiting on its probe.
+func (m *Manager) circuitOpen(rawURL string) bool {
+	host := breakerHost(rawURL)
+	m.breakerMu.Lock()
+	defer m.breakerMu.Unlock()
+	if m.breakerThreshold <= 0 || host == "" {
+		return false
+	}
+	b := m.breakers[host]
+	return b != nil && b.state != BreakerClosed
+}
+
+// admitJob decides whether a dequeued job may start. A job for a host with
+// an open breaker is moved to StateDeferred and false is returned; once the
+// cooldown has passed, the first job for the host is let through as the probe.
+func (m *Manager) admitJob(j job) bool {
+	host := breakerHost(j.url)
+	m.breakerMu.Lock()
+	if m.breakerThreshold <= 0 || host == "" {
+		m.breakerMu.Unlock()
+		return true
+	}
+	b := m.breakers[host]
+	switch {
+	case b == nil || b.state == BreakerClosed:
+		m.breakerMu.Unlock()
+		return true
+	case b.state == BreakerOpen && !time.Now().Before(b.retryAt):
+		b.state = BreakerHalfOpen
+		fallthrough
+	case b.state == BreakerHalfOpen && (b.probe == "" || b.probe == j.id):
+		b.probe = j.id
+		m.breakerMu.Unlock()
+		slog.Info("circuit breaker half-open; probing host",
+			"event", "breaker_probe",
+			"host", host,
+			"id", j.id)
+		return true
+	}
+
+	// Deferred under breakerMu so a concurrent close cannot miss this item.
+	deferred := false
+	_ = m.registry.Update(j.id, func(it *Item) {
+		if it.State != StateQueued || it.queueToken != j.token {
+			return
+		}
+		it.State = StateDeferred
+		it.Error = ReasonCircuitOpen
+		deferred = true
+	})
+	if deferred {
+		b.deferred = append(b.deferred, j.id)
+	}
+	m.breakerMu.Unlock()
+	if deferred {
+		m.updateState(j.id, StateDeferred, ReasonCircuitOpen)
+	}
+	return false
+}
+
+// siteFailureRE matches yt-dlp errors that point at the site rather than at
+// one URL: rate limiting, server errors, bot checks and network failures.
+var siteFailureRE = regexp.MustCompile(`(?i)http error (429|5\d\d)|too many requests|rate.?limit|throttl|not a bot|timed out|connection (reset|refused|aborted)|remote end closed|name resolution|name or service not known|network is unreachable|no route to host|urlopen error|ssl:`)
+
+// isSiteFailure reports whether err says something about its host's health.
+// Private, removed or unsupported videos and a job's own time limit do not.
+func isSiteFailure(err error) bool {
+	switch {
+	case errors.Is(err, ErrTimeout):
+		return false
+	case errors.Is(err, ErrStalled):
+		return true
+	}
+	return siteFailureRE.MatchString(err.Error())
+}
+
+// recordJobOutcome feeds a finished download or metadata probe into its
+// host's breaker. Success closes the breaker and requeues deferred jobs;
+// a site failure while half-open, or the threshold-th in a row, opens it.
+// Any other failure means the site answered and counts as a success.
+func (m *Manager) recordJobOutcome(rawURL, id string, err error) {
+	if errors.Is(err, ErrCircuitOpen) || errors.Is(err, context.Canceled) || m.closing.Load() {
+		return
+	}
+	if err != nil && !isSiteFailure(err) {
+		err = nil
+	}
+	host := breakerHost(rawURL)
+	m.breakerMu.Lock()
+	if m.breakerThreshold <= 0 || host == "" {
+		m.breakerMu.Unlock()
+		return
+	}
+	b := m.breakers[host]
+	if err == nil {
+		if b == nil {
+			m.breakerMu.Unlock()
+			return
+		}
+		wasOpen := b.state != BreakerClosed
+		b.close()
+		m.breakerMu.Unlock()
+		if wasOpen {
+			slog.Info("circuit breaker closed",
+				"event", "breaker_closed",
+				"host", host,
+				"id", id)
+		}
+		m.releaseDeferred(host)
+		return
+	}
+
+	if b == nil {
+		if m.breakers == nil {
+			m.breakers = make(map[string]*hostBreaker)
+		}
+		b = &hostBreaker{state: BreakerClosed}
+		m.breakers[host] = b
+	}
+	b.failures++
+	b.lastErr = truncateUTF8(err.Error(), 256)
+	reopen := b.state == BreakerHalfOpen
+	if !reopen && (b.state != BreakerClosed || b.failures < m.breakerThreshold) {
+		m.breakerMu.Unlock()
+		return
+	}
+	now := time.Now()
+	cooldown := m.breakerCooldown
+	b.state = BreakerOpen
+	b.openedAt = now
+	b.retryAt = now.Add(cooldown)
+	b.probe = ""
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	b.timer = time.AfterFunc(cooldown, func() { m.probeHost(host) })
+	failures := b.failures
+	m.breakerMu.Unlock()
+
+	slog.Warn("circuit breaker opened; deferring host jobs",
+		"event", "breaker_open",
+		"host", host,
+		"failures", failures,
+		"reopened", reopen,
+		"retry_in_ms", cooldown.Milliseconds(),
+		"error", err)
+}
+
+// releaseProbe forgets a probe that was stopped by the user before it could
+// report an outcome, so another job can take its place.
+func (m *Manager) releaseProbe(rawURL, id string) {
+	host := breakerHost(rawURL)
+	m.breakerMu.Lock()
+	b := m.breakers[host]
+	release := b != nil && b.state == BreakerHalfOpen && b.probe == id
+	if release {
+		b.probe = ""
+	}
+	m.breakerMu.Unlock()
+	if release {
+		m.probeHost(host)
+	}
+}
+
+// probeHost runs when an open breaker's cooldown ends: the oldest deferred
+// job is requeued so a worker can admit it as the probe.
+func (m *Manager) probeHost(host string) {
+	if m.closing.Load() {
+		return
+	}
+	m.breakerMu.Lock()
+	b := m.breakers[host]
+	if b == nil || b.state == BreakerClosed {
+		m.breakerMu.Unlock()
+		return
+	}
+	b.state = BreakerHalfOpen
+	var next *Item
+	for len(b.deferred) > 0 && b.probe == "" && next == nil {
+		id := b.deferred[0]
+		b.deferred = b.deferred[1:]
+		if it := m.registry.Get(id); it != nil && it.State == StateDeferred {
+			next = it
+		}
+	}
+	m.breakerMu.Unlock()
+	if next == nil {
+		// Nothing waiting; the next job submitted for the host becomes the probe.
+		return
+	}
+	if _, err := m.resumeItem(next); err != nil {
+		m.breakerMu.Lock()
+		b.deferred = append([]string{next.ID}, b.deferred...)
+		b.timer = time.AfterFunc(breakerRequeueRetry, func() { m.probeHost(host) })
+		m.breakerMu.Unlock()
+	}
+}
+
+// releaseDeferred requeues every job deferred for host, oldest first. Jobs
+// that do not fit in the queue are retried shortly.
+func (m *Manager) releaseDeferred(host string) {
+	if m.closing.Load() {
+		return
+	}
+	m.breakerMu.Lock()
+	b := m.breakers[host]
+	if b == nil || b.state != BreakerClosed {
+		m.breakerMu.Unlock()
+		return
+	}
+	ids := b.deferred
+	b.deferred = nil
+	m.breakerMu.Unlock()
+
+	for i, id := range ids {
+		it := m.registry.Get(id)
+		if it == nil || it.State != StateDeferred {
+			continue
+		}
+		if _, err := m.resumeItem(it); err != nil {
+			if !errors.Is(err, ErrQueueFull) {
+				slog.Warn("failed to requeue deferred download",
+					"event", "deferred_resume_error",
+					"id", id,
+					"host", host,
+					"error", err)
+			}
+			m.breakerMu.Lock()
+			b.deferred = append(append([]string(nil), ids[i:]...), b.deferred...)
+			b.timer = time.AfterFunc(breakerRequeueRetry, func() { m.releaseDeferred(host) })
+			m.breakerMu.Unlock()
+			return
+		}
+	}
+
+	m.breakerMu.Lock()
+	if b.state == BreakerClosed && len(b.deferred) == 0 && m.breakers[host] == b {
+		delete(m.breakers, host)
+	}
+	m.breakerMu.Unlock()
+}
+
+// close resets the breaker; deferred jobs are left for releaseDeferred.
+func (b *hostBreaker) close() {
+	b.state = BreakerClosed
+	b.failures = 0
+	b.lastErr = ""
+	b.probe = ""
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+}
@@ -0,0 +1,226 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newBreakerTestManager(t *testing.T, fail *atomic.Bool) *Manager {
+	t.Helper()
+	m := NewManager(t.TempDir(), 1, 16)
+	t.Cleanup(m.Shutdown)
+	m.SetCircuitBreaker(2, 50*time.Millisecond)
+	m.workerDownload = func(ctx context.Context, id, url string) error {
+		if fail.Load() && strings.Contains(url, "flaky.example") {
+			return errors.New("HTTP Error 429: Too Many Requests")
+		}
+		return nil
+	}
+	return m
+}
+
+func breakerFor(m *Manager, host string) (BreakerStatus, bool) {
+	for _, b := range m.Breakers() {
+		if b.Host == host {
+			return b, true
+		}
+	}
+	return BreakerStatus{}, false
+}
+
+func TestCircuitBreaker_DefersThenProbesAndCloses(t *testing.T) {
+	var fail atomic.Bool
+	fail.Store(true)
+	m := newBreakerTestManager(t, &fail)
+
+	for _, u := range []string{"https://flaky.example/1", "https://www.flaky.example/2"} {
+		id, err := m.Enqueue(u)
+		if err != nil {
+			t.Fatalf("enqueue: %v", err)
+		}
+		waitForItem(t, m, id, func(it *Item) bool { return it.State == StateFailed })
+	}
+	b, ok := breakerFor(m, "flaky.example")
+	if !ok || b.State != BreakerOpen || b.ConsecutiveFailures != 2 || b.RetryAt == nil {
+		t.Fatalf("expected open breaker after two failures, got %+v", m.Breakers())
+	}
+
+	// While open, the host's jobs are deferred and other hosts are unaffected.
+	first, _ := m.Enqueue("https://flaky.example/3")
+	second, _ := m.Enqueue("https://flaky.example/4")
+	other, _ := m.Enqueue("https://steady.example/1")
+	waitForItem(t, m, first, func(it *Item) bool { return it.State == StateDeferred && it.Error == ReasonCircuitOpen })
+	waitForItem(t, m, second, func(it *Item) bool { return it.State == StateDeferred })
+	waitForItem(t, m, other, func(it *Item) bool { return it.State == StateCompleted })
+	if b, _ := breakerFor(m, "flaky.example"); b.Deferred != 2 {
+		t.Fatalf("expected two deferred jobs, got %+v", b)
+	}
+
+	// After the cooldown one probe runs; its success requeues the rest.
+	fail.Store(false)
+	waitForItem(t, m, first, func(it *Item) bool { return it.State == StateCompleted })
+	waitForItem(t, m, second, func(it *Item) bool { return it.State == StateCompleted })
+	if _, ok := breakerFor(m, "flaky.example"); ok {
+		t.Fatalf("expected breaker cleared after recovery, got %+v", m.Breakers())
+	}
+}
+
+func TestCircuitBreaker_FailedProbeReopens(t *testing.T) {
+	var fail atomic.Bool
+	fail.Store(true)
+	m := newBreakerTestManager(t, &fail)
+
+	for i := 0; i < 2; i++ {
+		id, _ := m.Enqueue("https://flaky.example/" + string(rune('a'+i)))
+		waitForItem(t, m, id, func(it *Item) bool { return it.State == StateFailed })
+	}
+	probe, _ := m.Enqueue("https://flaky.example/probe")
+	waiting, _ := m.Enqueue("https://flaky.example/waiting")
+	waitForItem(t, m, waiting, func(it *Item) bool { return it.State == StateDeferred })
+
+	waitForItem(t, m, probe, func(it *Item) bool { return it.State == StateFailed })
+	b, _ := breakerFor(m, "flaky.example")
+	if b.State != BreakerOpen || b.ConsecutiveFailures != 3 {
+		t.Fatalf("expected breaker reopened by failed probe, got %+v", b)
+	}
+	if it := m.registry.Get(waiting); it.State != StateDeferred {
+		t.Fatalf("expected remaining job still deferred, got %s", it.State)
+	}
+
+	// A manual reset requeues deferred jobs at once.
+	fail.Store(false)
+	if !m.ResetBreaker("WWW.flaky.example") {
+		t.Fatalf("expected reset to find the breaker")
+	}
+	waitForItem(t, m, waiting, func(it *Item) bool { return it.State == StateCompleted })
+}
+
+func TestCircuitBreaker_SkipsMetadataProbeWhileOpen(t *testing.T) {
+	var fail atomic.Bool
+	fail.Store(true)
+	m := newBreakerTestManager(t, &fail)
+	m.SetCircuitBreaker(1, time.Hour)
+
+	origFetch := fetchMediaInfo
+	t.Cleanup(func() { fetchMediaInfo = origFetch })
+	var calls atomic.Int32
+	fetchMediaInfo = func(ctx context.Context, inputURL string) (MediaInfo, error) {
+		calls.Add(1)
+		return MediaInfo{Title: "t"}, nil
+	}
+
+	id, _ := m.Enqueue("https://flaky.example/1")
+	waitForItem(t, m, id, func(it *Item) bool { return it.State == StateFailed })
+	if _, err := m.fetchMediaInfoWithRetry(context.Background(), "https://flaky.example/2", 1); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if calls.Load() != 0 {
+		t.Fatalf("expected no yt-dlp probe while the breaker is open")
+	}
+	if _, err := m.fetchMediaInfoWithRetry(context.Background(), "https://steady.example/1", 1); err != nil {
+		t.Fatalf("other hosts must still be probed: %v", err)
+	}
+}
+
+func TestCircuitBreaker_SuccessfulMetadataProbeResetsFailures(t *testing.T) {
+	var fail atomic.Bool
+	fail.Store(true)
+	m := newBreakerTestManager(t, &fail)
+
+	origFetch := fetchMediaInfo
+	t.Cleanup(func() { fetchMediaInfo = origFetch })
+	fetchMediaInfo = func(ctx context.Context, inputURL string) (MediaInfo, error) {
+		return MediaInfo{Title: "t"}, nil
+	}
+
+	id, _ := m.Enqueue("https://flaky.example/1")
+	waitForItem(t, m, id, func(it *Item) bool { return it.State == StateFailed })
+	if _, err := m.fetchMediaInfoWithRetry(context.Background(), "https://flaky.example/2", 1); err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	if b, ok := breakerFor(m, "flaky.example"); ok && b.ConsecutiveFailures != 0 {
+		t.Fatalf("expected a successful probe to reset failures, got %+v", b)
+	}
+
+	// Failures separated by a success are not consecutive.
+	id, _ = m.Enqueue("https://flaky.example/3")
+	waitForItem(t, m, id, func(it *Item) bool { return it.State == StateFailed })
+	if b, _ := breakerFor(m, "flaky.example"); b.State != BreakerClosed {
+		t.Fatalf("expected breaker to stay closed, got %+v", b)
+	}
+}
+
+func TestCircuitBreaker_IgnoresPerVideoFailures(t *testing.T) {
+	var fail atomic.Bool
+	m := newBreakerTestManager(t, &fail)
+	m.workerDownload = func(ctx context.Context, id, url string) error {
+		return errors.New("ERROR: [youtube] abc: Private video. Sign in if you've been granted access to this video")
+	}
+
+	for i := 0; i < 3; i++ {
+		id, err := m.Enqueue("https://flaky.example/" + string(rune('a'+i)))
+		if err != nil {
+			t.Fatalf("enqueue: %v", err)
+		}
+		waitForItem(t, m, id, func(it *Item) bool { return it.State == StateFailed })
+	}
+	if b, ok := breakerFor(m, "flaky.example"); ok && (b.State != BreakerClosed || b.ConsecutiveFailures != 0) {
+		t.Fatalf("expected private-video failures to leave the breaker alone, got %+v", b)
+	}
+}
+
+func TestIsSiteFailure(t *testing.T) {
+	cases := map[string]bool{
+		"HTTP Error 429: Too Many Requests":                                  true,
+		"ERROR: unable to download webpage: HTTP Error 503":                  true,
+		"<urlopen error [Errno -3] Temporary failure in name resolution>":    true,
+		"ERROR: [youtube] x: Sign in to confirm you're not a bot":            true,
+		"ERROR: [youtube] x: Private video":                                  false,
+		"ERROR: [youtube] x: Video unavailable. This video has been removed": false,
+		"invalid URL": false,
+	}
+	for msg, want := range cases {
+		if got := isSiteFailure(errors.New(msg)); got != want {
+			t.Errorf("isSiteFailure(%q) = %v, want %v", msg, got, want)
+		}
+	}
+	if isSiteFailure(fmt.Errorf("%w: job exceeded 1h0m0s", ErrTimeout)) {
+		t.Errorf("a job's own time limit must not count against its host")
+	}
+}
+
+func TestCircuitBreaker_ProbesPendingRowSkippedWhileOpen(t *testing.T) {
+	var fail atomic.Bool
+	fail.Store(true)
+	m := newBreakerTestManager(t, &fail)
+	m.SetCircuitBreaker(1, 50*time.Millisecond)
+
+	origFetch := fetchMediaInfo
+	t.Cleanup(func() { fetchMediaInfo = origFetch })
+	var calls atomic.Int32
+	fetchMediaInfo = func(ctx context.Context, inputURL string) (MediaInfo, error) {
+		calls.Add(1)
+		return MediaInfo{Title: "Recovered", FilesizeBytes: 1234}, nil
+	}
+
+	first, _ := m.Enqueue("https://flaky.example/1")
+	waitForItem(t, m, first, func(it *Item) bool { return it.State == StateFailed })
+	if err := m.ProcessPendingDownloadWithOptions(context.Background(), 7, "https://flaky.example/2", EnqueueOptions{}, &mockStore{}); err != nil {
+		t.Fatalf("process pending: %v", err)
+	}
+	it := m.registry.GetWithDBID(7)
+	if it == nil || !it.needsProbe || calls.Load() != 0 {
+		t.Fatalf("expected the probe skipped while open, got %+v (%d probes)", it, calls.Load())
+	}
+
+	// Once admitted after the cooldown, the job is probed before downloading.
+	fail.Store(false)
+	waitForItem(t, m, it.ID, func(it *Item) bool {
+		return it.State == StateCompleted && it.Title == "Recovered" && it.EstimatedBytes == 1234
+	})
+}
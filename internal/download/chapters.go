@@ -0,0 +1,56 @@
+package download
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// chapterDirSuffix names the directory split chapters are written to, next
+// to the full file: "Title-id.mp4" gets "Title-id.chapters/001 - Intro.mp4".
+const chapterDirSuffix = ".chapters"
+
+// chapterNameRE matches a piece named by chapterTemplate.
+var chapterNameRE = regexp.MustCompile(`^(\d{3,}) - (.+)\.[^.]+$`)
+
+// ChapterFile is one piece of a download split by chapters.
+type ChapterFile struct {
+	Number   int    `json:"number"` // 1-based, in playback order
+	Title    string `json:"title"`  // as it appears in the file name
+	Filename string `json:"filename"`
+	Path     string `json:"-"` // absolute path of the piece
+}
+
+// chapterTemplate is the yt-dlp "chapter:" output template for a job whose
+// full file is named by tpl.
+func chapterTemplate(tpl string) string {
+	base := strings.TrimSuffix(tpl, ".%(ext)s")
+	return base + chapterDirSuffix + "/%(section_number)03d - %(section_title)s.%(ext)s"
+}
+
+// ChapterFiles picks the pieces of a split download out of its tracked
+// artifact paths, ordered by chapter number.
+func ChapterFiles(artifacts []string) []ChapterFile {
+	seen := make(map[int]bool)
+	var out []ChapterFile
+	for _, p := range artifacts {
+		if !strings.HasSuffix(filepath.Base(filepath.Dir(p)), chapterDirSuffix) {
+			continue
+		}
+		name := filepath.Base(p)
+		m := chapterNameRE.FindStringSubmatch(name)
+		if m == nil {
+			continue
+		}
+		n, err := strconv.Atoi(m[1])
+		if err != nil || n < 1 || seen[n] {
+			continue
+		}
+		seen[n] = true
+		out = append(out, ChapterFile{Number: n, Title: m[2], Filename: name, Path: p})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Number < out[j].Number })
+	return out
+}
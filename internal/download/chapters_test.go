@@ -0,0 +1,42 @@
+package download
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChapterFiles_PicksNumberedPiecesInOrder(t *testing.T) {
+	got := ChapterFiles([]string{
+		"/v/Mix-abc.webm",
+		"/v/Mix-abc.chapters/010 - Finale.webm",
+		"/v/Mix-abc.chapters/002 - Side B - Part 1.webm",
+		"/v/Mix-abc.chapters/notes.txt",
+		"/v/other/001 - Not a chapter.webm",
+		"/v/Mix-abc.chapters/001 - Intro.webm",
+	})
+	want := []ChapterFile{
+		{Number: 1, Title: "Intro", Filename: "001 - Intro.webm", Path: "/v/Mix-abc.chapters/001 - Intro.webm"},
+		{Number: 2, Title: "Side B - Part 1", Filename: "002 - Side B - Part 1.webm", Path: "/v/Mix-abc.chapters/002 - Side B - Part 1.webm"},
+		{Number: 10, Title: "Finale", Filename: "010 - Finale.webm", Path: "/v/Mix-abc.chapters/010 - Finale.webm"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("chapter %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRunOptions_SplitChaptersArgs(t *testing.T) {
+	tpl := defaultOutputTemplate
+	args := strings.Join(RunOptions{SplitChapters: true}.apply(buildYTDLPArgs("https://example.com/v", tpl, "/out", "/tmp", true), tpl), " ")
+	want := "--split-chapters --output chapter:%(title).200s-%(id)s.chapters/%(section_number)03d - %(section_title)s.%(ext)s"
+	if !strings.HasSuffix(args, want) {
+		t.Fatalf("unexpected args %q", args)
+	}
+	if strings.Contains(strings.Join(RunOptions{}.apply(buildYTDLPArgs("https://example.com/v", tpl, "/out", "/tmp", true), tpl), " "), "--split-chapters") {
+		t.Fatalf("expected no split without the option")
+	}
+}
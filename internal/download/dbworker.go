@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sync"
+	"sync/atomic"
 	"time"
 	"videofetch/internal/logging"
 )
@@ -23,8 +25,24 @@ type DBStore interface {
 	TryClaimPending(ctx context.Context, id int64) (bool, error)
 	UpdateStatus(ctx context.Context, id int64, status string, errMsg string) error
 	UpdateMeta(ctx context.Context, id int64, title string, duration int64, thumbnail string) error
+	UpdateDetails(ctx context.Context, id int64, uploader, description string) error
+	UpdateLibraryRoot(ctx context.Context, id int64, root string) error
 }
 
+// PendingNotifier is implemented by stores that announce rows becoming
+// pending. The channel may coalesce notifications; receivers rescan.
+type PendingNotifier interface {
+	SubscribePending() (<-chan struct{}, func())
+}
+
+const (
+	// pendingPollInterval is how often pending rows are polled when the store
+	// cannot announce them, or while rows are left waiting for room.
+	pendingPollInterval = 2 * time.Second
+	// pendingSweepInterval is the fallback sweep for missed notifications.
+	pendingSweepInterval = 30 * time.Second
+)
+
 // DBWorker processes pending downloads from the database
 type DBWorker struct {
 	store   DBStore
@@ -32,65 +50,145 @@ type DBWorker struct {
 	ctx     context.Context
 	cancel  context.CancelFunc
 	done    chan struct{}
+	wg      sync.WaitGroup
+
+	// Metadata pool: pending rows wait in metaQueue, oldest first, until one
+	// of metaWorkers probes them. metaInFlight holds queued and active IDs.
+	poolMu       sync.Mutex
+	metaWorkers  int
+	metaQueueCap int
+	metaQueue    []pendingRow
+	metaInFlight map[int64]struct{}
+	metaActive   int
+	metaWake     chan struct{}
+
+	// backlog is set when the last sweep may have left pending rows behind
+	// for lack of room; freeing a probe slot then sweeps again right away.
+	backlog atomic.Bool
+	kick    chan struct{}
+
+	// process probes and enqueues one row; tests replace it.
+	process func(row pendingRow)
+	// onSweep, when set, runs after every sweep; tests use it to wait.
+	onSweep func()
+	// sweepInterval overrides pendingSweepInterval; tests use it to rely on
+	// notifications alone.
+	sweepInterval time.Duration
 }
 
 // NewDBWorker creates a new database worker that processes pending URLs
 func NewDBWorker(store DBStore, manager *Manager) *DBWorker {
 	ctx, cancel := context.WithCancel(context.Background())
-	return &DBWorker{
-		store:   store,
-		manager: manager,
-		ctx:     ctx,
-		cancel:  cancel,
-		done:    make(chan struct{}),
+	dw := &DBWorker{
+		store:        store,
+		manager:      manager,
+		ctx:          ctx,
+		cancel:       cancel,
+		done:         make(chan struct{}),
+		metaWorkers:  DefaultMetadataWorkers,
+		metaQueueCap: DefaultMetadataQueue,
+		metaInFlight: make(map[int64]struct{}),
+		metaWake:     make(chan struct{}, 1),
+		kick:         make(chan struct{}, 1),
 	}
+	dw.process = dw.processDownload
+	return dw
 }
 
 // Start begins processing pending URLs from the database in the background
 func (dw *DBWorker) Start() {
+	dw.startMetadataWorkers()
 	go dw.run()
 }
 
-// Stop stops the database worker
+// Stop stops the database worker and waits for running metadata probes.
 func (dw *DBWorker) Stop() {
 	dw.cancel()
 	<-dw.done
+	dw.wg.Wait()
 }
 
+// run sweeps pending rows when the store announces them, when a probe slot
+// frees up behind a backlog, and on a timer as a fallback. Stores that cannot
+// announce rows are polled every pendingPollInterval.
 func (dw *DBWorker) run() {
 	defer close(dw.done)
 
-	ticker := time.NewTicker(2 * time.Second) // Poll database every 2 seconds
-	defer ticker.Stop()
+	var notify <-chan struct{}
+	if n, ok := dw.store.(PendingNotifier); ok {
+		ch, unsubscribe := n.SubscribePending()
+		defer unsubscribe()
+		notify = ch
+	}
+	idle := pendingPollInterval
+	if notify != nil {
+		idle = pendingSweepInterval
+		if dw.sweepInterval > 0 {
+			idle = dw.sweepInterval
+		}
+	}
 
+	timer := time.NewTimer(0) // pick up rows left pending before startup
+	defer timer.Stop()
 	for {
 		select {
 		case <-dw.ctx.Done():
 			return
-		case <-ticker.C:
-			if err := dw.processPendingURLs(); err != nil {
-				slog.Error("dbworker: error processing pending URLs",
-					"event", "dbworker_error",
-					"error", err)
-			}
+		case <-notify:
+		case <-dw.kick:
+		case <-timer.C:
+		}
+		if err := dw.processPendingURLs(); err != nil {
+			slog.Error("dbworker: error processing pending URLs",
+				"event", "dbworker_error",
+				"error", err)
 		}
+		if dw.onSweep != nil {
+			dw.onSweep()
+		}
+		next := idle
+		if dw.backlog.Load() {
+			next = min(idle, pendingPollInterval)
+		}
+		timer.Reset(next)
+	}
+}
+
+// kickSweep asks run for a sweep without waiting for a notification.
+func (dw *DBWorker) kickSweep() {
+	select {
+	case dw.kick <- struct{}{}:
+	default:
 	}
 }
 
 func (dw *DBWorker) processPendingURLs() error {
-	// Get a batch of pending downloads
-	pending, err := dw.store.GetPendingDownloadsForWorker(dw.ctx, 10)
+	// During maintenance new rows stay pending rather than filling the queue;
+	// nothing announces the end of maintenance, so keep polling.
+	if dw.manager != nil && dw.manager.Maintenance().Active {
+		dw.backlog.Store(true)
+		return nil
+	}
+
+	// Backpressure: rows the pool has no room for stay pending in the store.
+	room := dw.metadataRoom()
+	if room == 0 {
+		dw.backlog.Store(true)
+		return nil
+	}
+
+	// Rows already in the pool are still pending until claimed; fetch enough
+	// to fill the free slots after skipping them.
+	dw.poolMu.Lock()
+	inPool := len(dw.metaInFlight)
+	dw.poolMu.Unlock()
+	pending, err := dw.store.GetPendingDownloadsForWorker(dw.ctx, room+inPool)
 	if err != nil {
 		return fmt.Errorf("failed to get pending downloads: %w", err)
 	}
 
+	rows := make([]pendingRow, 0, len(pending))
 	for _, download := range pending {
-		select {
-		case <-dw.ctx.Done():
-			return nil
-		default:
-		}
-
 		downloadMap, ok := download.(map[string]interface{})
 		if !ok {
 			slog.Error("dbworker: invalid download type",
@@ -98,20 +196,49 @@ func (dw *DBWorker) processPendingURLs() error {
 				"type", fmt.Sprintf("%T", download))
 			continue
 		}
-
-		// Process metadata fetch and enqueue asynchronously
-		go dw.processDownload(downloadMap)
+		row, ok := pendingRowFromMap(downloadMap)
+		if !ok {
+			slog.Error("dbworker: pending download without id or url",
+				"event", "dbworker_type_error",
+				"type", fmt.Sprintf("%T", download))
+			continue
+		}
+		rows = append(rows, row)
 	}
-
+	dw.backlog.Store(dw.admitPending(rows, room) == room)
 	return nil
 }
 
-func (dw *DBWorker) processDownload(download map[string]interface{}) {
-	downloadID := download["id"].(int64)
-	downloadURL := download["url"].(string)
+func (dw *DBWorker) processDownload(row pendingRow) {
+	downloadID, downloadURL := row.id, row.url
+
+	// Sections were validated when the row was created; a row that no longer
+	// parses fails rather than silently downloading the whole video.
+	sections, err := ParseSections(row.sections)
+	if err != nil {
+		slog.Error("dbworker: pending download has invalid sections",
+			"event", "dbworker_sections_error",
+			"db_id", downloadID,
+			"error", err)
+		if updateErr := dw.store.UpdateStatus(dw.ctx, downloadID, "failed", err.Error()); updateErr != nil {
+			slog.Error("dbworker: failed to update status after invalid sections",
+				"event", "dbworker_status_update_error",
+				"db_id", downloadID,
+				"error", updateErr)
+		}
+		return
+	}
 
 	// Use the new helper function from Manager
-	if err := dw.manager.ProcessPendingDownload(dw.ctx, downloadID, downloadURL, dw.store); err != nil {
+	opts := EnqueueOptions{
+		Library:       row.library,
+		MaxDuration:   row.maxDuration,
+		FormatID:      row.formatID,
+		ProbeToken:    row.probeToken,
+		Sections:      sections,
+		SplitChapters: row.splitChapters,
+	}
+	if err := dw.manager.ProcessPendingDownloadWithOptions(dw.ctx, downloadID, downloadURL, opts, dw.store); err != nil {
 		slog.Error("dbworker: ProcessPendingDownload failed",
 			"event", "dbworker_process_error",
 			"db_id", downloadID,
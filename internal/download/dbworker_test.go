@@ -3,6 +3,7 @@ package download
 import (
 	"context"
 	"testing"
+	"time"
 )
 
 // Mock store for testing
@@ -76,6 +77,14 @@ func (m *mockStore) UpdateMeta(ctx context.Context, id int64, title string, dura
 	return nil
 }
 
+func (m *mockStore) UpdateDetails(ctx context.Context, id int64, uploader, description string) error {
+	return nil
+}
+
+func (m *mockStore) UpdateLibraryRoot(ctx context.Context, id int64, root string) error {
+	return nil
+}
+
 func TestRetryIncompleteDownloads_NoIncompleteDownloads(t *testing.T) {
 	store := &mockStore{}
 	mgr := NewManager("/tmp", 1, 10)
@@ -161,3 +170,56 @@ func TestRetryIncompleteDownloads_ContextCancellation(t *testing.T) {
 		t.Errorf("expected no status updates due to context cancellation, got %d", len(store.updateStatusCalls))
 	}
 }
+
+// notifyingStore announces pending rows like the SQLite store.
+type notifyingStore struct {
+	pendingStore
+	notify chan struct{}
+}
+
+func (s *notifyingStore) SubscribePending() (<-chan struct{}, func()) {
+	return s.notify, func() {}
+}
+
+func TestDBWorker_SweepsWhenStoreAnnouncesPendingRows(t *testing.T) {
+	st := &notifyingStore{notify: make(chan struct{}, 1)}
+	mgr := NewManager(t.TempDir(), 1, 16)
+	defer mgr.Shutdown()
+	dw := NewDBWorker(st, mgr)
+	dw.SetMetadataPool(1, 1)
+	dw.sweepInterval = time.Hour // after the startup sweep, only notifications and kicks sweep
+	swept := make(chan struct{}, 64)
+	dw.onSweep = func() { swept <- struct{}{} }
+	probed := make(chan int64, 8)
+	dw.process = func(row pendingRow) {
+		st.remove(row.id)
+		probed <- row.id
+	}
+	dw.Start()
+	defer dw.Stop()
+
+	select {
+	case <-swept:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("no startup sweep")
+	}
+
+	base := time.Now()
+	for id := int64(1); id <= 3; id++ {
+		st.add(id, base.Add(time.Duration(id)*time.Millisecond))
+	}
+	st.notify <- struct{}{}
+
+	// One notification is enough: with room for a single row, each finished
+	// probe sweeps again for the rows left behind.
+	for want := int64(1); want <= 3; want++ {
+		select {
+		case id := <-probed:
+			if id != want {
+				t.Fatalf("probed row %d, want %d", id, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("row %d was not probed", want)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package download
+
+import "syscall"
+
+func statDisk(path string) (diskUsage, error) {
+	var fs syscall.Statfs_t
+	if err := syscall.Statfs(path, &fs); err != nil {
+		return diskUsage{}, err
+	}
+	bsize := uint64(fs.Bsize)
+	return diskUsage{
+		Free:  uint64(fs.Bavail) * bsize,
+		Total: uint64(fs.Blocks) * bsize,
+	}, nil
+}
@@ -0,0 +1,173 @@
+package download
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ReasonDiskLow is recorded on items paused or held back because the output
+// volume dropped below the configured free-space reserve.
+const ReasonDiskLow = "disk_low"
+
+const defaultDiskCheckInterval = 10 * time.Second
+
+// diskUsage is the raw free/total byte count reported by the filesystem.
+type diskUsage struct {
+	Free  uint64
+	Total uint64
+}
+
+// DiskStatus is a point-in-time view of free space on the output volume.
+type DiskStatus struct {
+	Path         string    `json:"path"`
+	FreeBytes    uint64    `json:"free_bytes"`
+	TotalBytes   uint64    `json:"total_bytes"`
+	ReserveBytes uint64    `json:"reserve_bytes"`
+	Low          bool      `json:"low"`
+	CheckedAt    time.Time `json:"checked_at"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// DiskMonitor tracks free space for a directory against a fixed reserve.
+// A reserve of zero disables the low-space condition but still reports usage.
+type DiskMonitor struct {
+	path    string
+	reserve uint64
+	statFn  func(path string) (diskUsage, error)
+
+	mu   sync.RWMutex
+	last DiskStatus
+}
+
+// NewDiskMonitor creates a monitor for path that reports low space when fewer
+// than reserveBytes remain free.
+func NewDiskMonitor(path string, reserveBytes int64) *DiskMonitor {
+	var reserve uint64
+	if reserveBytes > 0 {
+		reserve = uint64(reserveBytes)
+	}
+	return &DiskMonitor{
+		path:    path,
+		reserve: reserve,
+		statFn:  statDisk,
+	}
+}
+
+// Check samples free space, records the result and returns it.
+// A failed sample is never reported as low so a flaky statfs cannot stall the queue.
+func (d *DiskMonitor) Check() DiskStatus {
+	st := DiskStatus{
+		Path:         d.path,
+		ReserveBytes: d.reserve,
+		CheckedAt:    time.Now().UTC(),
+	}
+	usage, err := d.statFn(d.path)
+	if err != nil {
+		st.Error = err.Error()
+	} else {
+		st.FreeBytes = usage.Free
+		st.TotalBytes = usage.Total
+		st.Low = d.reserve > 0 && usage.Free < d.reserve
+	}
+
+	d.mu.Lock()
+	d.last = st
+	d.mu.Unlock()
+	return st
+}
+
+// Status returns the most recent sample without touching the filesystem.
+func (d *DiskMonitor) Status() DiskStatus {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.last
+}
+
+// HasRoomFor reports whether writing size more bytes keeps free space above the reserve.
+// Unknown sizes and failed samples are treated as fitting.
+func (d *DiskMonitor) HasRoomFor(size int64) bool {
+	st := d.Status()
+	if st.Error != "" || st.CheckedAt.IsZero() {
+		return true
+	}
+	if st.Low {
+		return false
+	}
+	if size <= 0 || d.reserve == 0 {
+		return true
+	}
+	return st.FreeBytes >= d.reserve+uint64(size)
+}
+
+// SetDiskMonitor enables the free-space guard. While the monitor reports low
+// space, workers stop starting jobs and running jobs are paused with
+// ReasonDiskLow; they are requeued automatically once space frees up.
+func (m *Manager) SetDiskMonitor(mon *DiskMonitor) {
+	m.disk = mon
+	if mon == nil {
+		return
+	}
+	m.applyDiskStatus(mon.Check())
+	go m.watchDisk(mon)
+}
+
+func (m *Manager) watchDisk(mon *DiskMonitor) {
+	ctx := m.runCtx
+	if ctx == nil {
+		return
+	}
+	interval := m.diskInterval
+	if interval <= 0 {
+		interval = defaultDiskCheckInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.applyDiskStatus(mon.Check())
+		}
+	}
+}
+
+func (m *Manager) applyDiskStatus(st DiskStatus) {
+	if st.Low {
+		if m.setHold(ReasonDiskLow, true) {
+			slog.Warn("free disk space below reserve; holding downloads",
+				"event", "disk_low",
+				"path", st.Path,
+				"free_bytes", st.FreeBytes,
+				"reserve_bytes", st.ReserveBytes)
+		}
+		if n := m.pauseActiveForReason(ReasonDiskLow); n > 0 {
+			slog.Warn("paused in-flight downloads for low disk space",
+				"event", "disk_low_pause",
+				"count", n)
+		}
+		return
+	}
+	if m.setHold(ReasonDiskLow, false) {
+		slog.Info("free disk space recovered; resuming downloads",
+			"event", "disk_recovered",
+			"path", st.Path,
+			"free_bytes", st.FreeBytes)
+	}
+	m.resumeParked(ReasonDiskLow, func(it *Item) bool {
+		return m.disk == nil || m.disk.HasRoomFor(it.EstimatedBytes)
+	})
+}
+
+// fitsOnDisk reports whether the item's size estimate leaves the reserve intact.
+func (m *Manager) fitsOnDisk(id string) bool {
+	if m.disk == nil {
+		return true
+	}
+	it := m.registry.Get(id)
+	if it == nil || it.EstimatedBytes <= 0 {
+		return true
+	}
+	return m.disk.HasRoomFor(it.EstimatedBytes)
+}
@@ -0,0 +1,178 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newFakeDiskMonitor(reserve int64, free *atomic.Uint64) *DiskMonitor {
+	mon := NewDiskMonitor("/fake", reserve)
+	mon.statFn = func(path string) (diskUsage, error) {
+		return diskUsage{Free: free.Load(), Total: 1 << 40}, nil
+	}
+	return mon
+}
+
+func waitForItem(t *testing.T, m *Manager, id string, cond func(it *Item) bool) *Item {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if it := m.registry.Get(id); it != nil && cond(it) {
+			return it
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	it := m.registry.Get(id)
+	t.Fatalf("condition not met for item %s: %+v", id, it)
+	return nil
+}
+
+func TestDiskMonitor_LowBelowReserve(t *testing.T) {
+	var free atomic.Uint64
+	free.Store(500)
+	mon := newFakeDiskMonitor(1000, &free)
+
+	if st := mon.Check(); !st.Low || st.FreeBytes != 500 || st.ReserveBytes != 1000 {
+		t.Fatalf("expected low status with free=500 reserve=1000, got %+v", st)
+	}
+	if mon.HasRoomFor(1) {
+		t.Fatalf("expected no room while below reserve")
+	}
+
+	free.Store(5000)
+	if st := mon.Check(); st.Low {
+		t.Fatalf("expected healthy status, got %+v", st)
+	}
+	if !mon.HasRoomFor(4000) {
+		t.Fatalf("expected 4000 bytes to fit above reserve")
+	}
+	if mon.HasRoomFor(4001) {
+		t.Fatalf("expected 4001 bytes to eat into reserve")
+	}
+}
+
+func TestDiskMonitor_StatErrorIsNotLow(t *testing.T) {
+	mon := NewDiskMonitor("/fake", 1000)
+	mon.statFn = func(path string) (diskUsage, error) {
+		return diskUsage{}, errors.New("statfs failed")
+	}
+	st := mon.Check()
+	if st.Low || st.Error == "" {
+		t.Fatalf("expected non-low status with error, got %+v", st)
+	}
+	if !mon.HasRoomFor(1 << 30) {
+		t.Fatalf("expected failed samples to not block jobs")
+	}
+}
+
+func TestManager_DiskLowPausesActiveAndResumes(t *testing.T) {
+	m := NewManager(t.TempDir(), 1, 4)
+	defer m.Shutdown()
+
+	var calls atomic.Int32
+	m.workerDownload = func(ctx context.Context, id, url string) error {
+		calls.Add(1)
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	var free atomic.Uint64
+	free.Store(10_000)
+	m.disk = newFakeDiskMonitor(1000, &free)
+	m.applyDiskStatus(m.disk.Check())
+
+	id, err := m.Enqueue("https://example.com/video")
+	if err != nil {
+		t.Fatalf("enqueue failed: %v", err)
+	}
+	waitForItem(t, m, id, func(it *Item) bool { return it.State == StateDownloading })
+
+	free.Store(10)
+	m.applyDiskStatus(m.disk.Check())
+	waitForItem(t, m, id, func(it *Item) bool {
+		return it.State == StatePaused && it.Error == ReasonDiskLow
+	})
+	if holds := m.Holds(); len(holds) != 1 || holds[0] != ReasonDiskLow {
+		t.Fatalf("expected disk_low hold, got %v", holds)
+	}
+
+	free.Store(10_000)
+	m.applyDiskStatus(m.disk.Check())
+	waitForItem(t, m, id, func(it *Item) bool { return it.State == StateDownloading })
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("expected download to restart once, got %d calls", got)
+	}
+	if holds := m.Holds(); len(holds) != 0 {
+		t.Fatalf("expected no holds after recovery, got %v", holds)
+	}
+}
+
+func TestManager_UserCanceledItemIsNotAutoResumed(t *testing.T) {
+	m := NewManager(t.TempDir(), 1, 4)
+	defer m.Shutdown()
+
+	m.workerDownload = func(ctx context.Context, id, url string) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	var free atomic.Uint64
+	free.Store(10_000)
+	m.disk = newFakeDiskMonitor(1000, &free)
+	m.applyDiskStatus(m.disk.Check())
+
+	id, err := m.Enqueue("https://example.com/video")
+	if err != nil {
+		t.Fatalf("enqueue failed: %v", err)
+	}
+	m.AttachDB(id, 7)
+	waitForItem(t, m, id, func(it *Item) bool { return it.State == StateDownloading })
+
+	free.Store(10)
+	m.applyDiskStatus(m.disk.Check())
+	waitForItem(t, m, id, func(it *Item) bool { return it.State == StatePaused })
+
+	if !m.CancelByDBID(7) {
+		t.Fatalf("expected cancel of disk-paused item to succeed")
+	}
+	free.Store(10_000)
+	m.applyDiskStatus(m.disk.Check())
+	time.Sleep(20 * time.Millisecond)
+	if it := m.registry.Get(id); it.State != StateCanceled {
+		t.Fatalf("expected canceled item to stay canceled, got %s", it.State)
+	}
+}
+
+func TestWorker_ParksJobThatDoesNotFitEstimate(t *testing.T) {
+	m := NewManager(t.TempDir(), 1, 4)
+	defer m.Shutdown()
+
+	var calls atomic.Int32
+	m.workerDownload = func(ctx context.Context, id, url string) error {
+		calls.Add(1)
+		return nil
+	}
+
+	var free atomic.Uint64
+	free.Store(3000)
+	m.disk = newFakeDiskMonitor(1000, &free)
+	m.applyDiskStatus(m.disk.Check())
+
+	id, err := m.EnqueueWithOptions("https://example.com/big", EnqueueOptions{EstimatedBytes: 5000})
+	if err != nil {
+		t.Fatalf("enqueue failed: %v", err)
+	}
+	waitForItem(t, m, id, func(it *Item) bool {
+		return it.State == StatePaused && it.Error == ReasonDiskLow
+	})
+	if got := calls.Load(); got != 0 {
+		t.Fatalf("expected oversized job to not start, got %d calls", got)
+	}
+
+	free.Store(7000)
+	m.applyDiskStatus(m.disk.Check())
+	waitForItem(t, m, id, func(it *Item) bool { return it.State == StateCompleted })
+}
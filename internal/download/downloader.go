@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -19,12 +20,15 @@ import (
 // Downloader executes yt-dlp downloads with progress tracking.
 // It encapsulates all yt-dlp subprocess management and output parsing.
 type Downloader struct {
-	outDir string
+	outDir   string
+	template string  // yt-dlp output template; empty uses defaultOutputTemplate
+	archive  Archive // sidecars written next to each download
 
 	// Callbacks for progress and filename updates
 	onProgress  func(id string, progress float64)
 	onFilename  func(id string, filename string)
 	onArtifacts func(id string, paths []string)
+	onActivity  func(id string, downloadedBytes float64)
 }
 
 // NewDownloader creates a new Downloader with the specified output directory and callbacks.
@@ -34,6 +38,11 @@ func NewDownloader(outputDir string) *Downloader {
 	}
 }
 
+// SetOutputTemplate sets the yt-dlp output template, relative to the output directory.
+func (d *Downloader) SetOutputTemplate(tpl string) {
+	d.template = tpl
+}
+
 // SetProgressCallback sets the callback for progress updates.
 func (d *Downloader) SetProgressCallback(fn func(id string, progress float64)) {
 	d.onProgress = fn
@@ -49,15 +58,64 @@ func (d *Downloader) SetArtifactCallback(fn func(id string, paths []string)) {
 	d.onArtifacts = fn
 }
 
+// SetActivityCallback sets the callback for every line yt-dlp prints. Progress
+// lines report the bytes downloaded so far; other lines report -1.
+func (d *Downloader) SetActivityCallback(fn func(id string, downloadedBytes float64)) {
+	d.onActivity = fn
+}
+
 // Download executes a yt-dlp download for the given URL.
 // It blocks until the download completes or fails.
 func (d *Downloader) Download(ctx context.Context, id, url string) error {
+	return d.DownloadWith(ctx, id, url, RunOptions{})
+}
+
+// RunOptions adjusts a single download run.
+type RunOptions struct {
+	// InfoJSONPath has yt-dlp load a previously probed info JSON instead of
+	// extracting the URL again. Format URLs in the file expire, so callers
+	// should only pass recent probes.
+	InfoJSONPath string
+	// FormatID pins a yt-dlp format (-f); empty uses yt-dlp's default selection.
+	FormatID string
+	// Sections downloads only these parts of the video; empty downloads all of it.
+	Sections []Section
+	// SplitChapters also writes each chapter to its own file, next to the full one.
+	SplitChapters bool
+}
+
+// apply adds the options to arguments built by buildYTDLPArgs with outTpl.
+func (o RunOptions) apply(args []string, outTpl string) []string {
+	if o.FormatID != "" {
+		args = append(args, "-f", o.FormatID)
+	}
+	for _, sec := range o.Sections {
+		args = append(args, "--download-sections", sec.ytdlpArg())
+	}
+	if o.SplitChapters {
+		args = append(args, "--split-chapters", "--output", "chapter:"+chapterTemplate(outTpl))
+	}
+	if o.InfoJSONPath != "" {
+		// The info JSON replaces the URL, which buildYTDLPArgs puts first.
+		args = append([]string{"--load-info-json", o.InfoJSONPath}, args[1:]...)
+	}
+	return args
+}
+
+// DownloadWith is like Download with per-run options.
+func (d *Downloader) DownloadWith(ctx context.Context, id, url string, opts RunOptions) error {
 	// Defensive: ensure yt-dlp exists.
 	if err := CheckYTDLP(); err != nil {
 		return fmt.Errorf("yt_dlp_not_found: %w", err)
 	}
 
-	outTpl := "%(title).200s-%(id)s.%(ext)s"
+	outTpl := d.template
+	if outTpl == "" {
+		outTpl = defaultOutputTemplate
+	}
+	if len(opts.Sections) > 0 {
+		outTpl = sectionTemplate(outTpl)
+	}
 	tempDir := d.tempDirForID(id)
 	if err := os.MkdirAll(tempDir, 0o755); err != nil {
 		return fmt.Errorf("create temp dir: %w", err)
@@ -65,20 +123,25 @@ func (d *Downloader) Download(ctx context.Context, id, url string) error {
 
 	logging.LogYTDLPCommand(id, url, outTpl, false)
 
-	args := buildYTDLPArgs(url, outTpl, d.outDir, tempDir, true)
-	cmd := exec.CommandContext(ctx, "yt-dlp", args...)
+	args := append(opts.apply(buildYTDLPArgs(url, outTpl, d.outDir, tempDir, true), outTpl), d.archive.args()...)
+	cmd := exec.CommandContext(ctx, YTDLPPath(), args...)
+	useProcessGroup(cmd)
 
 	if err := d.executeWithProgressTracking(id, cmd); err != nil {
 		if ctx.Err() != nil || !shouldRetryWithoutThumbnail(err) {
 			return err
 		}
+		// This also removes a probed info file, so the retry extracts afresh.
 		_ = os.RemoveAll(tempDir)
 		if mkErr := os.MkdirAll(tempDir, 0o755); mkErr != nil {
 			return fmt.Errorf("recreate temp dir for thumbnail fallback: %w", mkErr)
 		}
 
-		retryArgs := buildYTDLPArgs(url, outTpl, d.outDir, tempDir, false)
-		retryCmd := exec.CommandContext(ctx, "yt-dlp", retryArgs...)
+		retry := opts
+		retry.InfoJSONPath = ""
+		retryArgs := append(retry.apply(buildYTDLPArgs(url, outTpl, d.outDir, tempDir, false), outTpl), d.archive.args()...)
+		retryCmd := exec.CommandContext(ctx, YTDLPPath(), retryArgs...)
+		useProcessGroup(retryCmd)
 		if retryErr := d.executeWithProgressTracking(id, retryCmd); retryErr != nil {
 			return retryErr
 		}
@@ -121,7 +184,7 @@ func shouldRetryWithoutThumbnail(err error) bool {
 }
 
 func (d *Downloader) tempDirForID(id string) string {
-	return filepath.Join(d.outDir, ".yt-dlp-tmp", id)
+	return filepath.Join(d.outDir, tempRootName, id)
 }
 
 // CleanupArtifacts removes per-download temporary artifacts and any known partial output.
@@ -201,10 +264,21 @@ func (d *Downloader) executeWithProgressTracking(id string, cmd *exec.Cmd) error
 	wg.Wait()
 
 	waitErr := cmd.Wait()
+	endProcessGroup(cmd)
 
 	// Extract filename and artifact paths from combined output.
 	combined := strings.TrimSpace(stdoutBuf.String() + "\n" + stderrBuf.String())
-	if paths := extractArtifactPaths(combined, d.outDir); len(paths) > 0 && d.onArtifacts != nil {
+	paths := extractArtifactPaths(combined, d.outDir)
+	if waitErr == nil && d.archive.Comments {
+		var err error
+		if paths, err = d.writeCommentsSidecar(paths); err != nil {
+			slog.Warn("failed to archive comments",
+				"event", "archive_comments_failed",
+				"id", id,
+				"error", err)
+		}
+	}
+	if len(paths) > 0 && d.onArtifacts != nil {
 		d.onArtifacts(id, paths)
 	}
 
@@ -238,14 +312,15 @@ func (d *Downloader) parseProgress(id string, sc *bufio.Scanner) {
 
 		// Try to parse as JSON
 		var progress progressData
-		if err := json.Unmarshal([]byte(line), &progress); err != nil {
-			// Not JSON, skip this line (could be other yt-dlp output)
+		if err := json.Unmarshal([]byte(line), &progress); err != nil || progress.Status != "downloading" {
+			// Other yt-dlp output still shows the process is alive
+			if d.onActivity != nil {
+				d.onActivity(id, -1)
+			}
 			continue
 		}
-
-		// Only process if we have valid download progress
-		if progress.Status != "downloading" {
-			continue
+		if d.onActivity != nil {
+			d.onActivity(id, progress.DownloadedBytes)
 		}
 
 		// Calculate percentage from bytes
@@ -322,8 +397,9 @@ func extractFilename(output string) string {
 				continue
 			}
 		}
-		// Record destination lines as a fallback (may be intermediate e.g., fXXX selections)
-		if strings.Contains(line, "Destination:") {
+		// Record destination lines as a fallback (may be intermediate e.g., fXXX selections).
+		// Split chapter pieces are written after the full file and never name it.
+		if strings.Contains(line, "Destination:") && !strings.HasPrefix(line, "[SplitChapters]") {
 			parts := strings.SplitN(line, "Destination:", 2)
 			if len(parts) == 2 {
 				path := strings.TrimSpace(parts[1])
@@ -379,6 +455,14 @@ func extractArtifactPaths(output string, outDir string) []string {
 			}
 			continue
 		}
+		// Archival sidecars: "[info] Writing video description to: X.description"
+		// and "[info] Writing video metadata as JSON to: X.info.json".
+		if strings.HasPrefix(line, "[info] Writing video ") {
+			if _, path, ok := strings.Cut(line, " to: "); ok {
+				add(path)
+			}
+			continue
+		}
 		if strings.HasPrefix(line, "[download]") && strings.Contains(line, "has already been downloaded") {
 			if i := strings.Index(line, "] "); i != -1 {
 				rest := line[i+2:]
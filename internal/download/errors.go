@@ -11,4 +11,40 @@ var (
 
 	// ErrNoMediaInfo indicates metadata extraction produced no results
 	ErrNoMediaInfo = errors.New("no_media_info")
+
+	// ErrUpdateInProgress indicates a yt-dlp update is already running
+	ErrUpdateInProgress = errors.New("update_in_progress")
+
+	// ErrUnknownUpdateMethod indicates an unsupported yt-dlp update method was requested
+	ErrUnknownUpdateMethod = errors.New("unknown_update_method")
+
+	// ErrInvalidPoolSize indicates a worker count or queue capacity below 1 or above its maximum
+	ErrInvalidPoolSize = errors.New("invalid_pool_size")
+
+	// ErrQueueTooSmall indicates the requested queue capacity is below the number of queued jobs
+	ErrQueueTooSmall = errors.New("queue_too_small")
+
+	// ErrUnknownLibrary indicates a request named a library that is not configured
+	ErrUnknownLibrary = errors.New("unknown_library")
+
+	// ErrTimeout classifies jobs and metadata probes that ran past their time limit
+	ErrTimeout = errors.New("timeout")
+
+	// ErrCircuitOpen indicates work for a host was skipped because its circuit breaker is open
+	ErrCircuitOpen = errors.New("circuit_open")
+
+	// ErrStalled classifies downloads the stall watchdog gave up on
+	ErrStalled = errors.New("stalled")
 )
+
+// errorClass returns the class a failure is stored under, so retry and
+// dashboard code can tell failures apart without parsing messages. Failures
+// outside the classes above return "".
+func errorClass(err error) string {
+	for _, class := range []error{ErrTimeout, ErrStalled} {
+		if errors.Is(err, class) {
+			return class.Error()
+		}
+	}
+	return ""
+}
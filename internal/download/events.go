@@ -0,0 +1,265 @@
+package download
+
+import (
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EventType identifies a download lifecycle event published by the Manager.
+type EventType string
+
+const (
+	EventQueued        EventType = "queued"
+	EventStarted       EventType = "started"
+	EventMetadata      EventType = "metadata"
+	EventProgress      EventType = "progress"
+	EventStateChanged  EventType = "state_changed"
+	EventArtifactAdded EventType = "artifact_added"
+	EventCompleted     EventType = "completed"
+	EventFailed        EventType = "failed"
+)
+
+// Event carries a snapshot of the item taken when the event was published, so
+// consumers never need to re-read it from the registry or the database.
+type Event struct {
+	Type EventType `json:"type"`
+	Item Item      `json:"item"`
+	At   time.Time `json:"at"`
+
+	// PrevState is the state a requeued item was resumed from; empty for new jobs (EventQueued only).
+	PrevState State `json:"prev_state,omitempty"`
+
+	// Artifacts lists every file tracked for the job so far (EventArtifactAdded, EventCompleted).
+	Artifacts []string `json:"artifacts,omitempty"`
+}
+
+// DeliveryPolicy controls what a subscriber does when it falls behind.
+type DeliveryPolicy int
+
+const (
+	// DeliverSync runs the handler on the publishing goroutine before Publish
+	// returns. Only for fast subscribers that must see every event in order,
+	// such as persistence.
+	DeliverSync DeliveryPolicy = iota
+	// BlockWhenFull buffers events and makes publishers wait for room.
+	BlockWhenFull
+	// DropNewest discards the incoming event when the buffer is full.
+	DropNewest
+	// DropOldest discards the oldest buffered event to make room.
+	DropOldest
+)
+
+const defaultSubscriberBuffer = 256
+
+// SubscribeOptions configures a subscriber.
+type SubscribeOptions struct {
+	// Types limits delivery to these events; empty means all.
+	Types []EventType
+	// Buffer is the channel size for asynchronous policies; 0 uses a default.
+	Buffer int
+	Policy DeliveryPolicy
+}
+
+// Subscription is a registered subscriber. Asynchronous subscriptions read
+// events from Events until Close.
+type Subscription struct {
+	name    string
+	policy  DeliveryPolicy
+	types   map[EventType]struct{}
+	handler func(Event) // DeliverSync only
+	ch      chan Event
+	done    chan struct{}
+	dropped atomic.Uint64
+
+	mu     sync.RWMutex // guards closing ch against in-flight sends
+	closed bool
+	once   sync.Once
+	bus    *EventBus
+}
+
+// Events returns the channel events are delivered on; nil for DeliverSync.
+func (s *Subscription) Events() <-chan Event { return s.ch }
+
+// Dropped reports how many events the drop policy discarded.
+func (s *Subscription) Dropped() uint64 { return s.dropped.Load() }
+
+// Close unregisters the subscription and closes its channel. Events already
+// buffered can still be read.
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		close(s.done) // release publishers blocked on a full buffer
+		s.bus.remove(s)
+		s.mu.Lock()
+		s.closed = true
+		if s.ch != nil {
+			close(s.ch)
+		}
+		s.mu.Unlock()
+		if n := s.dropped.Load(); n > 0 {
+			slog.Debug("event subscriber closed with dropped events", "subscriber", s.name, "dropped", n)
+		}
+	})
+}
+
+func (s *Subscription) wants(t EventType) bool {
+	if len(s.types) == 0 {
+		return true
+	}
+	_, ok := s.types[t]
+	return ok
+}
+
+func (s *Subscription) deliver(e Event) {
+	if s.policy == DeliverSync {
+		s.handler(e)
+		return
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return
+	}
+	switch s.policy {
+	case BlockWhenFull:
+		select {
+		case s.ch <- e:
+		case <-s.done:
+		}
+	case DropNewest:
+		select {
+		case s.ch <- e:
+		default:
+			s.dropped.Add(1)
+		}
+	case DropOldest:
+		for {
+			select {
+			case s.ch <- e:
+				return
+			default:
+			}
+			select {
+			case <-s.ch:
+				s.dropped.Add(1)
+			default:
+			}
+		}
+	}
+}
+
+// EventBus fans Manager events out to subscribers.
+type EventBus struct {
+	mu     sync.RWMutex
+	subs   []*Subscription // registration order; sync handlers run in this order
+	closed bool
+	wg     sync.WaitGroup // handler goroutines started by Handle
+}
+
+// NewEventBus returns an empty bus.
+func NewEventBus() *EventBus {
+	return &EventBus{}
+}
+
+// Subscribe registers a subscriber and returns it. With DeliverSync the
+// subscription has no channel and is only useful through Handle.
+func (b *EventBus) Subscribe(name string, opts SubscribeOptions) *Subscription {
+	return b.subscribe(name, opts, nil)
+}
+
+// Handle runs fn for every matching event until the returned stop function is
+// called or the bus closes. Asynchronous policies run fn on a dedicated
+// goroutine that drains the buffer before exiting.
+func (b *EventBus) Handle(name string, opts SubscribeOptions, fn func(Event)) (stop func()) {
+	sub := b.subscribe(name, opts, fn)
+	if sub.policy == DeliverSync {
+		return sub.Close
+	}
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		for e := range sub.ch {
+			fn(e)
+		}
+	}()
+	return sub.Close
+}
+
+func (b *EventBus) subscribe(name string, opts SubscribeOptions, fn func(Event)) *Subscription {
+	sub := &Subscription{
+		name:    name,
+		policy:  opts.Policy,
+		handler: fn,
+		done:    make(chan struct{}),
+		bus:     b,
+	}
+	if len(opts.Types) > 0 {
+		sub.types = make(map[EventType]struct{}, len(opts.Types))
+		for _, t := range opts.Types {
+			sub.types[t] = struct{}{}
+		}
+	}
+	if sub.policy != DeliverSync {
+		buffer := opts.Buffer
+		if buffer <= 0 {
+			buffer = defaultSubscriberBuffer
+		}
+		sub.ch = make(chan Event, buffer)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		sub.closed = true
+		close(sub.done)
+		if sub.ch != nil {
+			close(sub.ch)
+		}
+		return sub
+	}
+	b.subs = append(b.subs, sub)
+	return sub
+}
+
+func (b *EventBus) remove(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, s := range b.subs {
+		if s == sub {
+			b.subs = append(b.subs[:i:i], b.subs[i+1:]...)
+			return
+		}
+	}
+}
+
+// Publish delivers e to every interested subscriber. A nil bus drops events.
+func (b *EventBus) Publish(e Event) {
+	if b == nil {
+		return
+	}
+	if e.At.IsZero() {
+		e.At = time.Now().UTC()
+	}
+	b.mu.RLock()
+	subs := b.subs
+	b.mu.RUnlock()
+	for _, sub := range subs {
+		if sub.wants(e.Type) {
+			sub.deliver(e)
+		}
+	}
+}
+
+// Close closes every subscription and waits for Handle goroutines to drain
+// their buffers. Later publishes are dropped; safe to call multiple times.
+func (b *EventBus) Close() {
+	b.mu.Lock()
+	b.closed = true
+	subs := b.subs
+	b.mu.Unlock()
+	for _, sub := range subs {
+		sub.Close()
+	}
+	b.wg.Wait()
+}
@@ -0,0 +1,146 @@
+package download
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestEventBus_SyncHandlersRunInOrderWithTypeFilter(t *testing.T) {
+	bus := NewEventBus()
+	var got []EventType
+	bus.Handle("sync", SubscribeOptions{Policy: DeliverSync, Types: []EventType{EventQueued, EventCompleted}}, func(e Event) {
+		got = append(got, e.Type)
+	})
+
+	for _, typ := range []EventType{EventQueued, EventProgress, EventCompleted} {
+		bus.Publish(Event{Type: typ})
+	}
+	if len(got) != 2 || got[0] != EventQueued || got[1] != EventCompleted {
+		t.Fatalf("expected filtered in-order delivery, got %v", got)
+	}
+}
+
+func TestEventBus_DropPolicies(t *testing.T) {
+	bus := NewEventBus()
+	newest := bus.Subscribe("newest", SubscribeOptions{Policy: DropNewest, Buffer: 2})
+	oldest := bus.Subscribe("oldest", SubscribeOptions{Policy: DropOldest, Buffer: 2})
+
+	for i := 1; i <= 4; i++ {
+		bus.Publish(Event{Type: EventProgress, Item: Item{Progress: float64(i)}})
+	}
+
+	read := func(sub *Subscription) []float64 {
+		var out []float64
+		for len(sub.Events()) > 0 {
+			out = append(out, (<-sub.Events()).Item.Progress)
+		}
+		return out
+	}
+	if got := read(newest); len(got) != 2 || got[0] != 1 || got[1] != 2 || newest.Dropped() != 2 {
+		t.Fatalf("drop newest kept %v (dropped %d)", got, newest.Dropped())
+	}
+	if got := read(oldest); len(got) != 2 || got[0] != 3 || got[1] != 4 || oldest.Dropped() != 2 {
+		t.Fatalf("drop oldest kept %v (dropped %d)", got, oldest.Dropped())
+	}
+}
+
+func TestEventBus_CloseDrainsHandlersAndReleasesBlockedPublishers(t *testing.T) {
+	bus := NewEventBus()
+	var mu sync.Mutex
+	delivered := 0
+	release := make(chan struct{})
+	bus.Handle("slow", SubscribeOptions{Policy: BlockWhenFull, Buffer: 4}, func(e Event) {
+		<-release
+		mu.Lock()
+		delivered++
+		mu.Unlock()
+	})
+	stuck := bus.Subscribe("abandoned", SubscribeOptions{Policy: BlockWhenFull, Buffer: 1})
+
+	bus.Publish(Event{Type: EventProgress})
+	// The abandoned subscriber is now full; closing it must unblock these publishes.
+	published := make(chan struct{})
+	go func() {
+		for i := 0; i < 3; i++ {
+			bus.Publish(Event{Type: EventProgress})
+		}
+		close(published)
+	}()
+	stuck.Close()
+	<-published
+
+	close(release)
+	bus.Close()
+	mu.Lock()
+	defer mu.Unlock()
+	if delivered != 4 {
+		t.Fatalf("expected buffered events drained before Close returned, got %d", delivered)
+	}
+	bus.Publish(Event{Type: EventProgress}) // no panic after close
+}
+
+func TestManager_PublishesLifecycleEvents(t *testing.T) {
+	m := NewManager(t.TempDir(), 1, 4)
+	m.workerDownload = func(ctx context.Context, id, url string) error {
+		m.updateProgress(id, 50)
+		m.setFilename(id, "video.mp4")
+		return nil
+	}
+
+	var mu sync.Mutex
+	var events []Event
+	m.Events().Handle("test", SubscribeOptions{Policy: DeliverSync}, func(e Event) {
+		mu.Lock()
+		events = append(events, e)
+		mu.Unlock()
+	})
+
+	id, err := m.Enqueue("https://example.com/v")
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	waitForItem(t, m, id, func(it *Item) bool { return it.State == StateCompleted })
+	m.Shutdown()
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []EventType{EventQueued, EventStateChanged, EventStarted, EventProgress, EventArtifactAdded, EventProgress, EventStateChanged, EventCompleted}
+	if len(events) != len(want) {
+		t.Fatalf("expected %d events, got %d: %+v", len(want), len(events), events)
+	}
+	for i, typ := range want {
+		if events[i].Type != typ {
+			t.Fatalf("event %d: expected %s, got %s", i, typ, events[i].Type)
+		}
+	}
+	done := events[len(events)-1]
+	if done.Item.State != StateCompleted || done.Item.Filename != "video.mp4" || len(done.Artifacts) != 1 {
+		t.Fatalf("expected completed snapshot with artifact, got %+v", done)
+	}
+	if events[3].Item.Progress != 50 {
+		t.Fatalf("expected progress snapshot at 50, got %v", events[3].Item.Progress)
+	}
+}
+
+func TestManager_SetMetaPublishesMetadataEvent(t *testing.T) {
+	m := NewManager(t.TempDir(), 1, 4)
+	got := make(chan Event, 1)
+	m.Events().Handle("test", SubscribeOptions{Types: []EventType{EventMetadata}, Policy: DeliverSync}, func(e Event) { got <- e })
+
+	m.SetMeta("missing", "t", 1, "https://img.example/x.jpg")
+	select {
+	case e := <-got:
+		t.Fatalf("expected no event for unknown item, got %+v", e)
+	default:
+	}
+
+	_, _ = m.registry.Create("abc", "https://example.com/v")
+	m.AttachDB("abc", 7)
+	m.SetMeta("abc", "Title", 60, "https://img.example/x.jpg")
+	e := <-got
+	if e.Item.DBID != 7 || e.Item.ThumbnailURL != "https://img.example/x.jpg" || e.Item.Title != "Title" {
+		t.Fatalf("expected metadata snapshot, got %+v", e.Item)
+	}
+	m.Shutdown()
+}
@@ -34,3 +34,17 @@ func TestExtractFilename_FallbackDestination(t *testing.T) {
 		t.Fatalf("want %q, got %q", want, got)
 	}
 }
+
+func TestExtractFilename_IgnoresSplitChapters(t *testing.T) {
+	log := `[download] Destination: /videos/Mix-abc.webm
+[SplitChapters] Splitting video by chapters; 2 chapters found
+[SplitChapters] Chapter 001; Destination: /videos/Mix-abc.chapters/001 - Intro.webm
+[SplitChapters] Chapter 002; Destination: /videos/Mix-abc.chapters/002 - Outro.webm`
+	if got := extractFilename(log); got != "Mix-abc.webm" {
+		t.Fatalf("want the full file, got %q", got)
+	}
+	paths := extractArtifactPaths(log, "/videos")
+	if files := ChapterFiles(paths); len(files) != 2 {
+		t.Fatalf("expected both pieces tracked as artifacts, got %v", paths)
+	}
+}
@@ -0,0 +1,217 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+)
+
+// setHold adds or removes a reason that blocks workers from starting new jobs.
+// Returns true when the hold set changed.
+func (m *Manager) setHold(reason string, on bool) bool {
+	m.holdMu.Lock()
+	defer m.holdMu.Unlock()
+	if m.holds == nil {
+		m.holds = make(map[string]struct{})
+	}
+	_, had := m.holds[reason]
+	if on == had {
+		return false
+	}
+	if on {
+		m.holds[reason] = struct{}{}
+	} else {
+		delete(m.holds, reason)
+	}
+	// Wake every waiting worker so it re-evaluates the hold set.
+	if m.holdCh != nil {
+		close(m.holdCh)
+	}
+	m.holdCh = make(chan struct{})
+	return true
+}
+
+// Holds returns the reasons currently blocking dispatch, sorted.
+func (m *Manager) Holds() []string {
+	m.holdMu.Lock()
+	defer m.holdMu.Unlock()
+	out := make([]string, 0, len(m.holds))
+	for reason := range m.holds {
+		out = append(out, reason)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// waitForDispatch blocks until no hold is active.
+// Returns false if the manager shuts down while waiting.
+func (m *Manager) waitForDispatch() bool {
+	ctx := m.runCtx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	for {
+		m.holdMu.Lock()
+		if len(m.holds) == 0 {
+			m.holdMu.Unlock()
+			return true
+		}
+		if m.holdCh == nil {
+			m.holdCh = make(chan struct{})
+		}
+		wake := m.holdCh
+		m.holdMu.Unlock()
+
+		select {
+		case <-wake:
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+func (m *Manager) markParked(id, reason string) {
+	m.holdMu.Lock()
+	defer m.holdMu.Unlock()
+	if m.parked == nil {
+		m.parked = make(map[string]string)
+	}
+	m.parked[id] = reason
+}
+
+func (m *Manager) unpark(id string) {
+	m.holdMu.Lock()
+	defer m.holdMu.Unlock()
+	delete(m.parked, id)
+}
+
+func (m *Manager) parkedIDs(reason string) []string {
+	m.holdMu.Lock()
+	defer m.holdMu.Unlock()
+	out := make([]string, 0, len(m.parked))
+	for id, r := range m.parked {
+		if r == reason {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// parkQueuedJob pauses a dequeued job that must not start yet and remembers
+// reason so resumeParked can requeue it later.
+func (m *Manager) parkQueuedJob(j job, reason string) bool {
+	parked := false
+	_ = m.registry.Update(j.id, func(it *Item) {
+		if it.State != StateQueued || it.queueToken != j.token {
+			return
+		}
+		it.State = StatePaused
+		it.Error = reason
+		parked = true
+	})
+	if !parked {
+		return false
+	}
+	m.markParked(j.id, reason)
+	m.updateState(j.id, StatePaused, reason)
+	return true
+}
+
+// pauseActiveForReason stops every running job through the stop-intent path,
+// tagging the resulting pause with reason. Jobs already being stopped by a
+// user action are left alone. Returns the number of jobs signalled.
+func (m *Manager) pauseActiveForReason(reason string) int {
+	m.activeMu.Lock()
+	if m.stopReasons == nil {
+		m.stopReasons = make(map[string]string)
+	}
+	entries := make([]*activeDownload, 0, len(m.activeByID))
+	for id, entry := range m.activeByID {
+		if _, stopping := m.stopIntents[id]; stopping {
+			continue
+		}
+		m.stopIntents[id] = StatePaused
+		m.stopReasons[id] = reason
+		entries = append(entries, entry)
+	}
+	m.activeMu.Unlock()
+
+	for _, entry := range entries {
+		m.markParked(entry.id, reason)
+		if entry.cancel != nil {
+			entry.cancel()
+		}
+	}
+	return len(entries)
+}
+
+// resumeLiftedHolds resumes items still parked for maintenance after the
+// hold lifted: ones that finished stopping late, or that found the queue full.
+func (m *Manager) resumeLiftedHolds() {
+	m.holdMu.Lock()
+	_, held := m.holds[ReasonMaintenance]
+	m.holdMu.Unlock()
+	if held || len(m.parkedIDs(ReasonMaintenance)) == 0 {
+		return
+	}
+	if n := m.resumeParked(ReasonMaintenance, nil); n > 0 {
+		slog.Info("resumed downloads paused for maintenance", "event", "maintenance_resume", "count", n)
+	}
+}
+
+func (m *Manager) consumeStopReason(id string) string {
+	m.activeMu.Lock()
+	defer m.activeMu.Unlock()
+	reason := m.stopReasons[id]
+	delete(m.stopReasons, id)
+	return reason
+}
+
+// resumeParked requeues items parked for reason, oldest first. Items a user
+// has since resumed, canceled or re-paused are forgotten; items still winding
+// down are kept for the next pass. fits may veto individual items.
+func (m *Manager) resumeParked(reason string, fits func(it *Item) bool) int {
+	ids := m.parkedIDs(reason)
+	items := make([]*Item, 0, len(ids))
+	for _, id := range ids {
+		it := m.registry.Get(id)
+		switch {
+		case it == nil:
+			m.unpark(id)
+		case it.State == StateDownloading:
+			// Stop intent not processed yet; retry on the next pass.
+		case it.State != StatePaused || it.Error != reason:
+			m.unpark(id)
+		default:
+			items = append(items, it)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].startedAt.Before(items[j].startedAt)
+	})
+
+	resumed := 0
+	for _, it := range items {
+		if fits != nil && !fits(it) {
+			continue
+		}
+		ok, err := m.resumeItem(it)
+		if err != nil {
+			if !errors.Is(err, ErrQueueFull) && !errors.Is(err, ErrShuttingDown) {
+				slog.Warn("failed to resume parked download",
+					"event", "parked_resume_error",
+					"id", it.ID,
+					"reason", reason,
+					"error", err)
+			}
+			break
+		}
+		if !ok {
+			continue
+		}
+		m.unpark(it.ID)
+		resumed++
+	}
+	return resumed
+}
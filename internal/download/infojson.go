@@ -0,0 +1,127 @@
+package download
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"videofetch/internal/logging"
+)
+
+// probeInfoName is the probe's info JSON inside the job's temp dir.
+const probeInfoName = "probe.info.json"
+
+// InfoJSONMetrics counts how often downloads reused the metadata probe.
+type InfoJSONMetrics struct {
+	Saved     int64 `json:"saved"`     // probes whose info JSON was kept for the download
+	Reused    int64 `json:"reused"`    // downloads that finished from a saved info JSON
+	Expired   int64 `json:"expired"`   // saved info JSON was too old or gone; extracted afresh
+	Fallbacks int64 `json:"fallbacks"` // download from info JSON failed and was redone with a fresh extraction
+	// SavedProbeSeconds is the probe time the reused downloads did not spend again.
+	SavedProbeSeconds float64 `json:"saved_probe_seconds"`
+}
+
+// Metrics is a point-in-time view of manager counters.
+type Metrics struct {
+	InfoJSON InfoJSONMetrics `json:"info_json"`
+}
+
+type infoJSONCounters struct {
+	saved, reused, expired, fallbacks atomic.Int64
+	savedNanos                        atomic.Int64
+}
+
+// SetInfoJSONTTL sets how long a probe's info JSON may be loaded by the
+// download step. 0 disables reuse, so every download extracts afresh.
+func (m *Manager) SetInfoJSONTTL(d time.Duration) {
+	m.infoJSONTTL.Store(int64(max(d, 0)))
+}
+
+// Metrics returns the manager counters.
+func (m *Manager) Metrics() Metrics {
+	c := &m.infoStats
+	return Metrics{InfoJSON: InfoJSONMetrics{
+		Saved:             c.saved.Load(),
+		Reused:            c.reused.Load(),
+		Expired:           c.expired.Load(),
+		Fallbacks:         c.fallbacks.Load(),
+		SavedProbeSeconds: time.Duration(c.savedNanos.Load()).Seconds(),
+	}}
+}
+
+// saveProbedInfo writes the probe's info JSON into the job's temp dir before
+// the job becomes visible to workers. Failing to write only costs the reuse.
+func (m *Manager) saveProbedInfo(id, library string, opts EnqueueOptions) {
+	if opts.InfoJSON == "" || m.infoJSONTTL.Load() <= 0 {
+		return
+	}
+	dir := m.downloaderFor(library).tempDirForID(id)
+	err := os.MkdirAll(dir, 0o755)
+	if err == nil {
+		err = os.WriteFile(filepath.Join(dir, probeInfoName), []byte(opts.InfoJSON), 0o644)
+	}
+	if err != nil {
+		slog.Warn("failed to save probe info JSON",
+			"event", "info_json_save_error",
+			"id", id,
+			"error", err)
+		return
+	}
+	now := time.Now()
+	_ = m.registry.Update(id, func(it *Item) {
+		it.infoSavedAt = now
+		it.probeTime = opts.ProbeTime
+	})
+	m.infoStats.saved.Add(1)
+}
+
+// discardProbedInfo removes the temp dir of a job that never got queued.
+func (m *Manager) discardProbedInfo(id, library string) {
+	_ = os.RemoveAll(m.downloaderFor(library).tempDirForID(id))
+}
+
+// probedDownload returns the download function for a job: loading the saved
+// info JSON while it is fresh, extracting afresh otherwise. A failed download
+// from info JSON, typically a format URL that expired early, is retried once
+// with a fresh extraction. Archiving comments always extracts afresh, as the
+// probe's info JSON carries none.
+func (m *Manager) probedDownload(it *Item, dl *Downloader) func(ctx context.Context, id, url string) error {
+	var run RunOptions
+	if it != nil {
+		run.FormatID, run.Sections, run.SplitChapters = it.formatID, it.sections, it.splitChapters
+	}
+	if it == nil || it.infoSavedAt.IsZero() || dl.archive.Comments {
+		return func(ctx context.Context, id, url string) error { return dl.DownloadWith(ctx, id, url, run) }
+	}
+	savedAt, probeTime := it.infoSavedAt, it.probeTime
+	return func(ctx context.Context, id, url string) error {
+		infoPath := filepath.Join(dl.tempDirForID(id), probeInfoName)
+		ttl := time.Duration(m.infoJSONTTL.Load())
+		if _, statErr := os.Stat(infoPath); statErr != nil || ttl <= 0 || time.Since(savedAt) > ttl {
+			m.infoStats.expired.Add(1)
+			return dl.DownloadWith(ctx, id, url, run)
+		}
+		fromInfo := run
+		fromInfo.InfoJSONPath = infoPath
+		err := dl.DownloadWith(ctx, id, url, fromInfo)
+		if err == nil {
+			m.infoStats.reused.Add(1)
+			m.infoStats.savedNanos.Add(int64(probeTime))
+			return nil
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+		m.infoStats.fallbacks.Add(1)
+		slog.Warn("download from probe info JSON failed; extracting again",
+			"event", "info_json_fallback",
+			"id", id,
+			"url", logging.RedactURL(url),
+			"error", err)
+		_ = os.Remove(infoPath)
+		return dl.DownloadWith(ctx, id, url, run)
+	}
+}
@@ -0,0 +1,114 @@
+//go:build !windows
+
+package download
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// installArgLoggingYTDLP installs a fake yt-dlp that records its arguments and
+// fails to load info JSON for jobs whose ID contains "broken".
+func installArgLoggingYTDLP(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "args.log")
+	fake := filepath.Join(dir, "yt-dlp")
+	script := `#!/usr/bin/env bash
+if [[ "${1:-}" == "--help" ]]; then
+  echo "supports --progress-template"
+  exit 0
+fi
+printf '%s\n' "$*" >> "` + logPath + `"
+if [[ "$1" == "--load-info-json" && "$2" == */broken/* ]]; then
+  echo "ERROR: HTTP Error 403: Forbidden" >&2
+  exit 1
+fi
+echo "[download] Destination: ok.mp4" >&2
+exit 0
+`
+	if err := os.WriteFile(fake, []byte(script), 0o755); err != nil {
+		t.Fatalf("write fake yt-dlp: %v", err)
+	}
+	SetYTDLPPath(fake)
+	t.Cleanup(func() { SetYTDLPPath("") })
+	return logPath
+}
+
+func readArgLog(t *testing.T, path string) []string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read args log: %v", err)
+	}
+	return strings.Split(strings.TrimSpace(string(b)), "\n")
+}
+
+func TestEnqueue_DownloadLoadsProbedInfoJSON(t *testing.T) {
+	logPath := installArgLoggingYTDLP(t)
+	m := NewManager(t.TempDir(), 1, 4)
+	defer m.Shutdown()
+	m.SetInfoJSONTTL(time.Hour)
+
+	id, err := m.EnqueueWithOptions("https://example.com/v", EnqueueOptions{InfoJSON: `{"id":"v"}`, ProbeTime: 1500 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	waitForItem(t, m, id, func(it *Item) bool { return it.State == StateCompleted })
+
+	calls := readArgLog(t, logPath)
+	if len(calls) != 1 || !strings.HasPrefix(calls[0], "--load-info-json ") || strings.Contains(calls[0], "https://example.com/v") {
+		t.Fatalf("expected one download from the probed info JSON, got %q", calls)
+	}
+	got := m.Metrics().InfoJSON
+	if got.Saved != 1 || got.Reused != 1 || got.SavedProbeSeconds != 1.5 {
+		t.Fatalf("unexpected metrics %+v", got)
+	}
+}
+
+func TestProbedDownload_ExpiredOrFailedExtractsAgain(t *testing.T) {
+	logPath := installArgLoggingYTDLP(t)
+	m := NewManager(t.TempDir(), 1, 4)
+	defer m.Shutdown()
+	m.SetInfoJSONTTL(time.Minute)
+	dl := m.downloaderFor(DefaultLibraryName)
+
+	cases := []struct {
+		id, url string
+		age     time.Duration
+	}{
+		{"stale", "https://example.com/stale", time.Hour},
+		{"broken", "https://example.com/expired", time.Second},
+	}
+	for _, c := range cases {
+		if err := os.MkdirAll(dl.tempDirForID(c.id), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dl.tempDirForID(c.id), probeInfoName), []byte(`{}`), 0o644); err != nil {
+			t.Fatalf("write info: %v", err)
+		}
+		it := &Item{ID: c.id, infoSavedAt: time.Now().Add(-c.age), probeTime: time.Second}
+		if err := m.probedDownload(it, dl)(context.Background(), c.id, c.url); err != nil {
+			t.Fatalf("%s: download: %v", c.id, err)
+		}
+	}
+
+	calls := readArgLog(t, logPath)
+	want := []string{"https://example.com/stale ", "--load-info-json ", "https://example.com/expired "}
+	if len(calls) != len(want) {
+		t.Fatalf("expected %d yt-dlp runs, got %q", len(want), calls)
+	}
+	for i, prefix := range want {
+		if !strings.HasPrefix(calls[i], prefix) {
+			t.Fatalf("run %d: expected prefix %q, got %q", i, prefix, calls[i])
+		}
+	}
+	got := m.Metrics().InfoJSON
+	if got.Expired != 1 || got.Fallbacks != 1 || got.Reused != 0 || got.SavedProbeSeconds != 0 {
+		t.Fatalf("unexpected metrics %+v", got)
+	}
+}
@@ -0,0 +1,130 @@
+package download
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// DefaultLibraryName is the library rooted at the manager's output directory.
+// It matches config.DefaultLibraryName and store.DefaultLibrary.
+const DefaultLibraryName = "default"
+
+// defaultOutputTemplate names files when a library has no template of its own.
+const defaultOutputTemplate = "%(title).200s-%(id)s.%(ext)s"
+
+// Library is a named download destination. Jobs select one by name; roots
+// come from configuration only, never from requests.
+type Library struct {
+	Name     string `json:"name"`
+	Root     string `json:"root"`
+	Template string `json:"template,omitempty"`
+	// MaxDuration caps how long one job in this library may run; 0 uses the manager's limit.
+	MaxDuration time.Duration `json:"-"`
+	// Archive selects the sidecars kept next to each download.
+	Archive Archive `json:"archive"`
+}
+
+type libraryEntry struct {
+	lib        Library
+	downloader *Downloader
+}
+
+// SetLibraries registers named libraries in addition to the default one.
+// Each gets its own Downloader so output root and template stay per library.
+func (m *Manager) SetLibraries(libs []Library) error {
+	entries := make(map[string]*libraryEntry, len(libs))
+	for _, lib := range libs {
+		if lib.Name == "" || lib.Name == DefaultLibraryName {
+			return fmt.Errorf("invalid library name %q", lib.Name)
+		}
+		if _, dup := entries[lib.Name]; dup {
+			return fmt.Errorf("duplicate library %q", lib.Name)
+		}
+		if !filepath.IsAbs(lib.Root) {
+			return fmt.Errorf("library %s root must be absolute: %q", lib.Name, lib.Root)
+		}
+		dl := NewDownloader(lib.Root)
+		dl.SetOutputTemplate(lib.Template)
+		dl.SetArchive(lib.Archive)
+		m.wireDownloader(dl)
+		entries[lib.Name] = &libraryEntry{lib: lib, downloader: dl}
+	}
+	m.libMu.Lock()
+	m.libraries = entries
+	m.libMu.Unlock()
+	return nil
+}
+
+// Libraries lists the default library followed by named ones sorted by name.
+func (m *Manager) Libraries() []Library {
+	m.libMu.RLock()
+	out := make([]Library, 0, len(m.libraries)+1)
+	for _, e := range m.libraries {
+		out = append(out, e.lib)
+	}
+	m.libMu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return append([]Library{m.defaultLibrary()}, out...)
+}
+
+// Library resolves a library by name; an empty name selects the default.
+func (m *Manager) Library(name string) (Library, bool) {
+	if name == "" || name == DefaultLibraryName {
+		return m.defaultLibrary(), true
+	}
+	m.libMu.RLock()
+	defer m.libMu.RUnlock()
+	e, ok := m.libraries[name]
+	if !ok {
+		return Library{}, false
+	}
+	return e.lib, true
+}
+
+func (m *Manager) defaultLibrary() Library {
+	var tpl string
+	var archive Archive
+	if m.downloader != nil {
+		tpl, archive = m.downloader.template, m.downloader.archive
+	}
+	return Library{Name: DefaultLibraryName, Root: m.outDir, Template: tpl, Archive: archive}
+}
+
+// downloaderFor returns the Downloader writing into the named library,
+// falling back to the default one.
+func (m *Manager) downloaderFor(name string) *Downloader {
+	if name != "" && name != DefaultLibraryName {
+		m.libMu.RLock()
+		e, ok := m.libraries[name]
+		m.libMu.RUnlock()
+		if ok {
+			return e.downloader
+		}
+	}
+	return m.downloader
+}
+
+// libraryRoot returns the output root for the named library.
+func (m *Manager) libraryRoot(name string) string {
+	if lib, ok := m.Library(name); ok {
+		return lib.Root
+	}
+	return m.outDir
+}
+
+// libraryRoots lists every distinct output root, default first.
+func (m *Manager) libraryRoots() []string {
+	seen := make(map[string]struct{})
+	roots := make([]string, 0, 1)
+	for _, lib := range m.Libraries() {
+		root := filepath.Clean(lib.Root)
+		if _, dup := seen[root]; dup {
+			continue
+		}
+		seen[root] = struct{}{}
+		roots = append(roots, lib.Root)
+	}
+	return roots
+}
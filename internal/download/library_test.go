@@ -0,0 +1,56 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestLibraries_RouteJobsToLibraryRoot(t *testing.T) {
+	m := NewManager(t.TempDir(), 1, 4)
+	defer m.Shutdown()
+	musicRoot := t.TempDir()
+	if err := m.SetLibraries([]Library{{Name: "music", Root: musicRoot, Template: "%(artist)s/%(title)s.%(ext)s"}}); err != nil {
+		t.Fatalf("set libraries: %v", err)
+	}
+	if err := m.SetLibraries([]Library{{Name: "default", Root: musicRoot}}); err == nil {
+		t.Fatalf("expected reserved default name to be rejected")
+	}
+	if err := m.SetLibraries([]Library{{Name: "rel", Root: "relative/dir"}}); err == nil {
+		t.Fatalf("expected relative root to be rejected")
+	}
+	if err := m.SetLibraries([]Library{{Name: "music", Root: musicRoot, Template: "%(artist)s/%(title)s.%(ext)s"}}); err != nil {
+		t.Fatalf("set libraries: %v", err)
+	}
+
+	libs := m.Libraries()
+	if len(libs) != 2 || libs[0].Name != DefaultLibraryName || libs[1].Name != "music" {
+		t.Fatalf("expected default then music, got %+v", libs)
+	}
+	if dl := m.downloaderFor("music"); dl.outDir != musicRoot || dl.template == "" {
+		t.Fatalf("expected music downloader rooted at library, got %+v", dl)
+	}
+
+	if _, err := m.EnqueueWithOptions("https://example.com/x", EnqueueOptions{Library: "../etc"}); !errors.Is(err, ErrUnknownLibrary) {
+		t.Fatalf("expected ErrUnknownLibrary, got %v", err)
+	}
+
+	var tracked []string
+	m.workerDownload = func(ctx context.Context, id, url string) error {
+		m.setFilename(id, "Artist/song.opus")
+		tracked = m.trackedArtifacts(id)
+		return nil
+	}
+	id, err := m.EnqueueWithOptions("https://example.com/song", EnqueueOptions{Library: "music"})
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	it := waitForItem(t, m, id, func(it *Item) bool { return it.State == StateCompleted })
+	if it.Library != "music" {
+		t.Fatalf("expected item in music library, got %q", it.Library)
+	}
+	if len(tracked) != 1 || tracked[0] != filepath.Join(musicRoot, "Artist/song.opus") {
+		t.Fatalf("expected artifact under library root, got %v", tracked)
+	}
+}
@@ -0,0 +1,110 @@
+package download
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// ReasonMaintenance is recorded on items paused by the global maintenance hold.
+const ReasonMaintenance = "maintenance"
+
+// MaintenanceStore persists the maintenance flag and requeues rows the hold
+// paused before a restart, when they are no longer in the registry.
+type MaintenanceStore interface {
+	SetSetting(ctx context.Context, key, value string) error
+	ResumePausedByReason(ctx context.Context, reason string, skip []int64) (int64, error)
+}
+
+// maintenanceSettingKey matches store.SettingMaintenance; "1" while the hold is on.
+const maintenanceSettingKey = "maintenance"
+
+// MaintenanceStatus describes the global maintenance hold.
+type MaintenanceStatus struct {
+	Active bool       `json:"active"`
+	Since  *time.Time `json:"since,omitempty"`
+	Parked int        `json:"parked"` // jobs paused by the hold that will resume when it lifts
+}
+
+// SetMaintenanceStore enables persistence of the maintenance hold.
+func (m *Manager) SetMaintenanceStore(st MaintenanceStore) {
+	m.maintenanceStore = st
+}
+
+// Maintenance reports whether the maintenance hold is active.
+func (m *Manager) Maintenance() MaintenanceStatus {
+	m.holdMu.Lock()
+	_, active := m.holds[ReasonMaintenance]
+	since := m.maintenanceSince
+	m.holdMu.Unlock()
+
+	st := MaintenanceStatus{Active: active, Parked: len(m.parkedIDs(ReasonMaintenance))}
+	if active {
+		st.Since = &since
+	}
+	return st
+}
+
+// EnterMaintenance stops workers from starting new jobs. With pauseActive,
+// running jobs are paused through the stop-intent path and remembered so
+// ExitMaintenance restarts exactly those.
+func (m *Manager) EnterMaintenance(pauseActive bool) (MaintenanceStatus, error) {
+	if m.setHold(ReasonMaintenance, true) {
+		m.holdMu.Lock()
+		m.maintenanceSince = time.Now().UTC()
+		m.holdMu.Unlock()
+		slog.Info("maintenance mode on; holding new downloads", "event", "maintenance_on")
+	}
+	if pauseActive {
+		if n := m.pauseActiveForReason(ReasonMaintenance); n > 0 {
+			slog.Info("paused in-flight downloads for maintenance", "event", "maintenance_pause", "count", n)
+		}
+	}
+	return m.Maintenance(), m.persistMaintenance(true)
+}
+
+// ExitMaintenance lifts the hold and resumes jobs the hold paused, including
+// rows paused before a restart. Jobs users paused themselves stay paused.
+// Jobs still stopping, or left over when the queue fills, are resumed by a
+// later resumeLiftedHolds pass.
+func (m *Manager) ExitMaintenance() (MaintenanceStatus, error) {
+	if m.setHold(ReasonMaintenance, false) {
+		slog.Info("maintenance mode off; resuming downloads", "event", "maintenance_off")
+	}
+	if err := m.persistMaintenance(false); err != nil {
+		return m.Maintenance(), err
+	}
+	resumed := m.resumeParked(ReasonMaintenance, nil)
+	if m.maintenanceStore != nil {
+		// Rows left paused by a previous process go back to pending for the DB
+		// worker; rows this process still holds are resumed in memory only.
+		n, err := m.maintenanceStore.ResumePausedByReason(context.Background(), ReasonMaintenance, m.registry.DBIDs())
+		if err != nil {
+			return m.Maintenance(), err
+		}
+		resumed += int(n)
+	}
+	if resumed > 0 {
+		slog.Info("resumed downloads paused for maintenance", "event", "maintenance_resume", "count", resumed)
+	}
+	return m.Maintenance(), nil
+}
+
+// ToggleMaintenance flips the hold; used by the SIGUSR1 handler.
+func (m *Manager) ToggleMaintenance(pauseActive bool) (MaintenanceStatus, error) {
+	if m.Maintenance().Active {
+		return m.ExitMaintenance()
+	}
+	return m.EnterMaintenance(pauseActive)
+}
+
+func (m *Manager) persistMaintenance(on bool) error {
+	if m.maintenanceStore == nil {
+		return nil
+	}
+	value := "0"
+	if on {
+		value = "1"
+	}
+	return m.maintenanceStore.SetSetting(context.Background(), maintenanceSettingKey, value)
+}
@@ -0,0 +1,172 @@
+package download
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeMaintenanceStore struct {
+	mu       sync.Mutex
+	settings map[string]string
+	resumed  []string
+	skipped  [][]int64
+}
+
+func (f *fakeMaintenanceStore) SetSetting(ctx context.Context, key, value string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.settings == nil {
+		f.settings = make(map[string]string)
+	}
+	f.settings[key] = value
+	return nil
+}
+
+func (f *fakeMaintenanceStore) ResumePausedByReason(ctx context.Context, reason string, skip []int64) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.resumed = append(f.resumed, reason)
+	f.skipped = append(f.skipped, skip)
+	return 0, nil
+}
+
+func TestMaintenance_ResumesOnlyJobsPausedByHold(t *testing.T) {
+	m := NewManager(t.TempDir(), 2, 4)
+	defer m.Shutdown()
+	fs := &fakeMaintenanceStore{}
+	m.SetMaintenanceStore(fs)
+
+	var calls atomic.Int32
+	m.workerDownload = func(ctx context.Context, id, url string) error {
+		calls.Add(1)
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	userID, err := m.Enqueue("https://example.com/user")
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	m.AttachDB(userID, 1)
+	holdID, err := m.Enqueue("https://example.com/hold")
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	waitForItem(t, m, userID, func(it *Item) bool { return it.State == StateDownloading })
+	waitForItem(t, m, holdID, func(it *Item) bool { return it.State == StateDownloading })
+
+	if !m.PauseByDBID(1) {
+		t.Fatalf("expected user pause to succeed")
+	}
+	waitForItem(t, m, userID, func(it *Item) bool { return it.State == StatePaused })
+
+	st, err := m.EnterMaintenance(true)
+	if err != nil || !st.Active {
+		t.Fatalf("enter maintenance: %+v %v", st, err)
+	}
+	waitForItem(t, m, holdID, func(it *Item) bool {
+		return it.State == StatePaused && it.Error == ReasonMaintenance
+	})
+	if fs.settings[maintenanceSettingKey] != "1" {
+		t.Fatalf("expected maintenance persisted, got %v", fs.settings)
+	}
+
+	queuedID, err := m.Enqueue("https://example.com/queued")
+	if err != nil {
+		t.Fatalf("enqueue during maintenance: %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if it := m.registry.Get(queuedID); it.State != StateQueued {
+		t.Fatalf("expected new job held in queue, got %s", it.State)
+	}
+
+	st, err = m.ExitMaintenance()
+	if err != nil || st.Active {
+		t.Fatalf("exit maintenance: %+v %v", st, err)
+	}
+	waitForItem(t, m, holdID, func(it *Item) bool { return it.State == StateDownloading })
+	waitForItem(t, m, queuedID, func(it *Item) bool { return it.State == StateDownloading })
+	if it := m.registry.Get(userID); it.State != StatePaused {
+		t.Fatalf("expected user-paused job to stay paused, got %s", it.State)
+	}
+	if fs.settings[maintenanceSettingKey] != "0" || len(fs.resumed) != 1 || fs.resumed[0] != ReasonMaintenance {
+		t.Fatalf("unexpected store calls: settings=%v resumed=%v", fs.settings, fs.resumed)
+	}
+	if got := calls.Load(); got != 4 {
+		t.Fatalf("expected 4 download starts, got %d", got)
+	}
+}
+
+func TestMaintenance_ToggleWithoutPausingActive(t *testing.T) {
+	m := NewManager(t.TempDir(), 1, 4)
+	defer m.Shutdown()
+
+	m.workerDownload = func(ctx context.Context, id, url string) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	id, err := m.Enqueue("https://example.com/v")
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	waitForItem(t, m, id, func(it *Item) bool { return it.State == StateDownloading })
+
+	if st, _ := m.EnterMaintenance(false); !st.Active || st.Since == nil || st.Parked != 0 {
+		t.Fatalf("unexpected status: %+v", st)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if it := m.registry.Get(id); it.State != StateDownloading {
+		t.Fatalf("expected active job to keep running, got %s", it.State)
+	}
+	if st, _ := m.ToggleMaintenance(true); st.Active {
+		t.Fatalf("expected toggle to turn maintenance off")
+	}
+}
+
+func TestMaintenance_ExitWhileJobStillStoppingResumesItLater(t *testing.T) {
+	m := NewManager(t.TempDir(), 1, 4)
+	defer m.Shutdown()
+	fs := &fakeMaintenanceStore{}
+	m.SetMaintenanceStore(fs)
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	m.workerDownload = func(ctx context.Context, id, url string) error {
+		if calls.Add(1) == 1 {
+			// The first run is slow to stop, so the hold lifts before it is paused.
+			<-ctx.Done()
+			<-release
+			return ctx.Err()
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	id, err := m.Enqueue("https://example.com/slow")
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	m.AttachDB(id, 7)
+	waitForItem(t, m, id, func(it *Item) bool { return it.State == StateDownloading })
+
+	if _, err := m.EnterMaintenance(true); err != nil {
+		t.Fatalf("enter maintenance: %v", err)
+	}
+	if _, err := m.ExitMaintenance(); err != nil {
+		t.Fatalf("exit maintenance: %v", err)
+	}
+	fs.mu.Lock()
+	skipped := fs.skipped
+	fs.mu.Unlock()
+	if len(skipped) != 1 || len(skipped[0]) != 1 || skipped[0][0] != 7 {
+		t.Fatalf("expected the managed row to be left to the registry, got %v", skipped)
+	}
+
+	close(release)
+	waitForItem(t, m, id, func(it *Item) bool { return it.State == StateDownloading && calls.Load() == 2 })
+	if st := m.Maintenance(); st.Parked != 0 {
+		t.Fatalf("expected nothing left parked, got %+v", st)
+	}
+}
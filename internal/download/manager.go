@@ -28,10 +28,13 @@ const (
 	StateFailed      State = "failed"
 	StatePaused      State = "paused"
 	StateCanceled    State = "canceled"
+	// StateDeferred holds a job whose host's circuit breaker is open.
+	StateDeferred State = "deferred"
 )
 
 const (
-	pendingMetadataTimeout     = 30 * time.Second
+	// DefaultMetadataTimeout bounds one metadata probe attempt unless SetMetadataTimeout overrides it.
+	DefaultMetadataTimeout     = 30 * time.Second
 	pendingMetadataRetryDelay  = 1500 * time.Millisecond
 	pendingMetadataMaxAttempts = 3
 )
@@ -56,6 +59,8 @@ type Item struct {
 	Progress float64 `json:"progress"` // 0-100
 	State    State   `json:"state"`
 	Error    string  `json:"error,omitempty"`
+	// ErrorClass is the stable class of a failed item's error, such as "timeout".
+	ErrorClass string `json:"error_class,omitempty"`
 
 	// Optional metadata for UI convenience.
 	Title        string `json:"title,omitempty"`
@@ -68,9 +73,40 @@ type Item struct {
 	// Filename gets set when download is complete.
 	Filename string `json:"filename,omitempty"`
 
-	startedAt  time.Time
-	updatedAt  time.Time
-	queueToken uint64
+	// EstimatedBytes is the expected output size reported by the metadata probe.
+	EstimatedBytes int64 `json:"estimated_bytes,omitempty"`
+
+	// Library names the destination the file is written to.
+	Library string `json:"library,omitempty"`
+
+	// Upload state of a completed file copied to a remote sink (dashboard rows only).
+	UploadPhase    string  `json:"upload_phase,omitempty"`
+	UploadProgress float64 `json:"upload_progress,omitempty"`
+	UploadError    string  `json:"upload_error,omitempty"`
+
+	// Tags are the row's labels, filled for dashboard rows only.
+	Tags []string `json:"tags,omitempty"`
+	// Snippet is the HTML-escaped search excerpt of a dashboard row matched by a search.
+	Snippet string `json:"snippet,omitempty"`
+	// StallSeconds is how long a running download has gone without progress or output.
+	StallSeconds int64 `json:"stall_seconds,omitempty"`
+	// Sections lists the clip's parts as canonical specs; empty for the whole video.
+	Sections []string `json:"sections,omitempty"`
+	// Chapters lists the pieces of a download split by chapters, filled for dashboard rows only.
+	Chapters []ChapterFile `json:"chapters,omitempty"`
+
+	startedAt     time.Time
+	updatedAt     time.Time
+	queueToken    uint64
+	maxDuration   time.Duration // per-request job limit; 0 falls back to the library's, then the manager's
+	formatID      string        // pinned yt-dlp format; empty uses yt-dlp's default selection
+	sections      []Section     // parts to download; empty downloads the whole video
+	splitChapters bool          // also write each chapter to its own file
+	// Set when the metadata probe's info JSON was saved to the job's temp dir.
+	infoSavedAt time.Time
+	probeTime   time.Duration
+	// Set when the metadata probe was skipped because the host's breaker was open.
+	needsProbe bool
 }
 
 type job struct {
@@ -106,6 +142,57 @@ type Manager struct {
 	activeByID  map[string]*activeDownload
 	activeByDB  map[int64]*activeDownload
 	stopIntents map[string]State
+	stopReasons map[string]string
+
+	// Dispatch holds block workers from starting new jobs; parked items were
+	// paused automatically and are requeued once their hold reason clears.
+	holdMu sync.Mutex
+	holds  map[string]struct{}
+	holdCh chan struct{}
+	parked map[string]string
+
+	maintenanceSince time.Time
+	maintenanceStore MaintenanceStore
+
+	// Named libraries besides the default output directory.
+	libMu     sync.RWMutex
+	libraries map[string]*libraryEntry
+
+	disk         *DiskMonitor
+	diskInterval time.Duration
+
+	// Running downloads without progress or output for this long are
+	// restarted, then failed as stalled; 0 disables the watchdog.
+	stallTimeout atomic.Int64
+
+	// Wall-clock limit per job (0 = unlimited) and per metadata probe attempt.
+	maxJobDuration  atomic.Int64
+	metadataTimeout atomic.Int64
+
+	// Probe info JSON younger than this is loaded by the download instead of
+	// extracting again; 0 disables reuse.
+	infoJSONTTL atomic.Int64
+	infoStats   infoJSONCounters
+
+	// Recent Probe results by token, reusable by the enqueue that follows.
+	probes probeCache
+
+	// Per-host circuit breakers; a threshold of 0 disables them.
+	breakerMu        sync.Mutex
+	breakers         map[string]*hostBreaker
+	breakerThreshold int
+	breakerCooldown  time.Duration
+
+	// Worker pool; each worker has its own quit channel so the pool can shrink
+	// without interrupting jobs in progress.
+	poolMu        sync.Mutex
+	workerQuit    []chan struct{}
+	nextWorkerIdx int
+
+	// events fans lifecycle events out to persistence, logging and other subscribers.
+	events     *EventBus
+	eventsOnce sync.Once
+	storeStop  func()
 
 	artifactMu sync.Mutex
 	artifacts  map[string]map[string]struct{}
@@ -118,6 +205,10 @@ type activeDownload struct {
 	id     string
 	dbID   int64
 	cancel context.CancelFunc
+
+	// Watchdog bookkeeping, guarded by activeMu.
+	lastActivity time.Time
+	lastBytes    float64
 }
 
 // Store interface defines methods for persisting download state
@@ -128,11 +219,19 @@ type Store interface {
 	UpdateArtifacts(ctx context.Context, id int64, paths []string) error
 }
 
+// ErrorClassStore is implemented by stores that keep a failed row's error
+// class next to its message.
+type ErrorClassStore interface {
+	UpdateErrorClass(ctx context.Context, id int64, class string) error
+}
+
 // PendingDownloadStore defines methods needed to process pending downloads from the database
 type PendingDownloadStore interface {
 	TryClaimPending(ctx context.Context, id int64) (bool, error)
 	UpdateStatus(ctx context.Context, id int64, status string, errMsg string) error
 	UpdateMeta(ctx context.Context, id int64, title string, duration int64, thumbnail string) error
+	UpdateDetails(ctx context.Context, id int64, uploader, description string) error
+	UpdateLibraryRoot(ctx context.Context, id int64, root string) error
 }
 
 func NewManager(outputDir string, workers, queueCap int) *Manager {
@@ -151,27 +250,44 @@ func NewManager(outputDir string, workers, queueCap int) *Manager {
 		activeByID:          make(map[string]*activeDownload, queueCap),
 		activeByDB:          make(map[int64]*activeDownload, queueCap),
 		stopIntents:         make(map[string]State, queueCap),
+		stopReasons:         make(map[string]string),
 		artifacts:           make(map[string]map[string]struct{}, queueCap),
 		artifactPersistByID: make(map[string]*sync.Mutex, queueCap),
 	}
 	m.runCtx, m.runCancel = context.WithCancel(context.Background())
+	m.events = NewEventBus()
+	m.events.Handle("logging", SubscribeOptions{Policy: BlockWhenFull, Buffer: 1024}, logEvent)
 
-	// Set up downloader callbacks
-	m.downloader.SetProgressCallback(m.updateProgress)
-	m.downloader.SetFilenameCallback(m.setFilename)
-	m.downloader.SetArtifactCallback(m.recordArtifacts)
+	m.wireDownloader(m.downloader)
 
 	// Start workers
-	for i := 0; i < workers; i++ {
-		m.wg.Add(1)
-		go m.worker(i)
-	}
+	m.poolMu.Lock()
+	m.spawnWorkersLocked(workers)
+	m.poolMu.Unlock()
 	return m
 }
 
-// SetStore configures the store for persisting progress and state updates
+// SetStore configures the store for persisting progress and state updates.
+// Persistence runs as a synchronous event subscriber.
 func (m *Manager) SetStore(store Store) {
+	if m.storeStop != nil {
+		m.storeStop()
+		m.storeStop = nil
+	}
 	m.store = store
+	if store != nil {
+		m.storeStop = m.Events().Handle("store", SubscribeOptions{Policy: DeliverSync}, m.persistEvent)
+	}
+}
+
+// Events returns the bus the manager publishes lifecycle events on.
+func (m *Manager) Events() *EventBus {
+	m.eventsOnce.Do(func() {
+		if m.events == nil {
+			m.events = NewEventBus()
+		}
+	})
+	return m.events
 }
 
 // SetRegistry allows replacing the registry (useful for testing)
@@ -182,10 +298,15 @@ func (m *Manager) SetRegistry(registry *ItemRegistry) {
 // SetDownloader allows replacing the downloader (useful for testing)
 func (m *Manager) SetDownloader(downloader *Downloader) {
 	m.downloader = downloader
-	// Re-setup callbacks
-	m.downloader.SetProgressCallback(m.updateProgress)
-	m.downloader.SetFilenameCallback(m.setFilename)
-	m.downloader.SetArtifactCallback(m.recordArtifacts)
+	m.wireDownloader(downloader)
+}
+
+// wireDownloader routes a downloader's progress, filename and artifact reports to the manager.
+func (m *Manager) wireDownloader(d *Downloader) {
+	d.SetProgressCallback(m.updateProgress)
+	d.SetFilenameCallback(m.setFilename)
+	d.SetArtifactCallback(m.recordArtifacts)
+	d.SetActivityCallback(m.noteActivity)
 }
 
 // StopAccepting stops queueing new jobs; Enqueue will return an error afterwards.
@@ -209,13 +330,58 @@ func (m *Manager) Shutdown() {
 	})
 	// Wait for workers to finish current job
 	m.wg.Wait()
+	// Flush asynchronous subscribers such as logging
+	m.Events().Close()
+}
+
+// EnqueueOptions carries per-job hints known before the job is queued.
+type EnqueueOptions struct {
+	// EstimatedBytes is the expected output size from the metadata probe; 0 if unknown.
+	EstimatedBytes int64
+	// Library selects the destination by name; empty uses the default library.
+	Library string
+	// MaxDuration caps how long the job may run; 0 uses the library's or the manager's limit.
+	MaxDuration time.Duration
+	// InfoJSON is the metadata probe's yt-dlp info JSON, reused by the download
+	// while fresh; ProbeTime is what that probe cost.
+	InfoJSON  string
+	ProbeTime time.Duration
+	// FormatID pins a yt-dlp format (-f); empty uses yt-dlp's default selection.
+	FormatID string
+	// ProbeToken refers to a cached Probe result; its info JSON is used when
+	// InfoJSON is empty.
+	ProbeToken string
+	// Sections downloads only these parts of the video; empty downloads all of it.
+	Sections []Section
+	// SplitChapters also writes each chapter to its own file.
+	SplitChapters bool
+
+	// needsProbe marks a job whose metadata probe was skipped; the worker
+	// probes it once the job is admitted.
+	needsProbe bool
 }
 
 // Enqueue adds a new URL to the queue and returns the assigned ID.
 func (m *Manager) Enqueue(url string) (string, error) {
+	return m.EnqueueWithOptions(url, EnqueueOptions{})
+}
+
+// EnqueueWithOptions is like Enqueue but records per-job hints on the item
+// before it becomes visible to workers.
+func (m *Manager) EnqueueWithOptions(url string, opts EnqueueOptions) (string, error) {
 	if m.closing.Load() {
 		return "", ErrShuttingDown
 	}
+	lib, ok := m.Library(opts.Library)
+	if !ok {
+		return "", ErrUnknownLibrary
+	}
+
+	if opts.InfoJSON == "" {
+		if info, ok := m.probedInfo(opts.ProbeToken, url); ok {
+			opts.InfoJSON, opts.ProbeTime = info.InfoJSON, info.ProbeTime
+		}
+	}
 
 	id := genID()
 
@@ -224,12 +390,25 @@ func (m *Manager) Enqueue(url string) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("failed to create item: %w", err)
 	}
+	_ = m.registry.Update(id, func(it *Item) {
+		it.EstimatedBytes = opts.EstimatedBytes
+		it.Library = lib.Name
+		it.maxDuration = opts.MaxDuration
+		it.formatID = opts.FormatID
+		it.sections = opts.Sections
+		it.Sections = SectionSpecs(opts.Sections)
+		it.splitChapters = opts.SplitChapters
+		it.needsProbe = opts.needsProbe
+	})
+	m.saveProbedInfo(id, lib.Name, opts)
 
 	if m.enqueueJob(job{id: id, url: url, token: m.bumpQueueToken(id)}) {
+		m.publish(EventQueued, id, nil)
 		return id, nil
 	}
 	// queue full, remove the entry we just added
 	m.registry.Delete(id)
+	m.discardProbedInfo(id, lib.Name)
 	return "", ErrQueueFull
 }
 
@@ -248,20 +427,42 @@ func (m *Manager) SetMeta(id string, title string, duration int64, thumb string)
 	if err := m.registry.SetMeta(id, title, duration, thumb); err != nil {
 		// Log but don't fail - this is a best-effort operation
 		slog.Debug("failed to set metadata for item", "id", id, "error", err)
+		return
 	}
+	m.publish(EventMetadata, id, nil)
 }
 
 // Snapshot returns a copy of the current download items. If id is non-empty, returns at most that item.
 func (m *Manager) Snapshot(id string) []*Item {
-	return m.registry.Snapshot(id)
+	items := m.registry.Snapshot(id)
+	m.fillStallTimes(items)
+	return items
 }
 
-func (m *Manager) worker(idx int) {
+// worker processes jobs until the queue closes or quit is signalled.
+// A nil quit channel never retires the worker.
+func (m *Manager) worker(idx int, quit <-chan struct{}) {
 	defer m.wg.Done()
-	for j := range m.jobs {
+	for {
+		j, ok := m.nextJob(quit)
+		if !ok {
+			return
+		}
+		if !m.waitForDispatch() {
+			continue
+		}
+		if !m.fitsOnDisk(j.id) {
+			m.parkQueuedJob(j, ReasonDiskLow)
+			continue
+		}
+		if !m.admitJob(j) {
+			continue
+		}
 		if !m.claimQueuedJob(j.id, j.token) {
+			m.releaseProbe(j.url, j.id)
 			continue
 		}
+		m.unpark(j.id)
 
 		m.updateState(j.id, StateDownloading, "")
 		item := m.registry.Get(j.id)
@@ -270,51 +471,78 @@ func (m *Manager) worker(idx int) {
 		if ctx == nil {
 			ctx = context.Background()
 		}
-		jobCtx, cancel := context.WithCancel(ctx)
-
-		var dbID int64
+		var (
+			dbID    int64
+			library string
+			limit   = m.jobDuration(item)
+		)
 		if item != nil {
 			dbID = item.DBID
+			library = item.Library
+		}
+		var (
+			jobCtx context.Context
+			cancel context.CancelFunc
+		)
+		if limit > 0 {
+			jobCtx, cancel = context.WithTimeoutCause(ctx, limit, ErrTimeout)
+		} else {
+			jobCtx, cancel = context.WithCancel(ctx)
 		}
 		m.registerActive(j.id, dbID, cancel)
 		if current := m.registry.Get(j.id); current != nil && current.DBID > 0 {
 			m.bindActiveDBID(j.id, current.DBID)
 		}
+		if item != nil && item.needsProbe {
+			m.probeAdmitted(jobCtx, item)
+			item = m.registry.Get(j.id)
+		}
 
 		downloadFn := m.workerDownload
 		if downloadFn == nil {
-			downloadFn = m.downloader.Download
+			downloadFn = m.probedDownload(item, m.downloaderFor(library))
 		}
 
-		if err := downloadFn(jobCtx, j.id, j.url); err != nil {
+		if err := m.downloadWatched(jobCtx, j.id, j.url, downloadFn); err != nil {
 			cancel()
 			m.unregisterActive(j.id)
 			if desired, ok := m.consumeStopIntent(j.id); ok {
-				m.updateState(j.id, desired, "")
+				m.releaseProbe(j.url, j.id)
+				m.updateState(j.id, desired, m.consumeStopReason(j.id))
 				if desired == StateCanceled {
 					m.cleanupCanceledArtifacts(j.id)
 				}
-				m.persistTerminalSnapshot(j.id)
+				m.resumeLiftedHolds()
 				continue
 			}
+			if errors.Is(context.Cause(jobCtx), ErrTimeout) {
+				// The job's own time limit says nothing about the site, so the
+				// breaker is not fed; a probe that timed out hands its slot on.
+				m.releaseProbe(j.url, j.id)
+				m.updateFailure(j.id, fmt.Errorf("%w: job exceeded %s", ErrTimeout, limit))
+				m.resumeLiftedHolds()
+				continue
+			}
+			m.recordJobOutcome(j.url, j.id, err)
 			m.updateFailure(j.id, err)
-			m.persistTerminalSnapshot(j.id)
 		} else {
 			cancel()
 			m.unregisterActive(j.id)
 			_, _ = m.consumeStopIntent(j.id)
+			_ = m.consumeStopReason(j.id)
+			m.recordJobOutcome(j.url, j.id, nil)
 			m.updateProgress(j.id, 100)
 			m.updateState(j.id, StateCompleted, "")
-			m.persistTerminalSnapshot(j.id)
-			m.persistAndClearArtifacts(j.id)
+			m.publishCompleted(j.id)
 		}
+		m.resumeLiftedHolds()
 	}
 }
 
-// CheckYTDLP ensures yt-dlp is in PATH.
+// CheckYTDLP ensures the configured yt-dlp binary (see YTDLPPath) is runnable.
 func CheckYTDLP() error {
 	// Ensure yt-dlp exists
-	p, err := exec.LookPath("yt-dlp")
+	p, err := exec.LookPath(YTDLPPath())
 	if err != nil {
 		return err
 	}
@@ -342,21 +570,9 @@ func (m *Manager) updateProgress(id string, p float64) {
 		return
 	}
 
-	// Log when integer percentage advances to reduce noise
+	// Publish only when the integer percentage advances to reduce noise
 	if int(new) != int(prev) {
-		item := m.registry.Get(id)
-		if item != nil {
-			dbIDStr := ""
-			if item.DBID > 0 {
-				dbIDStr = fmt.Sprintf("%d", item.DBID)
-			}
-			logging.LogDownloadProgress(id, dbIDStr, new, item.URL)
-
-			// Update store if configured
-			if item.DBID > 0 && m.store != nil {
-				m.persistProgressToStore(item.DBID, new)
-			}
-		}
+		m.publish(EventProgress, id, nil)
 	}
 }
 
@@ -366,13 +582,9 @@ func (m *Manager) updateState(id string, st State, errMsg string) {
 		return
 	}
 
-	item := m.registry.Get(id)
-	if item != nil {
-		logging.LogDownloadStateChange(id, item.URL, string(st))
-
-		if item.DBID > 0 && m.store != nil {
-			m.persistStatusToStore(item.DBID, stateToStatus(st), errMsg)
-		}
+	m.publish(EventStateChanged, id, nil)
+	if st == StateDownloading {
+		m.publish(EventStarted, id, nil)
 	}
 }
 
@@ -416,7 +628,7 @@ func (m *Manager) PauseByDBID(dbID int64) bool {
 			return false
 		case StateDownloading:
 			return m.requestStopByDBID(dbID, StatePaused)
-		case StateFailed, StateCanceled:
+		case StateFailed, StateCanceled, StateDeferred:
 			m.updateState(item.ID, StatePaused, "")
 			return true
 		case StatePaused:
@@ -471,7 +683,7 @@ func (m *Manager) CancelByDBID(dbID int64) bool {
 				return true
 			}
 			return false
-		case StatePaused, StateFailed:
+		case StatePaused, StateFailed, StateDeferred:
 			m.updateState(item.ID, StateCanceled, "")
 			m.cleanupCanceledArtifacts(item.ID)
 			return true
@@ -497,19 +709,25 @@ func (m *Manager) ResumeByDBID(dbID int64) (bool, error) {
 	if item.State == StateDownloading {
 		return true, nil
 	}
-	if item.State == StateQueued {
+	if item.State == StateQueued || item.State == StateDeferred {
 		return true, nil
 	}
 	if item.State == StateCompleted {
 		return false, nil
 	}
+	return m.resumeItem(item)
+}
+
+// resumeItem requeues a paused/canceled/failed item, rolling its state back
+// when the queue has no room.
+func (m *Manager) resumeItem(item *Item) (bool, error) {
 	if m.closing.Load() {
 		return false, ErrShuttingDown
 	}
 	prevState := item.State
 	prevProgress := item.Progress
 	prevFilename := item.Filename
-	prevError := item.Error
+	prevError, prevClass := item.Error, item.ErrorClass
 	prevQueueToken := item.queueToken
 	var token uint64
 	if err := m.registry.Update(item.ID, func(it *Item) {
@@ -519,12 +737,14 @@ func (m *Manager) ResumeByDBID(dbID int64) (bool, error) {
 		}
 		it.State = StateQueued
 		it.Error = ""
+		it.ErrorClass = ""
 		it.queueToken++
 		token = it.queueToken
 	}); err != nil {
 		return false, err
 	}
 	if m.enqueueJob(job{id: item.ID, url: item.URL, token: token}) {
+		m.publish(EventQueued, item.ID, func(e *Event) { e.PrevState = prevState })
 		return true, nil
 	}
 	_ = m.registry.Update(item.ID, func(it *Item) {
@@ -534,7 +754,7 @@ func (m *Manager) ResumeByDBID(dbID int64) (bool, error) {
 		it.State = prevState
 		it.Progress = prevProgress
 		it.Filename = prevFilename
-		it.Error = prevError
+		it.Error, it.ErrorClass = prevError, prevClass
 		it.queueToken = prevQueueToken
 	})
 	return false, ErrQueueFull
@@ -556,7 +776,7 @@ func (m *Manager) IsManagedByDBID(dbID int64) bool {
 	if item == nil {
 		return false
 	}
-	return item.State == StateQueued || item.State == StateDownloading
+	return item.State == StateQueued || item.State == StateDownloading || item.State == StateDeferred
 }
 
 func (m *Manager) bumpQueueToken(id string) uint64 {
@@ -632,7 +852,7 @@ func (m *Manager) dropQueuedJobsByID(id string) int {
 func (m *Manager) registerActive(id string, dbID int64, cancel context.CancelFunc) {
 	m.activeMu.Lock()
 	defer m.activeMu.Unlock()
-	entry := &activeDownload{id: id, dbID: dbID, cancel: cancel}
+	entry := &activeDownload{id: id, dbID: dbID, cancel: cancel, lastActivity: time.Now(), lastBytes: -1}
 	m.activeByID[id] = entry
 	if dbID > 0 {
 		m.activeByDB[dbID] = entry
@@ -671,6 +891,7 @@ func (m *Manager) requestStopByDBID(dbID int64, desired State) bool {
 	entry, ok := m.activeByDB[dbID]
 	if ok {
 		m.stopIntents[entry.id] = desired
+		delete(m.stopReasons, entry.id)
 	}
 	m.activeMu.Unlock()
 	if !ok || entry.cancel == nil {
@@ -693,12 +914,12 @@ func (m *Manager) consumeStopIntent(id string) (State, bool) {
 
 func (m *Manager) cleanupCanceledArtifacts(id string) {
 	item := m.registry.Get(id)
-	filename := ""
+	filename, library := "", ""
 	if item != nil {
-		filename = item.Filename
+		filename, library = item.Filename, item.Library
 	}
 	tracked := m.trackedArtifacts(id)
-	if err := m.downloader.CleanupArtifacts(id, filename, tracked); err != nil {
+	if err := m.downloaderFor(library).CleanupArtifacts(id, filename, tracked); err != nil {
 		slog.Debug("cleanup canceled artifacts failed", "id", id, "error", err)
 	}
 	m.clearArtifacts(id)
@@ -708,7 +929,10 @@ func (m *Manager) updateFailure(id string, err error) {
 	msg := err.Error()
 	// reduce noise from long command errors, respecting UTF-8 boundaries
 	msg = truncateUTF8(msg, 512)
+	class := errorClass(err)
+	_ = m.registry.Update(id, func(it *Item) { it.ErrorClass = class })
 	m.updateState(id, StateFailed, msg)
+	m.publish(EventFailed, id, nil)
 }
 
 // setFilename updates the filename for an item and calls the hook
@@ -717,20 +941,11 @@ func (m *Manager) setFilename(id, filename string) {
 		// Item might have been removed
 		return
 	}
-	m.recordArtifacts(id, []string{filepath.Join(m.outDir, filename)})
-
-	item := m.registry.Get(id)
-	if item != nil {
-		dbIDStr := ""
-		if item.DBID > 0 {
-			dbIDStr = fmt.Sprintf("%d", item.DBID)
-		}
-		logging.LogDownloadComplete(id, dbIDStr, filename)
-
-		if item.DBID > 0 && m.store != nil {
-			m.persistFilenameToStore(item.DBID, filename)
-		}
+	root := m.outDir
+	if item := m.registry.Get(id); item != nil {
+		root = m.libraryRoot(item.Library)
 	}
+	m.recordArtifacts(id, []string{filepath.Join(root, filename)})
 }
 
 func (m *Manager) recordArtifacts(id string, paths []string) {
@@ -760,13 +975,8 @@ func (m *Manager) recordArtifacts(id string, paths []string) {
 	if len(merged) == 0 {
 		return
 	}
-	sort.Strings(merged)
-
-	item := m.registry.Get(id)
-	if item == nil || item.DBID <= 0 || m.store == nil {
-		return
-	}
 
+	// Serialize per job so subscribers never see an older set after a newer one.
 	persistLock := m.artifactPersistLock(id)
 	persistLock.Lock()
 	defer persistLock.Unlock()
@@ -775,7 +985,7 @@ func (m *Manager) recordArtifacts(id string, paths []string) {
 	if len(pathsCopy) == 0 {
 		return
 	}
-	m.persistArtifactsToStore(item.DBID, pathsCopy)
+	m.publish(EventArtifactAdded, id, func(e *Event) { e.Artifacts = pathsCopy })
 }
 
 func (m *Manager) artifactPersistLock(id string) *sync.Mutex {
@@ -820,24 +1030,18 @@ func (m *Manager) clearArtifacts(id string) {
 	m.artifactPersistMu.Unlock()
 }
 
-func (m *Manager) persistAndClearArtifacts(id string) {
+// publishCompleted announces a finished job with its final artifact set and
+// stops tracking its artifacts.
+func (m *Manager) publishCompleted(id string) {
 	if id == "" {
 		return
 	}
 
 	persistLock := m.artifactPersistLock(id)
 	persistLock.Lock()
-	defer persistLock.Unlock()
-
-	if m.store != nil {
-		item := m.registry.Get(id)
-		if item != nil && item.DBID > 0 {
-			paths := m.trackedArtifacts(id)
-			if len(paths) > 0 {
-				m.persistArtifactsToStore(item.DBID, paths)
-			}
-		}
-	}
+	paths := m.trackedArtifacts(id)
+	m.publish(EventCompleted, id, func(e *Event) { e.Artifacts = paths })
+	persistLock.Unlock()
 
 	m.clearArtifacts(id)
 }
@@ -852,17 +1056,24 @@ func shouldRetryMetadataFetch(err error) bool {
 	if errors.Is(err, context.DeadlineExceeded) {
 		return true
 	}
-	if errors.Is(err, ErrNoMediaInfo) {
+	if errors.Is(err, ErrNoMediaInfo) || errors.Is(err, ErrTimeout) {
 		return true
 	}
 	var exitErr *exec.ExitError
 	return errors.As(err, &exitErr)
 }
 
-func fetchMediaInfoWithRetry(ctx context.Context, url string, dbID int64) (MediaInfo, error) {
+// fetchMediaInfoWithRetry probes url, retrying transient failures. Each
+// attempt is bounded by the metadata timeout; running out of time on the
+// last attempt is reported as ErrTimeout.
+func (m *Manager) fetchMediaInfoWithRetry(ctx context.Context, url string, dbID int64) (MediaInfo, error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
+	timeout := m.MetadataTimeout()
+	if m.circuitOpen(url) {
+		return MediaInfo{}, ErrCircuitOpen
+	}
 
 	var lastErr error
 	for attempt := 1; attempt <= pendingMetadataMaxAttempts; attempt++ {
@@ -870,12 +1081,19 @@ func fetchMediaInfoWithRetry(ctx context.Context, url string, dbID int64) (Media
 			return MediaInfo{}, err
 		}
 
-		attemptCtx, cancel := context.WithTimeout(ctx, pendingMetadataTimeout)
+		attemptCtx, cancel := context.WithTimeoutCause(ctx, timeout, ErrTimeout)
+		started := time.Now()
 		info, err := fetchMediaInfo(attemptCtx, url)
+		timedOut := errors.Is(context.Cause(attemptCtx), ErrTimeout)
 		cancel()
 		if err == nil {
+			info.ProbeTime = time.Since(started)
+			m.recordJobOutcome(url, "", nil)
 			return info, nil
 		}
+		if timedOut {
+			err = fmt.Errorf("%w: metadata probe exceeded %s", ErrTimeout, timeout)
+		}
 
 		lastErr = err
 		if !shouldRetryMetadataFetch(err) || attempt == pendingMetadataMaxAttempts {
@@ -901,11 +1119,20 @@ func fetchMediaInfoWithRetry(ctx context.Context, url string, dbID int64) (Media
 	if lastErr == nil {
 		lastErr = ErrNoMediaInfo
 	}
+	m.recordJobOutcome(url, "", lastErr)
 	return MediaInfo{}, lastErr
 }
 
 // ProcessPendingDownload processes a single pending download from the database.
-func (m *Manager) ProcessPendingDownload(ctx context.Context, dbID int64, url string, store PendingDownloadStore) error {
+// library names the row's destination; empty selects the default library.
+func (m *Manager) ProcessPendingDownload(ctx context.Context, dbID int64, url, library string, store PendingDownloadStore) error {
+	return m.ProcessPendingDownloadWithOptions(ctx, dbID, url, EnqueueOptions{Library: library}, store)
+}
+
+// ProcessPendingDownloadWithOptions is like ProcessPendingDownload but carries
+// the row's per-request job options. EstimatedBytes comes from the probe.
+func (m *Manager) ProcessPendingDownloadWithOptions(ctx context.Context, dbID int64, url string, opts EnqueueOptions, store PendingDownloadStore) error {
+	library := opts.Library
 	if ctx == nil {
 		ctx = context.Background()
 	}
@@ -922,8 +1149,19 @@ func (m *Manager) ProcessPendingDownload(ctx context.Context, dbID int64, url st
 		return nil
 	}
 
-	// Fetch media info with bounded retries for transient extractor/network failures.
-	mediaInfo, err := fetchMediaInfoWithRetry(ctx, url, dbID)
+	// A fresh /api/probe result for the row's URL replaces a second probe.
+	// Otherwise fetch media info with bounded retries for transient
+	// extractor/network failures. While the host's breaker is open the probe
+	// is skipped rather than failing the row; the worker defers the job and
+	// probes it once the job is admitted.
+	mediaInfo, probed := m.probedInfo(opts.ProbeToken, url)
+	if !probed {
+		mediaInfo, err = m.fetchMediaInfoWithRetry(ctx, url, dbID)
+	}
+	if errors.Is(err, ErrCircuitOpen) {
+		mediaInfo, err = MediaInfo{}, nil
+		opts.needsProbe = true
+	}
 	if err != nil {
 		logging.LogMetadataFetch(url, dbID, err)
 		// Update database with error
@@ -947,9 +1185,32 @@ func (m *Manager) ProcessPendingDownload(ctx context.Context, dbID int64, url st
 	} else {
 		logging.LogMetadataFetch(url, dbID, nil)
 	}
+	if err := store.UpdateDetails(ctx, dbID, mediaInfo.Uploader, mediaInfo.Description); err != nil {
+		slog.Error("failed to update details in ProcessPendingDownload",
+			"event", "store_update_error",
+			"operation", "update_details",
+			"db_id", dbID,
+			"error", err)
+	}
+
+	// Record where the file will land; the library root may have moved since the row was created
+	if lib, ok := m.Library(library); ok {
+		if err := store.UpdateLibraryRoot(ctx, dbID, lib.Root); err != nil {
+			slog.Error("failed to update library root in ProcessPendingDownload",
+				"event", "store_update_error",
+				"operation", "update_library_root",
+				"db_id", dbID,
+				"error", err)
+		}
+	}
 
 	// Enqueue the download with the manager
-	id, err := m.Enqueue(url)
+	// The probe's size is the whole video's, which would overstate a clip.
+	if len(opts.Sections) == 0 {
+		opts.EstimatedBytes = mediaInfo.FilesizeBytes
+	}
+	opts.InfoJSON, opts.ProbeTime = mediaInfo.InfoJSON, mediaInfo.ProbeTime
+	id, err := m.EnqueueWithOptions(url, opts)
 	if err != nil {
 		slog.Error("failed to enqueue download in ProcessPendingDownload",
 			"event", "enqueue_error",
@@ -1035,6 +1296,16 @@ func (m *Manager) persistStatusToStore(dbID int64, status, errMsg string) {
 	}, "status", status)
 }
 
+func (m *Manager) persistErrorClassToStore(dbID int64, class string) {
+	st, ok := m.store.(ErrorClassStore)
+	if !ok {
+		return
+	}
+	m.persistWithRetry("update_error_class", dbID, func(ctx context.Context) error {
+		return st.UpdateErrorClass(ctx, dbID, class)
+	}, "error_class", class)
+}
+
 func (m *Manager) persistFilenameToStore(dbID int64, filename string) {
 	m.persistWithRetry("update_filename", dbID, func(ctx context.Context) error {
 		return m.store.UpdateFilename(ctx, dbID, filename)
@@ -1047,25 +1318,8 @@ func (m *Manager) persistArtifactsToStore(dbID int64, paths []string) {
 	}, "artifact_count", len(paths))
 }
 
-func (m *Manager) persistTerminalSnapshot(id string) {
-	if id == "" {
-		return
-	}
-	item := m.registry.Get(id)
-	if item == nil || item.DBID <= 0 || m.store == nil {
-		return
-	}
-	status := stateToStatus(item.State)
-	m.persistStatusToStore(item.DBID, status, item.Error)
-	if item.State == StateCompleted {
-		m.persistProgressToStore(item.DBID, 100)
-	}
-	if item.Filename != "" {
-		m.persistFilenameToStore(item.DBID, item.Filename)
-	}
-}
-
-func stateToStatus(st State) string {
+// StoreStatus maps an item state to the status persisted for its row.
+func StoreStatus(st State) string {
 	switch st {
 	case StateQueued:
 		return "pending"
@@ -1079,6 +1333,8 @@ func stateToStatus(st State) string {
 		return "paused"
 	case StateCanceled:
 		return "canceled"
+	case StateDeferred:
+		return "deferred"
 	default:
 		return "pending"
 	}
@@ -0,0 +1,89 @@
+package download
+
+import (
+	"fmt"
+	"videofetch/internal/logging"
+)
+
+// publish snapshots the item and sends an event of type t. mutate may attach
+// event-specific fields. Items removed from the registry publish nothing.
+func (m *Manager) publish(t EventType, id string, mutate func(e *Event)) {
+	item := m.registry.Get(id)
+	if item == nil {
+		return
+	}
+	e := Event{Type: t, Item: *item}
+	if mutate != nil {
+		mutate(&e)
+	}
+	m.Events().Publish(e)
+}
+
+// persistEvent mirrors events into the Store. It runs synchronously so rows
+// are written in the order state changes happen.
+func (m *Manager) persistEvent(e Event) {
+	dbID := e.Item.DBID
+	if dbID <= 0 || m.store == nil {
+		return
+	}
+	switch e.Type {
+	case EventQueued:
+		// Mirror Store.TryMarkResumed for paused rows: a requeued row must not
+		// look pending to the DB worker. Canceled and failed rows are reset by
+		// the caller, which also clears their progress and files.
+		if e.PrevState == StatePaused || e.PrevState == StateDeferred {
+			m.persistStatusToStore(dbID, "downloading", "")
+		}
+	case EventProgress:
+		m.persistProgressToStore(dbID, e.Item.Progress)
+	case EventStateChanged:
+		m.persistStatusToStore(dbID, StoreStatus(e.Item.State), e.Item.Error)
+		if e.Item.State == StateFailed && e.Item.ErrorClass != "" {
+			m.persistErrorClassToStore(dbID, e.Item.ErrorClass)
+		}
+		if !isTerminalSnapshotState(e.Item.State) {
+			return
+		}
+		if e.Item.State == StateCompleted {
+			m.persistProgressToStore(dbID, 100)
+		}
+		if e.Item.Filename != "" {
+			m.persistFilenameToStore(dbID, e.Item.Filename)
+		}
+	case EventArtifactAdded:
+		if e.Item.Filename != "" {
+			m.persistFilenameToStore(dbID, e.Item.Filename)
+		}
+		m.persistArtifactsToStore(dbID, e.Artifacts)
+	case EventCompleted:
+		if len(e.Artifacts) > 0 {
+			m.persistArtifactsToStore(dbID, e.Artifacts)
+		}
+	}
+}
+
+// isTerminalSnapshotState reports states after which the worker is done with
+// the item, so its final progress and filename are written too.
+func isTerminalSnapshotState(st State) bool {
+	switch st {
+	case StateCompleted, StateFailed, StatePaused, StateCanceled:
+		return true
+	}
+	return false
+}
+
+// logEvent writes the structured download log lines.
+func logEvent(e Event) {
+	dbIDStr := ""
+	if e.Item.DBID > 0 {
+		dbIDStr = fmt.Sprintf("%d", e.Item.DBID)
+	}
+	switch e.Type {
+	case EventProgress:
+		logging.LogDownloadProgress(e.Item.ID, dbIDStr, e.Item.Progress, e.Item.URL)
+	case EventStateChanged:
+		logging.LogDownloadStateChange(e.Item.ID, e.Item.URL, string(e.Item.State))
+	case EventCompleted:
+		logging.LogDownloadComplete(e.Item.ID, dbIDStr, e.Item.Filename)
+	}
+}
@@ -62,7 +62,7 @@ func TestResumeByDBID_DoesNotExecuteStaleQueuedJob(t *testing.T) {
 
 	close(m.jobs)
 	m.wg.Add(1)
-	go m.worker(0)
+	go m.worker(0, nil)
 	m.wg.Wait()
 
 	if got := calls.Load(); got != 1 {
@@ -351,7 +351,7 @@ func TestWorkerSuccess_ClearsLateStopIntent(t *testing.T) {
 	m.jobs <- job{id: id, url: mediaURL, token: 1}
 	close(m.jobs)
 	m.wg.Add(1)
-	go m.worker(0)
+	go m.worker(0, nil)
 
 	select {
 	case <-started:
@@ -73,8 +73,8 @@ func TestUpdateState_PersistsDuringClosing(t *testing.T) {
 	st := &recordingStore{}
 	m := &Manager{
 		registry: NewItemRegistry(4),
-		store:    st,
 	}
+	m.SetStore(st)
 
 	if _, err := m.registry.Create("id-1", "https://example.com/video"); err != nil {
 		t.Fatalf("registry create failed: %v", err)
@@ -147,12 +147,20 @@ func (s *claimOnlyStore) UpdateMeta(ctx context.Context, id int64, title string,
 	return nil
 }
 
+func (s *claimOnlyStore) UpdateDetails(ctx context.Context, id int64, uploader, description string) error {
+	return nil
+}
+
+func (s *claimOnlyStore) UpdateLibraryRoot(ctx context.Context, id int64, root string) error {
+	return nil
+}
+
 func TestProcessPendingDownload_ClaimConflictSkipsWork(t *testing.T) {
 	m := NewManager(t.TempDir(), 1, 4)
 	defer m.Shutdown()
 
 	st := &claimOnlyStore{claimResult: false}
-	err := m.ProcessPendingDownload(context.Background(), 99, "https://example.com/video", st)
+	err := m.ProcessPendingDownload(context.Background(), 99, "https://example.com/video", "", st)
 	if err != nil {
 		t.Fatalf("expected nil error on claim conflict, got %v", err)
 	}
@@ -180,13 +188,13 @@ func TestProcessPendingDownload_UsesBoundedMetadataTimeout(t *testing.T) {
 			t.Fatalf("expected metadata context deadline to be set")
 		}
 		remaining := time.Until(deadline)
-		if remaining <= 0 || remaining > pendingMetadataTimeout+time.Second {
+		if remaining <= 0 || remaining > DefaultMetadataTimeout+time.Second {
 			t.Fatalf("unexpected metadata deadline window: %s", remaining)
 		}
 		return MediaInfo{}, context.DeadlineExceeded
 	}
 
-	err := m.ProcessPendingDownload(context.Background(), 7, "https://example.com/video", st)
+	err := m.ProcessPendingDownload(context.Background(), 7, "https://example.com/video", "", st)
 	if err == nil {
 		t.Fatalf("expected metadata failure error")
 	}
@@ -219,7 +227,7 @@ func TestProcessPendingDownload_MetadataRetryEventuallySucceeds(t *testing.T) {
 		}, nil
 	}
 
-	err := m.ProcessPendingDownload(context.Background(), 8, "https://example.com/video", st)
+	err := m.ProcessPendingDownload(context.Background(), 8, "https://example.com/video", "", st)
 	if err != nil {
 		t.Fatalf("expected retry path to succeed, got %v", err)
 	}
@@ -248,7 +256,7 @@ func TestProcessPendingDownload_MetadataNonRetryableFailsFast(t *testing.T) {
 		return MediaInfo{}, fmt.Errorf("invalid URL: missing host")
 	}
 
-	err := m.ProcessPendingDownload(context.Background(), 9, "https://example.com/video", st)
+	err := m.ProcessPendingDownload(context.Background(), 9, "https://example.com/video", "", st)
 	if err == nil {
 		t.Fatalf("expected metadata failure")
 	}
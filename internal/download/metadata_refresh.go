@@ -0,0 +1,146 @@
+package download
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"videofetch/internal/logging"
+)
+
+// ffprobeTimeout bounds reading tags from a local file.
+const ffprobeTimeout = 30 * time.Second
+
+// probeFile is swapped out in tests.
+var probeFile = ProbeFile
+
+// MetadataStore is the subset of store operations a metadata refresh writes to.
+type MetadataStore interface {
+	UpdateMeta(ctx context.Context, id int64, title string, duration int64, thumbnail string) error
+	UpdateDetails(ctx context.Context, id int64, uploader, description string) error
+}
+
+// RefreshMetadata re-runs the metadata probe for a persisted row, with the
+// same retry policy as pending downloads, and stores the result. A row that
+// is still managed in memory also gets its item updated.
+func (m *Manager) RefreshMetadata(ctx context.Context, dbID int64, url string, st MetadataStore) (MediaInfo, error) {
+	info, err := m.fetchMediaInfoWithRetry(ctx, url, dbID)
+	if err != nil {
+		logging.LogMetadataFetch(url, dbID, err)
+		return MediaInfo{}, err
+	}
+	if err := m.applyMetadata(ctx, dbID, info, st); err != nil {
+		return MediaInfo{}, err
+	}
+	logging.LogMetadataFetch(url, dbID, nil)
+	return info, nil
+}
+
+// RefreshMetadataFromFile re-reads the tags embedded in a finished file and
+// stores them. Fields the file does not carry are left unchanged.
+func (m *Manager) RefreshMetadataFromFile(ctx context.Context, dbID int64, path string, st MetadataStore) (MediaInfo, error) {
+	info, err := probeFile(ctx, path)
+	if err != nil {
+		return MediaInfo{}, err
+	}
+	if err := m.applyMetadata(ctx, dbID, info, st); err != nil {
+		return MediaInfo{}, err
+	}
+	return info, nil
+}
+
+// probeAdmitted runs the metadata probe a job skipped while its host's
+// breaker was open, now that a worker has admitted the job. The probe does
+// not feed the breaker; the download that follows reports for the host. A
+// failed probe only leaves the item without metadata.
+func (m *Manager) probeAdmitted(ctx context.Context, it *Item) {
+	_ = m.registry.Update(it.ID, func(it *Item) { it.needsProbe = false })
+	probeCtx, cancel := context.WithTimeoutCause(ctx, m.MetadataTimeout(), ErrTimeout)
+	info, err := fetchMediaInfo(probeCtx, it.URL)
+	cancel()
+	logging.LogMetadataFetch(it.URL, it.DBID, err)
+	if err != nil {
+		return
+	}
+	if len(it.sections) == 0 {
+		_ = m.registry.Update(it.ID, func(it *Item) { it.EstimatedBytes = info.FilesizeBytes })
+	}
+	m.saveProbedInfo(it.ID, it.Library, EnqueueOptions{InfoJSON: info.InfoJSON, ProbeTime: info.ProbeTime})
+	st, ok := m.store.(MetadataStore)
+	if !ok || it.DBID <= 0 {
+		m.SetMeta(it.ID, info.Title, info.DurationSec, info.ThumbnailURL)
+		return
+	}
+	m.persistWithRetry("update_metadata", it.DBID, func(ctx context.Context) error {
+		return m.applyMetadata(ctx, it.DBID, info, st)
+	}, "id", it.ID)
+}
+
+func (m *Manager) applyMetadata(ctx context.Context, dbID int64, info MediaInfo, st MetadataStore) error {
+	if err := st.UpdateMeta(ctx, dbID, info.Title, info.DurationSec, info.ThumbnailURL); err != nil {
+		return err
+	}
+	if err := st.UpdateDetails(ctx, dbID, info.Uploader, info.Description); err != nil {
+		return err
+	}
+	if m.registry != nil {
+		if it := m.registry.GetWithDBID(dbID); it != nil {
+			m.SetMeta(it.ID, info.Title, info.DurationSec, info.ThumbnailURL)
+		}
+	}
+	return nil
+}
+
+// ProbeFile reads the title, uploader, description and duration that yt-dlp
+// embedded in a media file, using ffprobe.
+func ProbeFile(ctx context.Context, path string) (MediaInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, ffprobeTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "ffprobe", "-v", "error", "-print_format", "json", "-show_format", path).Output()
+	if err != nil {
+		return MediaInfo{}, fmt.Errorf("ffprobe: %w", err)
+	}
+	return parseProbeOutput(out)
+}
+
+// parseProbeOutput maps ffprobe's format section to MediaInfo. Tag names
+// differ in case between containers, so they are matched case-insensitively.
+func parseProbeOutput(out []byte) (MediaInfo, error) {
+	var probe struct {
+		Format struct {
+			Duration string            `json:"duration"`
+			Tags     map[string]string `json:"tags"`
+		} `json:"format"`
+	}
+	if err := json.Unmarshal(out, &probe); err != nil {
+		return MediaInfo{}, fmt.Errorf("parse ffprobe output: %w", err)
+	}
+	tags := make(map[string]string, len(probe.Format.Tags))
+	for k, v := range probe.Format.Tags {
+		tags[strings.ToLower(k)] = strings.TrimSpace(v)
+	}
+	first := func(keys ...string) string {
+		for _, k := range keys {
+			if v := tags[k]; v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+	info := MediaInfo{
+		Title:       first("title"),
+		Uploader:    first("artist", "album_artist", "uploader"),
+		Description: first("description", "synopsis", "comment"),
+	}
+	if d, err := strconv.ParseFloat(probe.Format.Duration, 64); err == nil && d > 0 {
+		info.DurationSec = int64(d)
+	}
+	if info == (MediaInfo{}) {
+		return MediaInfo{}, ErrNoMediaInfo
+	}
+	return info, nil
+}
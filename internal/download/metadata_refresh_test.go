@@ -0,0 +1,81 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type metaRecorder struct {
+	title, thumb, uploader, description string
+	duration                            int64
+}
+
+func (r *metaRecorder) UpdateMeta(ctx context.Context, id int64, title string, duration int64, thumbnail string) error {
+	r.title, r.duration, r.thumb = title, duration, thumbnail
+	return nil
+}
+
+func (r *metaRecorder) UpdateDetails(ctx context.Context, id int64, uploader, description string) error {
+	r.uploader, r.description = uploader, description
+	return nil
+}
+
+func TestRefreshMetadata_UpdatesStoreAndManagedItem(t *testing.T) {
+	m := NewManager(t.TempDir(), 1, 4)
+	defer m.Shutdown()
+	m.workerDownload = func(ctx context.Context, id, url string) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	attempts := 0
+	origFetch := fetchMediaInfo
+	t.Cleanup(func() { fetchMediaInfo = origFetch })
+	fetchMediaInfo = func(ctx context.Context, inputURL string) (MediaInfo, error) {
+		attempts++
+		if attempts == 1 {
+			return MediaInfo{}, ErrNoMediaInfo
+		}
+		return MediaInfo{Title: "Real title", DurationSec: 42, ThumbnailURL: "https://img.example.com/t.jpg", Uploader: "Chan"}, nil
+	}
+
+	id, err := m.Enqueue("https://example.com/video")
+	if err != nil {
+		t.Fatalf("enqueue failed: %v", err)
+	}
+	m.AttachDB(id, 7)
+
+	rec := &metaRecorder{}
+	if _, err := m.RefreshMetadata(context.Background(), 7, "https://example.com/video", rec); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if attempts != 2 {
+		t.Fatalf("expected transient failure retried, got %d attempts", attempts)
+	}
+	if rec.title != "Real title" || rec.duration != 42 || rec.uploader != "Chan" {
+		t.Fatalf("unexpected stored metadata %+v", rec)
+	}
+	waitForItem(t, m, id, func(it *Item) bool { return it.Title == "Real title" && it.Duration == 42 })
+
+	// Rows the manager no longer tracks are still updated in the store.
+	rec = &metaRecorder{}
+	if _, err := m.RefreshMetadata(context.Background(), 99, "https://example.com/other", rec); err != nil || rec.title != "Real title" {
+		t.Fatalf("refresh of unmanaged row: err=%v rec=%+v", err, rec)
+	}
+}
+
+func TestParseProbeOutput(t *testing.T) {
+	out := []byte(`{"format":{"duration":"125.480000","tags":{"TITLE":"Talk","ARTIST":"Speaker","comment":"https://example.com/v","DESCRIPTION":"About things"}}}`)
+	info, err := parseProbeOutput(out)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	want := MediaInfo{Title: "Talk", Uploader: "Speaker", Description: "About things", DurationSec: 125}
+	if info != want {
+		t.Fatalf("got %+v, want %+v", info, want)
+	}
+	if _, err := parseProbeOutput([]byte(`{"format":{"duration":"N/A"}}`)); !errors.Is(err, ErrNoMediaInfo) {
+		t.Fatalf("expected ErrNoMediaInfo for untagged file, got %v", err)
+	}
+}
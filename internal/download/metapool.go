@@ -0,0 +1,188 @@
+package download
+
+import (
+	"sort"
+	"time"
+)
+
+// Default metadata pool size used by NewDBWorker.
+const (
+	DefaultMetadataWorkers = 4
+	DefaultMetadataQueue   = 64
+)
+
+// MetadataPoolStatus describes the DBWorker's metadata pool at a point in time.
+type MetadataPoolStatus struct {
+	Workers       int `json:"workers"`
+	QueueCapacity int `json:"queue_capacity"`
+	Queued        int `json:"queued"` // pending rows waiting for a probe slot
+	Active        int `json:"active"` // probes running now
+}
+
+// pendingRow is a pending download picked up from the store.
+type pendingRow struct {
+	id            int64
+	url           string
+	library       string
+	maxDuration   time.Duration
+	formatID      string
+	probeToken    string
+	sections      []string // canonical section specs; nil for the whole video
+	splitChapters bool
+	createdAt     time.Time
+}
+
+func pendingRowFromMap(download map[string]interface{}) (pendingRow, bool) {
+	id, okID := download["id"].(int64)
+	url, okURL := download["url"].(string)
+	if !okID || !okURL {
+		return pendingRow{}, false
+	}
+	row := pendingRow{id: id, url: url}
+	row.library, _ = download["library"].(string)
+	row.maxDuration, _ = download["max_duration"].(time.Duration)
+	row.formatID, _ = download["format_id"].(string)
+	row.probeToken, _ = download["probe_token"].(string)
+	row.sections, _ = download["sections"].([]string)
+	row.splitChapters, _ = download["split_chapters"].(bool)
+	row.createdAt, _ = download["created_at"].(time.Time)
+	return row, true
+}
+
+// before orders rows oldest first, by ID when created in the same instant.
+func (r pendingRow) before(o pendingRow) bool {
+	if !r.createdAt.Equal(o.createdAt) {
+		return r.createdAt.Before(o.createdAt)
+	}
+	return r.id < o.id
+}
+
+// SetMetadataPool sets how many metadata probes run at once and how many
+// pending rows may wait for one. Call before Start; values below 1 are ignored.
+func (dw *DBWorker) SetMetadataPool(workers, queueCap int) {
+	dw.poolMu.Lock()
+	defer dw.poolMu.Unlock()
+	if workers >= 1 {
+		dw.metaWorkers = workers
+	}
+	if queueCap >= 1 {
+		dw.metaQueueCap = queueCap
+	}
+}
+
+// MetadataPool reports the metadata pool's size and usage.
+func (dw *DBWorker) MetadataPool() MetadataPoolStatus {
+	dw.poolMu.Lock()
+	defer dw.poolMu.Unlock()
+	return MetadataPoolStatus{
+		Workers:       dw.metaWorkers,
+		QueueCapacity: dw.metaQueueCap,
+		Queued:        len(dw.metaQueue),
+		Active:        dw.metaActive,
+	}
+}
+
+// metadataRoom is how many more rows the pool may take. Every row will need a
+// slot in the manager's queue once probed, so rows already in the pool count
+// against the manager's free slots too; rows beyond that stay pending.
+func (dw *DBWorker) metadataRoom() int {
+	dw.poolMu.Lock()
+	room := dw.metaQueueCap - len(dw.metaQueue)
+	inPool := len(dw.metaInFlight)
+	dw.poolMu.Unlock()
+	if dw.manager != nil {
+		ps := dw.manager.PoolSize()
+		room = min(room, ps.QueueCapacity-ps.Queued-inPool)
+	}
+	return max(room, 0)
+}
+
+// admitPending adds rows not already in the pool, keeping the queue ordered
+// by creation time so rows reset to pending go ahead of newer ones.
+func (dw *DBWorker) admitPending(rows []pendingRow, room int) int {
+	dw.poolMu.Lock()
+	defer dw.poolMu.Unlock()
+	added := 0
+	for _, row := range rows {
+		if added == room {
+			break
+		}
+		if _, dup := dw.metaInFlight[row.id]; dup {
+			continue
+		}
+		dw.metaInFlight[row.id] = struct{}{}
+		i := sort.Search(len(dw.metaQueue), func(i int) bool { return row.before(dw.metaQueue[i]) })
+		dw.metaQueue = append(dw.metaQueue, pendingRow{})
+		copy(dw.metaQueue[i+1:], dw.metaQueue[i:])
+		dw.metaQueue[i] = row
+		added++
+	}
+	if added > 0 {
+		dw.signalMetadata()
+	}
+	return added
+}
+
+// signalMetadata wakes one idle metadata worker.
+func (dw *DBWorker) signalMetadata() {
+	select {
+	case dw.metaWake <- struct{}{}:
+	default:
+	}
+}
+
+// nextPending pops the oldest queued row and marks it active.
+func (dw *DBWorker) nextPending() (pendingRow, bool) {
+	dw.poolMu.Lock()
+	defer dw.poolMu.Unlock()
+	if len(dw.metaQueue) == 0 {
+		return pendingRow{}, false
+	}
+	row := dw.metaQueue[0]
+	dw.metaQueue = dw.metaQueue[1:]
+	dw.metaActive++
+	if len(dw.metaQueue) > 0 {
+		// Pass the wakeup on so idle workers drain the rest.
+		dw.signalMetadata()
+	}
+	return row, true
+}
+
+func (dw *DBWorker) finishPending(row pendingRow) {
+	dw.poolMu.Lock()
+	dw.metaActive--
+	delete(dw.metaInFlight, row.id)
+	dw.poolMu.Unlock()
+	if dw.backlog.Load() {
+		dw.kickSweep()
+	}
+}
+
+func (dw *DBWorker) startMetadataWorkers() {
+	dw.poolMu.Lock()
+	workers := dw.metaWorkers
+	dw.poolMu.Unlock()
+	dw.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go dw.metadataWorker()
+	}
+}
+
+func (dw *DBWorker) metadataWorker() {
+	defer dw.wg.Done()
+	for {
+		select {
+		case <-dw.ctx.Done():
+			return
+		case <-dw.metaWake:
+		}
+		for dw.ctx.Err() == nil {
+			row, ok := dw.nextPending()
+			if !ok {
+				break
+			}
+			dw.process(row)
+			dw.finishPending(row)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package download
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// pendingStore serves pending rows oldest first, like the SQLite store.
+type pendingStore struct {
+	mockStore
+	mu      sync.Mutex
+	rows    map[int64]time.Time
+	fetches []int
+}
+
+func (s *pendingStore) add(id int64, createdAt time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.rows == nil {
+		s.rows = make(map[int64]time.Time)
+	}
+	s.rows[id] = createdAt
+}
+
+func (s *pendingStore) remove(id int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.rows, id)
+}
+
+func (s *pendingStore) GetPendingDownloadsForWorker(ctx context.Context, limit int) ([]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fetches = append(s.fetches, limit)
+	ids := make([]int64, 0, len(s.rows))
+	for id := range s.rows {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return s.rows[ids[i]].Before(s.rows[ids[j]]) })
+	out := []interface{}{}
+	for _, id := range ids[:min(limit, len(ids))] {
+		out = append(out, map[string]interface{}{"id": id, "url": "https://example.com/v", "created_at": s.rows[id]})
+	}
+	return out, nil
+}
+
+func TestMetadataPool_BoundedFairAndDeduped(t *testing.T) {
+	base := time.Now()
+	st := &pendingStore{}
+	for id := int64(2); id <= 7; id++ {
+		st.add(id, base.Add(time.Duration(id)*time.Second))
+	}
+	mgr := NewManager(t.TempDir(), 1, 16)
+	defer mgr.Shutdown()
+	dw := NewDBWorker(st, mgr)
+	dw.SetMetadataPool(2, 3)
+
+	var mu sync.Mutex
+	var started []int64
+	running, peak := 0, 0
+	release := make(chan struct{})
+	dw.process = func(row pendingRow) {
+		mu.Lock()
+		started = append(started, row.id)
+		running++
+		peak = max(peak, running)
+		mu.Unlock()
+		<-release
+		st.remove(row.id)
+		mu.Lock()
+		running--
+		mu.Unlock()
+	}
+	dw.startMetadataWorkers()
+	defer func() { dw.cancel(); dw.wg.Wait() }()
+
+	waitPool := func(want MetadataPoolStatus) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for dw.MetadataPool() != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("pool = %+v, want %+v", dw.MetadataPool(), want)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	if err := dw.processPendingURLs(); err != nil {
+		t.Fatalf("process pending: %v", err)
+	}
+	waitPool(MetadataPoolStatus{Workers: 2, QueueCapacity: 3, Queued: 1, Active: 2})
+
+	// A row reset to pending keeps its creation time and goes ahead of newer ones.
+	st.add(1, base)
+	if err := dw.processPendingURLs(); err != nil {
+		t.Fatalf("process pending: %v", err)
+	}
+	waitPool(MetadataPoolStatus{Workers: 2, QueueCapacity: 3, Queued: 3, Active: 2})
+	dw.poolMu.Lock()
+	var queued []int64
+	for _, row := range dw.metaQueue {
+		queued = append(queued, row.id)
+	}
+	dw.poolMu.Unlock()
+	if len(queued) != 3 || queued[0] != 1 || queued[1] != 4 || queued[2] != 5 {
+		t.Fatalf("queue order = %v, want [1 4 5]", queued)
+	}
+
+	// Full pool: the store is not asked for more.
+	if err := dw.processPendingURLs(); err != nil {
+		t.Fatalf("process pending: %v", err)
+	}
+	if want := []int{3, 5}; len(st.fetches) != 2 || st.fetches[0] != want[0] || st.fetches[1] != want[1] {
+		t.Fatalf("fetch limits = %v, want %v", st.fetches, want)
+	}
+
+	close(release)
+	waitPool(MetadataPoolStatus{Workers: 2, QueueCapacity: 3})
+	mu.Lock()
+	defer mu.Unlock()
+	sort.Slice(started, func(i, j int) bool { return started[i] < started[j] })
+	for i, id := range started {
+		if len(started) != 5 || id != int64(i+1) {
+			t.Fatalf("expected rows 1-5 probed once each, got %v", started)
+		}
+	}
+	if peak != 2 {
+		t.Fatalf("expected at most 2 concurrent probes, peak was %d", peak)
+	}
+}
+
+func TestMetadataPool_LimitedByManagerQueue(t *testing.T) {
+	st := &pendingStore{}
+	for id := int64(1); id <= 5; id++ {
+		st.add(id, time.Now())
+	}
+	mgr := NewManager(t.TempDir(), 1, 2)
+	defer mgr.Shutdown()
+	dw := NewDBWorker(st, mgr)
+
+	if err := dw.processPendingURLs(); err != nil {
+		t.Fatalf("process pending: %v", err)
+	}
+	// Each probed row needs a slot in the manager's queue; the rest stay pending.
+	if got := dw.MetadataPool(); got.Queued != 2 {
+		t.Fatalf("expected 2 rows admitted for a queue of 2, got %+v", got)
+	}
+	if room := dw.metadataRoom(); room != 0 {
+		t.Fatalf("expected no room left, got %d", room)
+	}
+}
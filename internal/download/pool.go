@@ -0,0 +1,146 @@
+package download
+
+import (
+	"log/slog"
+)
+
+// Upper bounds for Resize. Sizes are saved and reapplied at startup, so a
+// typo must not leave the queue allocating gigabytes on every boot.
+const (
+	MaxWorkers       = 256
+	MaxQueueCapacity = 100000
+)
+
+// PoolSize describes the worker pool and queue at a point in time.
+type PoolSize struct {
+	Workers       int `json:"workers"`
+	QueueCapacity int `json:"queue_capacity"`
+	Queued        int `json:"queued"`
+	Active        int `json:"active"`
+}
+
+// PoolSize returns the current worker count, queue capacity and usage.
+func (m *Manager) PoolSize() PoolSize {
+	m.poolMu.Lock()
+	workers := len(m.workerQuit)
+	m.poolMu.Unlock()
+
+	m.queueMu.Lock()
+	capacity, queued := cap(m.jobs), len(m.jobs)
+	m.queueMu.Unlock()
+
+	m.activeMu.Lock()
+	active := len(m.activeByID)
+	m.activeMu.Unlock()
+
+	return PoolSize{Workers: workers, QueueCapacity: capacity, Queued: queued, Active: active}
+}
+
+// Resize changes the worker count and queue capacity without a restart.
+// Retired workers finish their current job before exiting. The queue cannot
+// shrink below the number of jobs already waiting in it.
+func (m *Manager) Resize(workers, queueCap int) error {
+	if workers < 1 || queueCap < 1 || workers > MaxWorkers || queueCap > MaxQueueCapacity {
+		return ErrInvalidPoolSize
+	}
+	if m.closing.Load() {
+		return ErrShuttingDown
+	}
+	if err := m.setQueueCapacity(queueCap); err != nil {
+		return err
+	}
+	m.setWorkers(workers)
+	slog.Info("worker pool resized",
+		"event", "pool_resized",
+		"workers", workers,
+		"queue_capacity", queueCap)
+	return nil
+}
+
+func (m *Manager) setWorkers(n int) {
+	m.poolMu.Lock()
+	defer m.poolMu.Unlock()
+	current := len(m.workerQuit)
+	switch {
+	case n > current:
+		m.spawnWorkersLocked(n - current)
+	case n < current:
+		for _, quit := range m.workerQuit[n:] {
+			close(quit)
+		}
+		m.workerQuit = m.workerQuit[:n]
+	}
+}
+
+func (m *Manager) spawnWorkersLocked(n int) {
+	for i := 0; i < n; i++ {
+		quit := make(chan struct{})
+		m.workerQuit = append(m.workerQuit, quit)
+		idx := m.nextWorkerIdx
+		m.nextWorkerIdx++
+		m.wg.Add(1)
+		go m.worker(idx, quit)
+	}
+}
+
+// setQueueCapacity swaps the jobs channel for one of the new size, carrying
+// queued jobs over in order. Closing the old channel wakes idle workers so
+// they pick up the new one.
+func (m *Manager) setQueueCapacity(n int) error {
+	m.queueMu.Lock()
+	defer m.queueMu.Unlock()
+	if m.jobsClosed {
+		return ErrShuttingDown
+	}
+	if n == cap(m.jobs) {
+		return nil
+	}
+	if len(m.jobs) > n {
+		return ErrQueueTooSmall
+	}
+	old := m.jobs
+	next := make(chan job, n)
+	for drained := false; !drained; {
+		select {
+		case j := <-old:
+			next <- j
+		default:
+			drained = true
+		}
+	}
+	m.jobs = next
+	close(old)
+	return nil
+}
+
+// nextJob waits for the next queued job. It returns false when the worker
+// should exit: the current queue was closed or quit was signalled.
+func (m *Manager) nextJob(quit <-chan struct{}) (job, bool) {
+	for {
+		select {
+		case <-quit:
+			return job{}, false
+		default:
+		}
+
+		m.queueMu.Lock()
+		jobs := m.jobs
+		m.queueMu.Unlock()
+
+		select {
+		case j, ok := <-jobs:
+			if ok {
+				return j, true
+			}
+			m.queueMu.Lock()
+			swapped := m.jobs != jobs
+			m.queueMu.Unlock()
+			if !swapped {
+				return job{}, false
+			}
+			// The channel was replaced by a resize; read from the new one.
+		case <-quit:
+			return job{}, false
+		}
+	}
+}
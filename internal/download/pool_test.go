@@ -0,0 +1,129 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+func TestResize_GrowsAndShrinksWorkersWithoutCancelingJobs(t *testing.T) {
+	m := NewManager(t.TempDir(), 1, 8)
+	defer m.Shutdown()
+
+	release := make(chan struct{})
+	var running, canceled atomic.Int32
+	m.workerDownload = func(ctx context.Context, id, url string) error {
+		running.Add(1)
+		defer running.Add(-1)
+		select {
+		case <-release:
+			return nil
+		case <-ctx.Done():
+			canceled.Add(1)
+			return ctx.Err()
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := m.Enqueue("https://example.com/v"); err != nil {
+			t.Fatalf("enqueue: %v", err)
+		}
+	}
+	waitFor(t, "first job", func() bool { return running.Load() == 1 })
+
+	if err := m.Resize(3, 8); err != nil {
+		t.Fatalf("grow: %v", err)
+	}
+	waitFor(t, "three concurrent jobs", func() bool { return running.Load() == 3 })
+	if got := m.PoolSize(); got.Workers != 3 || got.Active != 3 {
+		t.Fatalf("unexpected pool size after grow: %+v", got)
+	}
+
+	if err := m.Resize(1, 8); err != nil {
+		t.Fatalf("shrink: %v", err)
+	}
+	if got := m.PoolSize().Workers; got != 1 {
+		t.Fatalf("expected 1 worker after shrink, got %d", got)
+	}
+	close(release)
+	waitFor(t, "jobs to finish", func() bool { return running.Load() == 0 })
+	if canceled.Load() != 0 {
+		t.Fatalf("expected shrink to let running jobs finish, %d canceled", canceled.Load())
+	}
+	for _, it := range m.Snapshot("") {
+		if it.State != StateCompleted {
+			t.Fatalf("expected all jobs completed, got %s for %s", it.State, it.ID)
+		}
+	}
+}
+
+func TestResize_QueueCapacityKeepsQueuedJobs(t *testing.T) {
+	m := NewManager(t.TempDir(), 1, 2)
+	defer m.Shutdown()
+
+	block := make(chan struct{})
+	var calls atomic.Int32
+	m.workerDownload = func(ctx context.Context, id, url string) error {
+		calls.Add(1)
+		<-block
+		return nil
+	}
+
+	if _, err := m.Enqueue("https://example.com/running"); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	waitFor(t, "first job to start", func() bool { return calls.Load() == 1 })
+	for i := 0; i < 2; i++ {
+		if _, err := m.Enqueue("https://example.com/queued"); err != nil {
+			t.Fatalf("enqueue %d: %v", i, err)
+		}
+	}
+	if _, err := m.Enqueue("https://example.com/overflow"); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("expected queue full at capacity 2, got %v", err)
+	}
+
+	if err := m.Resize(1, 1); !errors.Is(err, ErrQueueTooSmall) {
+		t.Fatalf("expected ErrQueueTooSmall, got %v", err)
+	}
+	if err := m.Resize(1, 4); err != nil {
+		t.Fatalf("grow queue: %v", err)
+	}
+	if got := m.PoolSize(); got.QueueCapacity != 4 || got.Queued != 2 {
+		t.Fatalf("expected queued jobs carried over, got %+v", got)
+	}
+	if _, err := m.Enqueue("https://example.com/more"); err != nil {
+		t.Fatalf("expected room after grow: %v", err)
+	}
+
+	close(block)
+	waitFor(t, "all jobs to run", func() bool { return calls.Load() == 4 })
+}
+
+func TestResize_RejectsInvalidSizes(t *testing.T) {
+	m := NewManager(t.TempDir(), 1, 2)
+	defer m.Shutdown()
+	if err := m.Resize(0, 2); !errors.Is(err, ErrInvalidPoolSize) {
+		t.Fatalf("expected ErrInvalidPoolSize, got %v", err)
+	}
+	if err := m.Resize(1, MaxQueueCapacity+1); !errors.Is(err, ErrInvalidPoolSize) {
+		t.Fatalf("expected ErrInvalidPoolSize above the queue maximum, got %v", err)
+	}
+	m.Shutdown()
+	if err := m.Resize(2, 2); !errors.Is(err, ErrShuttingDown) {
+		t.Fatalf("expected ErrShuttingDown after shutdown, got %v", err)
+	}
+}
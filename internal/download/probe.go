@@ -0,0 +1,283 @@
+package download
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// probeCacheTTL is how long a /api/probe result can be reused. Format URLs
+// in it expire, so it is kept short.
+const probeCacheTTL = 5 * time.Minute
+
+// probeCacheMax bounds the cached probes; the oldest is evicted first.
+const probeCacheMax = 256
+
+// probeMedia is replaced in tests.
+var probeMedia = ProbeMedia
+
+// Format is one downloadable format reported by yt-dlp.
+type Format struct {
+	ID         string  `json:"format_id"`
+	Ext        string  `json:"ext,omitempty"`
+	Resolution string  `json:"resolution,omitempty"` // e.g. "1920x1080" or "audio only"
+	FPS        float64 `json:"fps,omitempty"`
+	VCodec     string  `json:"vcodec,omitempty"` // "none" for audio-only formats
+	ACodec     string  `json:"acodec,omitempty"` // "none" for video-only formats
+	// FilesizeBytes is exact, or yt-dlp's estimate when FilesizeApprox is set; 0 if unknown.
+	FilesizeBytes  int64  `json:"filesize,omitempty"`
+	FilesizeApprox bool   `json:"filesize_approx,omitempty"`
+	Note           string `json:"note,omitempty"`
+}
+
+// ProbeResult is what yt-dlp reports for a URL before it is enqueued.
+type ProbeResult struct {
+	Token        string    `json:"token"` // pass to the enqueue request to reuse this probe
+	URL          string    `json:"url"`
+	Title        string    `json:"title"`
+	DurationSec  int64     `json:"duration"`
+	ThumbnailURL string    `json:"thumbnail_url,omitempty"`
+	Uploader     string    `json:"uploader,omitempty"`
+	IsPlaylist   bool      `json:"is_playlist"`
+	EntryCount   int       `json:"entry_count,omitempty"` // playlist entries, when known
+	IsLive       bool      `json:"is_live"`
+	Formats      []Format  `json:"formats"`
+	Cached       bool      `json:"cached"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// HasFormat reports whether id is one of the probed formats. Merged
+// selectors such as "137+140" match when every part does.
+func (p ProbeResult) HasFormat(id string) bool {
+	if id == "" {
+		return false
+	}
+	for _, part := range strings.Split(id, "+") {
+		found := false
+		for _, f := range p.Formats {
+			if f.ID == part {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// ProbeMedia runs `yt-dlp -J` for a URL without downloading it and returns
+// its metadata and full format list. Playlists are listed flat, so their
+// formats are empty. The MediaInfo carries the info JSON for single videos.
+func ProbeMedia(ctx context.Context, inputURL string) (ProbeResult, MediaInfo, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := CheckYTDLP(); err != nil {
+		return ProbeResult{}, MediaInfo{}, err
+	}
+	if err := validateURL(inputURL); err != nil {
+		return ProbeResult{}, MediaInfo{}, fmt.Errorf("invalid URL: %w", err)
+	}
+	cmd := exec.CommandContext(ctx, YTDLPPath(), "-J", "--flat-playlist", "--extractor-args", "generic:impersonate", "--no-playlist", inputURL)
+	out, err := cmd.Output()
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ProbeResult{}, MediaInfo{}, ctxErr
+		}
+		return ProbeResult{}, MediaInfo{}, fmt.Errorf("yt-dlp probe: %w", err)
+	}
+	raw := strings.TrimSpace(string(out))
+	var m map[string]any
+	if err := json.Unmarshal([]byte(raw), &m); err != nil || len(m) == 0 {
+		return ProbeResult{}, MediaInfo{}, ErrNoMediaInfo
+	}
+	return parseProbe(m, inputURL, raw)
+}
+
+func parseProbe(m map[string]any, inputURL, raw string) (ProbeResult, MediaInfo, error) {
+	info := mediaInfoFromJSON(m, inputURL, raw)
+	res := ProbeResult{
+		URL:          inputURL,
+		Title:        info.Title,
+		DurationSec:  info.DurationSec,
+		ThumbnailURL: info.ThumbnailURL,
+		Uploader:     info.Uploader,
+		Formats:      []Format{},
+	}
+	if t, _ := m["_type"].(string); t == "playlist" {
+		res.IsPlaylist = true
+		if n, ok := m["playlist_count"].(float64); ok {
+			res.EntryCount = int(n)
+		} else if entries, ok := m["entries"].([]any); ok {
+			res.EntryCount = len(entries)
+		}
+		// A flat playlist cannot be loaded by the download step.
+		info.InfoJSON = ""
+	}
+	live, _ := m["is_live"].(bool)
+	status, _ := m["live_status"].(string)
+	res.IsLive = live || status == "is_live"
+
+	formats, _ := m["formats"].([]any)
+	for _, item := range formats {
+		fm, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		f := Format{}
+		f.ID, _ = fm["format_id"].(string)
+		if f.ID == "" {
+			continue
+		}
+		f.Ext, _ = fm["ext"].(string)
+		f.Resolution, _ = fm["resolution"].(string)
+		if f.Resolution == "" {
+			w, _ := fm["width"].(float64)
+			h, _ := fm["height"].(float64)
+			if w > 0 && h > 0 {
+				f.Resolution = fmt.Sprintf("%dx%d", int(w), int(h))
+			}
+		}
+		f.FPS, _ = fm["fps"].(float64)
+		f.VCodec, _ = fm["vcodec"].(string)
+		f.ACodec, _ = fm["acodec"].(string)
+		if v, ok := fm["filesize"].(float64); ok && v > 0 {
+			f.FilesizeBytes = int64(v)
+		} else if v, ok := fm["filesize_approx"].(float64); ok && v > 0 {
+			f.FilesizeBytes, f.FilesizeApprox = int64(v), true
+		}
+		f.Note, _ = fm["format_note"].(string)
+		res.Formats = append(res.Formats, f)
+	}
+	return res, info, nil
+}
+
+// probeCache holds recent probe results by token, guarded by mu.
+type probeCache struct {
+	mu      sync.Mutex
+	entries map[string]*probeEntry
+	byURL   map[string]string // URL -> token of its newest probe
+}
+
+type probeEntry struct {
+	result ProbeResult
+	info   MediaInfo
+}
+
+func (c *probeCache) pruneLocked(now time.Time) {
+	for token, e := range c.entries {
+		if !now.Before(e.result.ExpiresAt) {
+			c.removeLocked(token)
+		}
+	}
+}
+
+func (c *probeCache) removeLocked(token string) {
+	e, ok := c.entries[token]
+	if !ok {
+		return
+	}
+	delete(c.entries, token)
+	if c.byURL[e.result.URL] == token {
+		delete(c.byURL, e.result.URL)
+	}
+}
+
+func (c *probeCache) add(res ProbeResult, info MediaInfo) ProbeResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if c.entries == nil {
+		c.entries = make(map[string]*probeEntry)
+		c.byURL = make(map[string]string)
+	}
+	c.pruneLocked(now)
+	for len(c.entries) >= probeCacheMax {
+		oldest := ""
+		for token, e := range c.entries {
+			if oldest == "" || e.result.ExpiresAt.Before(c.entries[oldest].result.ExpiresAt) {
+				oldest = token
+			}
+		}
+		c.removeLocked(oldest)
+	}
+	res.Token = genID()
+	res.ExpiresAt = now.Add(probeCacheTTL)
+	c.entries[res.Token] = &probeEntry{result: res, info: info}
+	c.byURL[res.URL] = res.Token
+	return res
+}
+
+func (c *probeCache) get(token string) (*probeEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pruneLocked(time.Now())
+	e, ok := c.entries[token]
+	return e, ok
+}
+
+func (c *probeCache) getByURL(url string) (*probeEntry, bool) {
+	c.mu.Lock()
+	token, ok := c.byURL[url]
+	c.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+	return c.get(token)
+}
+
+// Probe returns yt-dlp's metadata and format list for url without enqueuing
+// it. Results are cached for a few minutes; the returned token lets the
+// enqueue request reuse the result and pin one of its formats.
+func (m *Manager) Probe(ctx context.Context, url string) (ProbeResult, error) {
+	if e, ok := m.probes.getByURL(url); ok {
+		res := e.result
+		res.Cached = true
+		return res, nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	timeout := m.MetadataTimeout()
+	probeCtx, cancel := context.WithTimeoutCause(ctx, timeout, ErrTimeout)
+	defer cancel()
+	started := time.Now()
+	res, info, err := probeMedia(probeCtx, url)
+	if err != nil {
+		if errors.Is(context.Cause(probeCtx), ErrTimeout) {
+			return ProbeResult{}, fmt.Errorf("%w: probe exceeded %s", ErrTimeout, timeout)
+		}
+		return ProbeResult{}, err
+	}
+	info.ProbeTime = time.Since(started)
+	return m.probes.add(res, info), nil
+}
+
+// LookupProbe returns a cached probe by token while it is still fresh.
+func (m *Manager) LookupProbe(token string) (ProbeResult, bool) {
+	e, ok := m.probes.get(token)
+	if !ok {
+		return ProbeResult{}, false
+	}
+	return e.result, true
+}
+
+// probedInfo returns the metadata of a cached probe of url, for enqueues that
+// carry its token. Playlist probes are not reused.
+func (m *Manager) probedInfo(token, url string) (MediaInfo, bool) {
+	if token == "" {
+		return MediaInfo{}, false
+	}
+	e, ok := m.probes.get(token)
+	if !ok || e.result.URL != url || e.result.IsPlaylist {
+		return MediaInfo{}, false
+	}
+	return e.info, true
+}
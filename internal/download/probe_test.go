@@ -0,0 +1,97 @@
+package download
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestParseProbe_FormatsAndFlags(t *testing.T) {
+	raw := `{"title":"Clip","duration":61,"uploader":"Chan","live_status":"is_live","formats":[
+		{"format_id":"137","ext":"mp4","width":1920,"height":1080,"fps":30,"vcodec":"avc1.640028","acodec":"none","filesize":1000},
+		{"format_id":"140","ext":"m4a","resolution":"audio only","vcodec":"none","acodec":"mp4a.40.2","filesize_approx":300,"format_note":"medium"},
+		{"ext":"mhtml"}]}`
+	var m map[string]any
+	if err := json.Unmarshal([]byte(raw), &m); err != nil {
+		t.Fatal(err)
+	}
+	res, info, err := parseProbe(m, "https://example.com/v", raw)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if res.Title != "Clip" || res.DurationSec != 61 || res.Uploader != "Chan" || !res.IsLive || res.IsPlaylist {
+		t.Fatalf("unexpected probe %+v", res)
+	}
+	want := []Format{
+		{ID: "137", Ext: "mp4", Resolution: "1920x1080", FPS: 30, VCodec: "avc1.640028", ACodec: "none", FilesizeBytes: 1000},
+		{ID: "140", Ext: "m4a", Resolution: "audio only", VCodec: "none", ACodec: "mp4a.40.2", FilesizeBytes: 300, FilesizeApprox: true, Note: "medium"},
+	}
+	if len(res.Formats) != len(want) || res.Formats[0] != want[0] || res.Formats[1] != want[1] {
+		t.Fatalf("formats = %+v, want %+v", res.Formats, want)
+	}
+	if info.InfoJSON != raw {
+		t.Fatalf("expected the probe's info JSON to be kept for the download")
+	}
+	if !res.HasFormat("137+140") || res.HasFormat("137+22") || res.HasFormat("") {
+		t.Fatalf("HasFormat mismatch")
+	}
+
+	m = map[string]any{"_type": "playlist", "title": "List", "playlist_count": float64(12)}
+	res, info, _ = parseProbe(m, "https://example.com/list", `{}`)
+	if !res.IsPlaylist || res.EntryCount != 12 || len(res.Formats) != 0 || info.InfoJSON != "" {
+		t.Fatalf("unexpected playlist probe %+v / %q", res, info.InfoJSON)
+	}
+}
+
+func TestRunOptions_Args(t *testing.T) {
+	args := RunOptions{InfoJSONPath: "/tmp/p.json", FormatID: "137+140"}.apply(buildYTDLPArgs("https://example.com/v", "%(title)s", "/out", "/tmp", true), "%(title)s")
+	joined := strings.Join(args, " ")
+	if !strings.HasPrefix(joined, "--load-info-json /tmp/p.json --progress-template") || !strings.HasSuffix(joined, "-f 137+140") {
+		t.Fatalf("unexpected args %q", joined)
+	}
+}
+
+func TestProbe_CachedAndReusedByPendingDownload(t *testing.T) {
+	var probes atomic.Int32
+	origProbe, origFetch := probeMedia, fetchMediaInfo
+	t.Cleanup(func() { probeMedia, fetchMediaInfo = origProbe, origFetch })
+	probeMedia = func(ctx context.Context, url string) (ProbeResult, MediaInfo, error) {
+		probes.Add(1)
+		return ProbeResult{URL: url, Title: "Clip", Formats: []Format{{ID: "18"}}}, MediaInfo{Title: "Clip", DurationSec: 5}, nil
+	}
+	fetchMediaInfo = func(ctx context.Context, url string) (MediaInfo, error) {
+		return MediaInfo{}, errors.New("unexpected second probe")
+	}
+
+	m := NewManager(t.TempDir(), 1, 4)
+	defer m.Shutdown()
+	started := make(chan *Item, 1)
+	m.workerDownload = func(ctx context.Context, id, url string) error {
+		started <- m.registry.Get(id)
+		return nil
+	}
+
+	first, err := m.Probe(context.Background(), "https://example.com/v")
+	if err != nil || first.Token == "" || first.Cached {
+		t.Fatalf("first probe: %+v %v", first, err)
+	}
+	again, err := m.Probe(context.Background(), "https://example.com/v")
+	if err != nil || !again.Cached || again.Token != first.Token || probes.Load() != 1 {
+		t.Fatalf("expected cached probe, got %+v %v (probes=%d)", again, err, probes.Load())
+	}
+	if _, ok := m.LookupProbe(first.Token); !ok {
+		t.Fatalf("expected token lookup to succeed")
+	}
+
+	opts := EnqueueOptions{FormatID: "18", ProbeToken: first.Token}
+	if err := m.ProcessPendingDownloadWithOptions(context.Background(), 7, "https://example.com/v", opts, &mockStore{}); err != nil {
+		t.Fatalf("process pending: %v", err)
+	}
+	it := <-started
+	if it.Title != "Clip" || it.formatID != "18" {
+		t.Fatalf("expected probed metadata and pinned format on the job, got %+v", it)
+	}
+}
@@ -0,0 +1,7 @@
+package download
+
+import "time"
+
+// killGrace is how long a canceled download's processes get to exit after
+// the polite signal before they are killed outright.
+var killGrace = 5 * time.Second
@@ -0,0 +1,68 @@
+//go:build !windows
+
+package download
+
+import (
+	"errors"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// useProcessGroup runs cmd, which must come from exec.CommandContext, as the
+// leader of a new process group so that cancellation reaches the ffmpeg
+// children yt-dlp spawns and not just yt-dlp. On cancel the whole group gets
+// SIGTERM, then SIGKILL if anything is left after killGrace.
+func useProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		pgid := cmd.Process.Pid
+		// Output is read until EOF before Wait runs, so a child holding the
+		// pipes has to be killed here rather than by Wait.
+		time.AfterFunc(killGrace, func() {
+			if groupAlive(pgid) {
+				_ = syscall.Kill(-pgid, syscall.SIGKILL)
+			}
+		})
+		return syscall.Kill(-pgid, syscall.SIGTERM)
+	}
+	// A child still holding yt-dlp's output pipes must not keep Wait blocked.
+	cmd.WaitDelay = killGrace
+}
+
+// endProcessGroup makes sure nothing from cmd's process group outlives it,
+// so callers can clean up files without racing a child that is still
+// writing. Survivors get SIGTERM, then SIGKILL after killGrace. Commands
+// not started by useProcessGroup are left alone.
+func endProcessGroup(cmd *exec.Cmd) {
+	if cmd.Process == nil || cmd.SysProcAttr == nil || !cmd.SysProcAttr.Setpgid {
+		return
+	}
+	pgid := cmd.Process.Pid
+	if !groupAlive(pgid) {
+		return
+	}
+	_ = syscall.Kill(-pgid, syscall.SIGTERM)
+	if waitGroupGone(pgid, killGrace) {
+		return
+	}
+	_ = syscall.Kill(-pgid, syscall.SIGKILL)
+	waitGroupGone(pgid, time.Second)
+}
+
+// groupAlive reports whether any process of the group still exists.
+func groupAlive(pgid int) bool {
+	err := syscall.Kill(-pgid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+func waitGroupGone(pgid int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for groupAlive(pgid) {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	return true
+}
@@ -0,0 +1,71 @@
+//go:build !windows
+
+package download
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDownload_CancelKillsChildProcesses(t *testing.T) {
+	orig := killGrace
+	killGrace = 200 * time.Millisecond
+	t.Cleanup(func() { killGrace = orig })
+
+	dir := t.TempDir()
+	childLog := filepath.Join(dir, "child.log")
+	fake := filepath.Join(dir, "yt-dlp")
+	// The child stands in for an ffmpeg merger that ignores SIGTERM and
+	// keeps writing after yt-dlp itself is gone.
+	script := `#!/usr/bin/env bash
+if [[ "${1:-}" == "--help" ]]; then
+  echo "supports --progress-template"
+  exit 0
+fi
+( trap '' TERM; while true; do echo x >> "` + childLog + `"; sleep 0.02; done ) &
+wait
+`
+	if err := os.WriteFile(fake, []byte(script), 0o755); err != nil {
+		t.Fatalf("write fake yt-dlp: %v", err)
+	}
+	SetYTDLPPath(fake)
+	t.Cleanup(func() { SetYTDLPPath("") })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- NewDownloader(t.TempDir()).Download(ctx, "job", "https://example.com/v") }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if info, err := os.Stat(childLog); err == nil && info.Size() > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("child process never started")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatalf("expected canceled download to fail")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("download did not return after cancel")
+	}
+
+	before, err := os.Stat(childLog)
+	if err != nil {
+		t.Fatalf("stat child log: %v", err)
+	}
+	time.Sleep(150 * time.Millisecond)
+	after, _ := os.Stat(childLog)
+	if after.Size() != before.Size() {
+		t.Fatalf("child kept writing after Download returned (%d -> %d bytes)", before.Size(), after.Size())
+	}
+}
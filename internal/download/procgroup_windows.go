@@ -0,0 +1,25 @@
+//go:build windows
+
+package download
+
+import (
+	"os/exec"
+	"strconv"
+)
+
+// useProcessGroup makes cancellation of cmd, which must come from
+// exec.CommandContext, end its whole process tree, including the ffmpeg
+// children yt-dlp spawns. Console processes have no polite stop signal
+// here, so the tree is terminated at once.
+func useProcessGroup(cmd *exec.Cmd) {
+	cmd.Cancel = func() error {
+		if err := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid)).Run(); err != nil {
+			return cmd.Process.Kill()
+		}
+		return nil
+	}
+	cmd.WaitDelay = killGrace
+}
+
+// endProcessGroup is a no-op on Windows; taskkill /T already ended the tree.
+func endProcessGroup(cmd *exec.Cmd) {}
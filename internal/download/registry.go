@@ -145,6 +145,9 @@ func (r *ItemRegistry) SetState(id string, state State, errMsg string) error {
 	return r.Update(id, func(it *Item) {
 		it.State = state
 		it.Error = errMsg
+		if state != StateFailed {
+			it.ErrorClass = ""
+		}
 	})
 }
 
@@ -176,6 +179,20 @@ func (r *ItemRegistry) Size() int {
 	return len(r.downloads)
 }
 
+// DBIDs lists the database IDs of items attached to a row.
+func (r *ItemRegistry) DBIDs() []int64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]int64, 0, len(r.downloads))
+	for _, it := range r.downloads {
+		if it.DBID > 0 {
+			out = append(out, it.DBID)
+		}
+	}
+	return out
+}
+
 // GetWithDBID retrieves the item with matching DBID if present.
 func (r *ItemRegistry) GetWithDBID(dbID int64) *Item {
 	r.mu.RLock()
@@ -0,0 +1,175 @@
+package download
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidSection classifies section specs ParseSections rejects.
+var ErrInvalidSection = errors.New("invalid_section")
+
+// maxSections bounds the sections of one job.
+const maxSections = 16
+
+// chapterPrefix marks a section spec naming a chapter rather than a time range.
+const chapterPrefix = "chapter:"
+
+// Section is a part of a video to download instead of the whole of it.
+type Section struct {
+	// Start and End bound a time range; End 0 means to the end of the video.
+	Start, End time.Duration
+	// Chapter names a chapter, matched case-insensitively; set instead of a time range.
+	Chapter string
+}
+
+// String returns the canonical spec of the section, as accepted by ParseSections:
+// "12:00-18:00", "1:02:03-end" or "chapter:Intro".
+func (s Section) String() string {
+	if s.Chapter != "" {
+		return chapterPrefix + s.Chapter
+	}
+	end := "end"
+	if s.End > 0 {
+		end = formatTimestamp(s.End)
+	}
+	return formatTimestamp(s.Start) + "-" + end
+}
+
+// ytdlpArg is the section's --download-sections value: a "*" time range in
+// seconds, or an anchored regular expression matching the chapter title.
+func (s Section) ytdlpArg() string {
+	if s.Chapter != "" {
+		return "(?i)^" + regexp.QuoteMeta(s.Chapter) + "$"
+	}
+	end := "inf"
+	if s.End > 0 {
+		end = strconv.FormatInt(int64(s.End/time.Second), 10)
+	}
+	return fmt.Sprintf("*%d-%s", int64(s.Start/time.Second), end)
+}
+
+// ParseSections parses section specs: time ranges such as "12:00-18:00",
+// "1:02:03-1:05:00" or "90-120" (an empty or "end" end runs to the end of the
+// video), and chapter names prefixed with "chapter:". The result is sorted,
+// time ranges first, with duplicates removed, so equal selections compare
+// equal. No specs means the whole video.
+func ParseSections(specs []string) ([]Section, error) {
+	if len(specs) > maxSections {
+		return nil, fmt.Errorf("%w: at most %d sections", ErrInvalidSection, maxSections)
+	}
+	out := make([]Section, 0, len(specs))
+	seen := make(map[Section]bool, len(specs))
+	for _, spec := range specs {
+		s, err := parseSection(strings.TrimSpace(spec))
+		if err != nil {
+			return nil, err
+		}
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if (a.Chapter == "") != (b.Chapter == "") {
+			return a.Chapter == ""
+		}
+		if a.Chapter != b.Chapter {
+			return a.Chapter < b.Chapter
+		}
+		if a.Start != b.Start {
+			return a.Start < b.Start
+		}
+		// An open end sorts after every closed one.
+		return a.End != 0 && (b.End == 0 || a.End < b.End)
+	})
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
+
+func parseSection(spec string) (Section, error) {
+	if name, ok := strings.CutPrefix(spec, chapterPrefix); ok {
+		name = strings.TrimSpace(name)
+		if name == "" || len(name) > 200 || strings.ContainsFunc(name, func(r rune) bool { return r < 0x20 || r == 0x7f }) {
+			return Section{}, fmt.Errorf("%w: bad chapter name %q", ErrInvalidSection, spec)
+		}
+		return Section{Chapter: name}, nil
+	}
+	startStr, endStr, ok := strings.Cut(spec, "-")
+	if !ok {
+		return Section{}, fmt.Errorf("%w: %q is neither a time range nor chapter:<name>", ErrInvalidSection, spec)
+	}
+	var s Section
+	var err error
+	if startStr = strings.TrimSpace(startStr); startStr != "" {
+		if s.Start, err = parseTimestamp(startStr); err != nil {
+			return Section{}, fmt.Errorf("%w: %q: %v", ErrInvalidSection, spec, err)
+		}
+	}
+	if endStr = strings.TrimSpace(endStr); endStr != "" && endStr != "end" {
+		if s.End, err = parseTimestamp(endStr); err != nil {
+			return Section{}, fmt.Errorf("%w: %q: %v", ErrInvalidSection, spec, err)
+		}
+		if s.End <= s.Start {
+			return Section{}, fmt.Errorf("%w: %q ends before it starts", ErrInvalidSection, spec)
+		}
+	}
+	return s, nil
+}
+
+// parseTimestamp parses whole seconds written as "SS", "M:SS" or "H:MM:SS".
+func parseTimestamp(ts string) (time.Duration, error) {
+	parts := strings.Split(ts, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("bad timestamp %q", ts)
+	}
+	var sec int64
+	for i, p := range parts {
+		n, err := strconv.ParseInt(p, 10, 64)
+		if err != nil || n < 0 || (i > 0 && (len(p) != 2 || n >= 60)) {
+			return 0, fmt.Errorf("bad timestamp %q", ts)
+		}
+		sec = sec*60 + n
+	}
+	if sec > int64(100*time.Hour/time.Second) {
+		return 0, fmt.Errorf("timestamp %q is too large", ts)
+	}
+	return time.Duration(sec) * time.Second, nil
+}
+
+func formatTimestamp(d time.Duration) string {
+	sec := int64(d / time.Second)
+	if sec >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", sec/3600, sec/60%60, sec%60)
+	}
+	return fmt.Sprintf("%d:%02d", sec/60, sec%60)
+}
+
+// SectionSpecs returns the canonical specs of sections.
+func SectionSpecs(sections []Section) []string {
+	if len(sections) == 0 {
+		return nil
+	}
+	out := make([]string, len(sections))
+	for i, s := range sections {
+		out[i] = s.String()
+	}
+	return out
+}
+
+// sectionTemplate adds the section bounds to an output template ending in
+// ".%(ext)s", so clips never overwrite each other or the whole video.
+func sectionTemplate(tpl string) string {
+	i := strings.LastIndex(tpl, ".%(ext)s")
+	if i < 0 {
+		return tpl
+	}
+	return tpl[:i] + " [%(section_start)s-%(section_end)s]" + tpl[i:]
+}
@@ -0,0 +1,58 @@
+package download
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSections_Canonical(t *testing.T) {
+	got, err := ParseSections([]string{"chapter: Outro ", "1:02:03-", "12:00-18:00", "720-1080", "-0:30", "chapter:Intro"})
+	if err != nil {
+		t.Fatalf("ParseSections: %v", err)
+	}
+	want := []string{"0:00-0:30", "12:00-18:00", "1:02:03-end", "chapter:Intro", "chapter:Outro"}
+	if specs := SectionSpecs(got); !reflect.DeepEqual(specs, want) {
+		t.Fatalf("specs = %q, want %q", specs, want)
+	}
+	args := make([]string, len(got))
+	for i, s := range got {
+		args[i] = s.ytdlpArg()
+	}
+	wantArgs := []string{"*0-30", "*720-1080", "*3723-inf", "(?i)^Intro$", "(?i)^Outro$"}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Fatalf("yt-dlp args = %q, want %q", args, wantArgs)
+	}
+	if s, _ := ParseSections([]string{"chapter:Q&A (live)"}); s[0].ytdlpArg() != `(?i)^Q&A \(live\)$` {
+		t.Fatalf("chapter regex not escaped: %q", s[0].ytdlpArg())
+	}
+	if s, err := ParseSections(nil); s != nil || err != nil {
+		t.Fatalf("expected no sections for no specs, got %v %v", s, err)
+	}
+}
+
+func TestParseSections_Rejects(t *testing.T) {
+	for _, spec := range []string{"", "12:00", "18:00-12:00", "5-5", "1:60-2:00", "1:5-2:00", "a-b", "1:00:00:00-end", "chapter:", "chapter:\x01"} {
+		if _, err := ParseSections([]string{spec}); !errors.Is(err, ErrInvalidSection) {
+			t.Errorf("ParseSections(%q) err = %v, want ErrInvalidSection", spec, err)
+		}
+	}
+	if _, err := ParseSections(make([]string, maxSections+1)); !errors.Is(err, ErrInvalidSection) {
+		t.Fatalf("expected too many sections to be rejected, got %v", err)
+	}
+}
+
+func TestRunOptions_SectionArgsAndTemplate(t *testing.T) {
+	secs, _ := ParseSections([]string{"12:00-18:00"})
+	args := RunOptions{Sections: secs}.apply(buildYTDLPArgs("https://example.com/v", "%(title)s", "/out", "/tmp", true), "%(title)s")
+	if !strings.HasSuffix(strings.Join(args, " "), "--download-sections *720-1080") {
+		t.Fatalf("unexpected args %q", args)
+	}
+	if got := sectionTemplate(defaultOutputTemplate); got != "%(title).200s-%(id)s [%(section_start)s-%(section_end)s].%(ext)s" {
+		t.Fatalf("sectionTemplate = %q", got)
+	}
+	if got := sectionTemplate("%(title)s"); got != "%(title)s" {
+		t.Fatalf("template without extension should be unchanged, got %q", got)
+	}
+}
@@ -0,0 +1,153 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"videofetch/internal/logging"
+)
+
+const (
+	// maxStallRetries is how many times a stalled download is restarted before it fails.
+	maxStallRetries = 2
+	// stallRetryDelay separates a killed attempt from its restart.
+	stallRetryDelay = 5 * time.Second
+	// maxStallCheckInterval caps how often the watchdog looks at a job.
+	maxStallCheckInterval = 5 * time.Second
+)
+
+// stallRetryWait is swapped out in tests.
+var stallRetryWait = stallRetryDelay
+
+// SetStallTimeout sets how long a running download may go without progress or
+// output before it is killed and restarted. 0 disables the watchdog.
+func (m *Manager) SetStallTimeout(d time.Duration) {
+	m.stallTimeout.Store(int64(max(d, 0)))
+}
+
+// StallTimeout returns the watchdog threshold; 0 means disabled.
+func (m *Manager) StallTimeout() time.Duration {
+	return time.Duration(m.stallTimeout.Load())
+}
+
+// noteActivity records a line of yt-dlp output. Progress lines count only
+// when the byte count moves, so a repeated stuck progress line is not
+// mistaken for life; any other line is.
+func (m *Manager) noteActivity(id string, downloadedBytes float64) {
+	m.activeMu.Lock()
+	defer m.activeMu.Unlock()
+	entry, ok := m.activeByID[id]
+	if !ok {
+		return
+	}
+	if downloadedBytes >= 0 {
+		if downloadedBytes == entry.lastBytes {
+			return
+		}
+		entry.lastBytes = downloadedBytes
+	}
+	entry.lastActivity = time.Now()
+}
+
+// stallFor returns how long a running download has been idle.
+func (m *Manager) stallFor(id string) (time.Duration, bool) {
+	m.activeMu.Lock()
+	defer m.activeMu.Unlock()
+	entry, ok := m.activeByID[id]
+	if !ok {
+		return 0, false
+	}
+	return time.Since(entry.lastActivity), true
+}
+
+// resetActivity restarts the idle clock, e.g. for a new attempt.
+func (m *Manager) resetActivity(id string) {
+	m.activeMu.Lock()
+	defer m.activeMu.Unlock()
+	if entry, ok := m.activeByID[id]; ok {
+		entry.lastActivity = time.Now()
+		entry.lastBytes = -1
+	}
+}
+
+// fillStallTimes sets StallSeconds on running items.
+func (m *Manager) fillStallTimes(items []*Item) {
+	now := time.Now()
+	m.activeMu.Lock()
+	defer m.activeMu.Unlock()
+	for _, it := range items {
+		if entry, ok := m.activeByID[it.ID]; ok && it.State == StateDownloading {
+			it.StallSeconds = int64(now.Sub(entry.lastActivity) / time.Second)
+		}
+	}
+}
+
+// downloadWatched runs fn under the stall watchdog. A stalled attempt is
+// killed and restarted (yt-dlp continues partial files) up to
+// maxStallRetries times; after that the job fails with ErrStalled.
+func (m *Manager) downloadWatched(ctx context.Context, id, url string, fn func(ctx context.Context, id, url string) error) error {
+	threshold := m.StallTimeout()
+	if threshold <= 0 {
+		return fn(ctx, id, url)
+	}
+	for attempt := 1; ; attempt++ {
+		m.resetActivity(id)
+		attemptCtx, cancel := context.WithCancel(ctx)
+		stalled := make(chan bool, 1)
+		go m.watchStall(attemptCtx, id, threshold, cancel, stalled)
+		err := fn(attemptCtx, id, url)
+		cancel()
+		if !<-stalled || err == nil || ctx.Err() != nil {
+			return err
+		}
+
+		idle, _ := m.stallFor(id)
+		if attempt > maxStallRetries {
+			slog.Warn("download stalled; giving up",
+				"event", "download_stalled",
+				"id", id,
+				"url", logging.RedactURL(url),
+				"attempts", attempt,
+				"stall_ms", idle.Milliseconds())
+			return fmt.Errorf("%w: no progress or output for %s", ErrStalled, threshold)
+		}
+		slog.Warn("download stalled; restarting",
+			"event", "download_stall_retry",
+			"id", id,
+			"url", logging.RedactURL(url),
+			"attempt", attempt,
+			"max_attempts", maxStallRetries+1,
+			"stall_ms", idle.Milliseconds())
+
+		timer := time.NewTimer(stallRetryWait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// watchStall cancels the attempt once it has been idle for threshold and
+// reports on stalled whether it did.
+func (m *Manager) watchStall(ctx context.Context, id string, threshold time.Duration, cancel context.CancelFunc, stalled chan<- bool) {
+	interval := min(max(threshold/10, 10*time.Millisecond), maxStallCheckInterval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			stalled <- false
+			return
+		case <-ticker.C:
+			if idle, ok := m.stallFor(id); ok && idle >= threshold {
+				cancel()
+				stalled <- true
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package download
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newStallTestManager(t *testing.T, timeout time.Duration) *Manager {
+	t.Helper()
+	orig := stallRetryWait
+	stallRetryWait = 0
+	t.Cleanup(func() { stallRetryWait = orig })
+	m := NewManager(t.TempDir(), 1, 4)
+	t.Cleanup(m.Shutdown)
+	m.SetStallTimeout(timeout)
+	return m
+}
+
+func TestStallWatchdog_RestartsThenSucceeds(t *testing.T) {
+	m := newStallTestManager(t, 50*time.Millisecond)
+	var attempts atomic.Int32
+	m.workerDownload = func(ctx context.Context, id, url string) error {
+		if attempts.Add(1) == 1 {
+			<-ctx.Done() // hung: no output at all
+			return ctx.Err()
+		}
+		return nil
+	}
+
+	id, err := m.Enqueue("https://example.com/video")
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	waitForItem(t, m, id, func(it *Item) bool { return it.State == StateCompleted })
+	if n := attempts.Load(); n != 2 {
+		t.Fatalf("expected one restart, got %d attempts", n)
+	}
+}
+
+func TestStallWatchdog_FailsAsStalledAfterRetries(t *testing.T) {
+	m := newStallTestManager(t, 30*time.Millisecond)
+	var attempts atomic.Int32
+	m.workerDownload = func(ctx context.Context, id, url string) error {
+		attempts.Add(1)
+		// A progress line that never advances does not count as activity.
+		for {
+			m.noteActivity(id, 1024)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(5 * time.Millisecond):
+			}
+		}
+	}
+
+	id, err := m.Enqueue("https://example.com/video")
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	it := waitForItem(t, m, id, func(it *Item) bool { return it.State == StateFailed })
+	if !strings.HasPrefix(it.Error, "stalled: ") {
+		t.Fatalf("expected stalled error class, got %q", it.Error)
+	}
+	if n := attempts.Load(); n != maxStallRetries+1 {
+		t.Fatalf("expected %d attempts, got %d", maxStallRetries+1, n)
+	}
+}
+
+func TestStallWatchdog_ActivityKeepsJobAlive(t *testing.T) {
+	m := newStallTestManager(t, 60*time.Millisecond)
+	var attempts atomic.Int32
+	m.workerDownload = func(ctx context.Context, id, url string) error {
+		attempts.Add(1)
+		for i := 0; i < 20; i++ {
+			if i%2 == 0 {
+				m.noteActivity(id, float64(i*1024))
+			} else {
+				m.noteActivity(id, -1) // e.g. a [Merger] line
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(15 * time.Millisecond):
+			}
+		}
+		return nil
+	}
+
+	id, err := m.Enqueue("https://example.com/video")
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	waitForItem(t, m, id, func(it *Item) bool { return it.State == StateCompleted })
+	if n := attempts.Load(); n != 1 {
+		t.Fatalf("expected no restart, got %d attempts", n)
+	}
+}
+
+func TestStallWatchdog_PauseDuringStallIsNotRetried(t *testing.T) {
+	m := newStallTestManager(t, time.Hour)
+	started := make(chan struct{}, 1)
+	m.workerDownload = func(ctx context.Context, id, url string) error {
+		started <- struct{}{}
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	id, err := m.Enqueue("https://example.com/video")
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	m.AttachDB(id, 3)
+	<-started
+
+	// The idle time is reported while the job runs.
+	m.activeMu.Lock()
+	m.activeByID[id].lastActivity = time.Now().Add(-90 * time.Second)
+	m.activeMu.Unlock()
+	if items := m.Snapshot(id); len(items) != 1 || items[0].StallSeconds < 90 {
+		t.Fatalf("expected stall time in snapshot, got %+v", items)
+	}
+
+	if !m.PauseByDBID(3) {
+		t.Fatalf("expected pause to apply")
+	}
+	waitForItem(t, m, id, func(it *Item) bool { return it.State == StatePaused })
+	if items := m.Snapshot(id); items[0].StallSeconds != 0 {
+		t.Fatalf("expected no stall time for paused item, got %d", items[0].StallSeconds)
+	}
+}
+
+func TestParseProgress_ReportsActivity(t *testing.T) {
+	d := NewDownloader(t.TempDir())
+	var got []float64
+	d.SetActivityCallback(func(id string, downloadedBytes float64) { got = append(got, downloadedBytes) })
+
+	cmd := exec.Command("sh", "-c", `echo '[youtube] abc: Downloading webpage'; echo '{"status":"downloading","downloaded_bytes":2048,"total_bytes":4096}'`)
+	if err := d.executeWithProgressTracking("id", cmd); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(got) != 2 || got[0] != -1 || got[1] != 2048 {
+		t.Fatalf("unexpected activity reports %v", got)
+	}
+}
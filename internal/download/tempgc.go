@@ -0,0 +1,289 @@
+package download
+
+import (
+	"context"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const tempRootName = ".yt-dlp-tmp"
+
+// ArtifactStore lists files the database still expects to exist for unfinished rows.
+type ArtifactStore interface {
+	ListLiveArtifactPaths(ctx context.Context) ([]string, error)
+}
+
+// OrphanEntry is a temp directory or partial file removed (or found) by the janitor.
+type OrphanEntry struct {
+	Path      string    `json:"path"`
+	Kind      string    `json:"kind"` // temp_dir|partial
+	SizeBytes int64     `json:"size_bytes"`
+	ModTime   time.Time `json:"mod_time"`
+}
+
+// TempGCReport summarizes one janitor pass.
+type TempGCReport struct {
+	StartedAt      time.Time     `json:"started_at"`
+	FinishedAt     time.Time     `json:"finished_at"`
+	Removed        []OrphanEntry `json:"removed"`
+	KeptLive       int           `json:"kept_live"`
+	KeptGrace      int           `json:"kept_grace"`
+	ReclaimedBytes int64         `json:"reclaimed_bytes"`
+	Errors         []string      `json:"errors,omitempty"`
+}
+
+// TempJanitor removes per-job temp directories and partial files that no
+// longer belong to a live registry item or unfinished database row.
+type TempJanitor struct {
+	manager *Manager
+	store   ArtifactStore
+	grace   time.Duration
+
+	runMu sync.Mutex
+	mu    sync.RWMutex
+	last  *TempGCReport
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewTempJanitor creates a janitor for the manager's library roots.
+// Orphans younger than grace are left alone in case a writer is still attaching.
+func NewTempJanitor(manager *Manager, store ArtifactStore, grace time.Duration) *TempJanitor {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &TempJanitor{
+		manager: manager,
+		store:   store,
+		grace:   grace,
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+}
+
+// Start runs one pass immediately and then every interval.
+func (tj *TempJanitor) Start(interval time.Duration) {
+	go tj.run(interval)
+}
+
+// Stop stops the background loop.
+func (tj *TempJanitor) Stop() {
+	tj.cancel()
+	<-tj.done
+}
+
+func (tj *TempJanitor) run(interval time.Duration) {
+	defer close(tj.done)
+	tj.RunOnce(tj.ctx)
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-tj.ctx.Done():
+			return
+		case <-ticker.C:
+			tj.RunOnce(tj.ctx)
+		}
+	}
+}
+
+// LastReport returns the most recent pass, or nil if none has run yet.
+func (tj *TempJanitor) LastReport() *TempGCReport {
+	tj.mu.RLock()
+	defer tj.mu.RUnlock()
+	return tj.last
+}
+
+// RunOnce scans every library root and removes orphans older than the grace period.
+func (tj *TempJanitor) RunOnce(ctx context.Context) TempGCReport {
+	tj.runMu.Lock()
+	defer tj.runMu.Unlock()
+
+	report := TempGCReport{StartedAt: time.Now().UTC(), Removed: []OrphanEntry{}}
+	liveIDs, livePaths := tj.manager.liveTempState()
+	if tj.store != nil {
+		paths, err := tj.store.ListLiveArtifactPaths(ctx)
+		if err != nil {
+			// Without DB state we cannot tell partials apart; only sweep temp dirs.
+			report.Errors = append(report.Errors, "list live artifacts: "+err.Error())
+			livePaths = nil
+		} else {
+			for _, p := range paths {
+				livePaths[tj.manager.absArtifactPath(p)] = struct{}{}
+			}
+		}
+	}
+	cutoff := report.StartedAt.Add(-tj.grace)
+
+	roots := tj.manager.libraryRoots()
+	for _, root := range roots {
+		tj.sweepTempDirs(&report, root, liveIDs, cutoff)
+	}
+	if livePaths != nil {
+		skip := make(map[string]struct{}, 2*len(roots))
+		for _, root := range roots {
+			skip[filepath.Clean(root)] = struct{}{}
+			skip[filepath.Join(filepath.Clean(root), tempRootName)] = struct{}{}
+		}
+		for _, root := range roots {
+			tj.sweepPartials(&report, root, skip, livePaths, cutoff)
+		}
+	}
+
+	report.FinishedAt = time.Now().UTC()
+	tj.mu.Lock()
+	tj.last = &report
+	tj.mu.Unlock()
+
+	if len(report.Removed) > 0 || len(report.Errors) > 0 {
+		slog.Info("temp janitor pass finished",
+			"event", "tempgc_done",
+			"removed", len(report.Removed),
+			"reclaimed_bytes", report.ReclaimedBytes,
+			"errors", len(report.Errors))
+	}
+	return report
+}
+
+func (tj *TempJanitor) sweepTempDirs(report *TempGCReport, libRoot string, liveIDs map[string]struct{}, cutoff time.Time) {
+	root := filepath.Join(libRoot, tempRootName)
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			report.Errors = append(report.Errors, err.Error())
+		}
+		return
+	}
+	for _, e := range entries {
+		path := filepath.Join(root, e.Name())
+		if _, live := liveIDs[e.Name()]; live {
+			report.KeptLive++
+			continue
+		}
+		size, newest := treeUsage(path)
+		if newest.After(cutoff) {
+			report.KeptGrace++
+			continue
+		}
+		if err := os.RemoveAll(path); err != nil {
+			report.Errors = append(report.Errors, err.Error())
+			continue
+		}
+		report.Removed = append(report.Removed, OrphanEntry{Path: path, Kind: "temp_dir", SizeBytes: size, ModTime: newest})
+		report.ReclaimedBytes += size
+	}
+}
+
+// sweepPartials walks one library root. Other library roots and every temp
+// root are skipped, so nested libraries are swept once and job directories
+// are left to sweepTempDirs.
+func (tj *TempJanitor) sweepPartials(report *TempGCReport, root string, skip, livePaths map[string]struct{}, cutoff time.Time) {
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if _, ok := skip[filepath.Clean(path)]; ok && filepath.Clean(path) != filepath.Clean(root) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isPartialFile(d.Name()) {
+			return nil
+		}
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil
+		}
+		if _, live := livePaths[abs]; live {
+			report.KeptLive++
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if info.ModTime().After(cutoff) {
+			report.KeptGrace++
+			return nil
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			report.Errors = append(report.Errors, err.Error())
+			return nil
+		}
+		report.Removed = append(report.Removed, OrphanEntry{Path: path, Kind: "partial", SizeBytes: info.Size(), ModTime: info.ModTime()})
+		report.ReclaimedBytes += info.Size()
+		return nil
+	})
+}
+
+// liveTempState returns IDs whose temp dir may still be used (anything that
+// can run or be resumed) and the absolute artifact paths tracked in memory.
+func (m *Manager) liveTempState() (map[string]struct{}, map[string]struct{}) {
+	ids := make(map[string]struct{})
+	for _, it := range m.registry.Snapshot("") {
+		if it.State == StateCompleted || it.State == StateCanceled {
+			continue
+		}
+		ids[it.ID] = struct{}{}
+	}
+
+	paths := make(map[string]struct{})
+	m.artifactMu.Lock()
+	for _, set := range m.artifacts {
+		for p := range set {
+			paths[m.absArtifactPath(p)] = struct{}{}
+		}
+	}
+	m.artifactMu.Unlock()
+	return ids, paths
+}
+
+func (m *Manager) absArtifactPath(p string) string {
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(m.outDir, p)
+	}
+	if abs, err := filepath.Abs(p); err == nil {
+		return abs
+	}
+	return filepath.Clean(p)
+}
+
+// isPartialFile matches yt-dlp's in-progress outputs.
+func isPartialFile(name string) bool {
+	return strings.HasSuffix(name, ".part") ||
+		strings.HasSuffix(name, ".ytdl") ||
+		strings.Contains(name, ".part-Frag")
+}
+
+// treeUsage returns the total size and newest modification time under path.
+func treeUsage(path string) (int64, time.Time) {
+	var size int64
+	var newest time.Time
+	_ = filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, newest
+}
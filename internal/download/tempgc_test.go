@@ -0,0 +1,122 @@
+package download
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type fakeArtifactStore struct {
+	paths []string
+	err   error
+}
+
+func (f fakeArtifactStore) ListLiveArtifactPaths(ctx context.Context) ([]string, error) {
+	return f.paths, f.err
+}
+
+func writeAged(t *testing.T, path string, size int, age time.Duration) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, make([]byte, size), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	ts := time.Now().Add(-age)
+	if err := os.Chtimes(path, ts, ts); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+}
+
+func TestTempJanitor_RemovesOnlyOldOrphans(t *testing.T) {
+	outDir := t.TempDir()
+	m := &Manager{outDir: outDir, registry: NewItemRegistry(8)}
+
+	if _, err := m.registry.Create("live1", "https://example.com/live"); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	tmp := filepath.Join(outDir, tempRootName)
+	writeAged(t, filepath.Join(tmp, "live1", "video.part"), 10, 3*time.Hour)
+	writeAged(t, filepath.Join(tmp, "dead1", "video.part"), 100, 3*time.Hour)
+	writeAged(t, filepath.Join(tmp, "fresh1", "video.part"), 10, time.Minute)
+	writeAged(t, filepath.Join(outDir, "orphan.mp4.part"), 50, 3*time.Hour)
+	writeAged(t, filepath.Join(outDir, "resumable.mp4.part"), 10, 3*time.Hour)
+	writeAged(t, filepath.Join(outDir, "done.mp4"), 10, 3*time.Hour)
+	// Directory mtimes count as activity too.
+	old := time.Now().Add(-3 * time.Hour)
+	for _, dir := range []string{"live1", "dead1"} {
+		if err := os.Chtimes(filepath.Join(tmp, dir), old, old); err != nil {
+			t.Fatalf("chtimes: %v", err)
+		}
+	}
+
+	tj := NewTempJanitor(m, fakeArtifactStore{paths: []string{"resumable.mp4.part"}}, time.Hour)
+	report := tj.RunOnce(context.Background())
+
+	if len(report.Removed) != 2 || report.ReclaimedBytes != 150 {
+		t.Fatalf("expected dead temp dir and orphan partial removed (150 bytes), got %+v", report)
+	}
+	for _, p := range []string{filepath.Join(tmp, "dead1"), filepath.Join(outDir, "orphan.mp4.part")} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Fatalf("expected %s removed, stat err=%v", p, err)
+		}
+	}
+	for _, p := range []string{filepath.Join(tmp, "live1"), filepath.Join(tmp, "fresh1"), filepath.Join(outDir, "resumable.mp4.part"), filepath.Join(outDir, "done.mp4")} {
+		if _, err := os.Stat(p); err != nil {
+			t.Fatalf("expected %s kept: %v", p, err)
+		}
+	}
+	if report.KeptLive != 2 || report.KeptGrace != 1 {
+		t.Fatalf("expected 2 live and 1 grace keeps, got live=%d grace=%d", report.KeptLive, report.KeptGrace)
+	}
+	if last := tj.LastReport(); last == nil || last.ReclaimedBytes != 150 {
+		t.Fatalf("expected last report recorded, got %+v", last)
+	}
+}
+
+func TestTempJanitor_StoreErrorSkipsPartials(t *testing.T) {
+	outDir := t.TempDir()
+	m := &Manager{outDir: outDir, registry: NewItemRegistry(8)}
+	writeAged(t, filepath.Join(outDir, "maybe-live.mp4.part"), 10, 3*time.Hour)
+
+	tj := NewTempJanitor(m, fakeArtifactStore{err: os.ErrPermission}, time.Hour)
+	report := tj.RunOnce(context.Background())
+
+	if len(report.Errors) != 1 || len(report.Removed) != 0 {
+		t.Fatalf("expected error recorded and nothing removed, got %+v", report)
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "maybe-live.mp4.part")); err != nil {
+		t.Fatalf("expected partial kept when DB state is unknown: %v", err)
+	}
+}
+
+func TestTempJanitor_SweepsEveryLibraryRoot(t *testing.T) {
+	outDir := t.TempDir()
+	nested := filepath.Join(outDir, "music")
+	m := &Manager{outDir: outDir, registry: NewItemRegistry(8)}
+	if err := m.SetLibraries([]Library{{Name: "music", Root: nested}}); err != nil {
+		t.Fatalf("set libraries: %v", err)
+	}
+
+	writeAged(t, filepath.Join(nested, tempRootName, "dead1", "song.part"), 10, 3*time.Hour)
+	writeAged(t, filepath.Join(nested, "orphan.opus.part"), 20, 3*time.Hour)
+	writeAged(t, filepath.Join(nested, "live.opus.part"), 5, 3*time.Hour)
+	old := time.Now().Add(-3 * time.Hour)
+	if err := os.Chtimes(filepath.Join(nested, tempRootName, "dead1"), old, old); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+
+	live := fakeArtifactStore{paths: []string{filepath.Join(nested, "live.opus.part")}}
+	report := NewTempJanitor(m, live, time.Hour).RunOnce(context.Background())
+
+	if len(report.Removed) != 2 || report.ReclaimedBytes != 30 || report.KeptLive != 1 {
+		t.Fatalf("expected nested library swept once, got %+v", report)
+	}
+	if _, err := os.Stat(filepath.Join(nested, "live.opus.part")); err != nil {
+		t.Fatalf("expected live partial kept: %v", err)
+	}
+}
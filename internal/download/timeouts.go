@@ -0,0 +1,36 @@
+package download
+
+import "time"
+
+// SetMaxJobDuration caps the wall-clock time of every job that has no
+// per-request or per-library limit. 0 means unlimited.
+func (m *Manager) SetMaxJobDuration(d time.Duration) {
+	m.maxJobDuration.Store(int64(max(d, 0)))
+}
+
+// SetMetadataTimeout bounds each metadata probe attempt; 0 restores DefaultMetadataTimeout.
+func (m *Manager) SetMetadataTimeout(d time.Duration) {
+	m.metadataTimeout.Store(int64(max(d, 0)))
+}
+
+// MetadataTimeout returns the limit for one metadata probe attempt.
+func (m *Manager) MetadataTimeout() time.Duration {
+	if d := time.Duration(m.metadataTimeout.Load()); d > 0 {
+		return d
+	}
+	return DefaultMetadataTimeout
+}
+
+// jobDuration resolves the time limit of a job: the request's own limit,
+// then its library's, then the manager-wide one. 0 means unlimited.
+func (m *Manager) jobDuration(it *Item) time.Duration {
+	if it != nil {
+		if it.maxDuration > 0 {
+			return it.maxDuration
+		}
+		if lib, ok := m.Library(it.Library); ok && lib.MaxDuration > 0 {
+			return lib.MaxDuration
+		}
+	}
+	return time.Duration(m.maxJobDuration.Load())
+}
@@ -0,0 +1,114 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestJobTimeout_FailsWithTimeoutClass(t *testing.T) {
+	m := newStallTestManager(t, time.Hour)
+	m.SetMaxJobDuration(40 * time.Millisecond)
+	var attempts atomic.Int32
+	m.workerDownload = func(ctx context.Context, id, url string) error {
+		attempts.Add(1)
+		for {
+			m.noteActivity(id, float64(attempts.Load())) // busy but never finishing
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(5 * time.Millisecond):
+			}
+		}
+	}
+
+	id, err := m.Enqueue("https://example.com/video")
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	it := waitForItem(t, m, id, func(it *Item) bool { return it.State == StateFailed })
+	if !strings.HasPrefix(it.Error, "timeout: job exceeded 40ms") {
+		t.Fatalf("expected timeout error class, got %q", it.Error)
+	}
+	if it.ErrorClass != "timeout" {
+		t.Fatalf("expected error_class timeout, got %q", it.ErrorClass)
+	}
+	if n := attempts.Load(); n != 1 {
+		t.Fatalf("expected timed-out job not to be restarted, got %d attempts", n)
+	}
+}
+
+func TestJobTimeout_LeavesBreakerClosed(t *testing.T) {
+	m := newStallTestManager(t, time.Hour)
+	m.SetMaxJobDuration(20 * time.Millisecond)
+	m.SetCircuitBreaker(1, time.Hour)
+	m.workerDownload = func(ctx context.Context, id, url string) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	for _, u := range []string{"https://example.com/1", "https://example.com/2"} {
+		id, err := m.Enqueue(u)
+		if err != nil {
+			t.Fatalf("enqueue: %v", err)
+		}
+		it := waitForItem(t, m, id, func(it *Item) bool { return it.State == StateFailed })
+		if it.ErrorClass != "timeout" {
+			t.Fatalf("expected error class timeout, got %q (%s)", it.ErrorClass, it.Error)
+		}
+	}
+	for _, b := range m.Breakers() {
+		if b.Host == "example.com" && b.State != BreakerClosed {
+			t.Fatalf("expected timed-out jobs to leave the breaker closed, got %+v", b)
+		}
+	}
+}
+
+func TestJobDuration_Precedence(t *testing.T) {
+	m := NewManager(t.TempDir(), 1, 4)
+	defer m.Shutdown()
+	if err := m.SetLibraries([]Library{{Name: "podcasts", Root: t.TempDir(), MaxDuration: time.Hour}}); err != nil {
+		t.Fatalf("set libraries: %v", err)
+	}
+	m.SetMaxJobDuration(2 * time.Hour)
+
+	cases := []struct {
+		item *Item
+		want time.Duration
+	}{
+		{&Item{Library: DefaultLibraryName}, 2 * time.Hour},
+		{&Item{Library: "podcasts"}, time.Hour},
+		{&Item{Library: "podcasts", maxDuration: time.Minute}, time.Minute},
+	}
+	for _, c := range cases {
+		if got := m.jobDuration(c.item); got != c.want {
+			t.Fatalf("jobDuration(%+v) = %s, want %s", c.item, got, c.want)
+		}
+	}
+}
+
+func TestFetchMediaInfo_AttemptTimeout(t *testing.T) {
+	m := NewManager(t.TempDir(), 1, 4)
+	defer m.Shutdown()
+	m.SetMetadataTimeout(20 * time.Millisecond)
+
+	origFetch := fetchMediaInfo
+	t.Cleanup(func() { fetchMediaInfo = origFetch })
+	var attempts atomic.Int32
+	fetchMediaInfo = func(ctx context.Context, inputURL string) (MediaInfo, error) {
+		attempts.Add(1)
+		<-ctx.Done()
+		return MediaInfo{}, ctx.Err()
+	}
+
+	_, err := m.fetchMediaInfoWithRetry(context.Background(), "https://example.com/video", 1)
+	if !errors.Is(err, ErrTimeout) || !strings.Contains(err.Error(), "metadata probe exceeded 20ms") {
+		t.Fatalf("expected metadata timeout, got %v", err)
+	}
+	if n := attempts.Load(); n != pendingMetadataMaxAttempts {
+		t.Fatalf("expected timeouts retried %d times, got %d", pendingMetadataMaxAttempts, n)
+	}
+}
@@ -0,0 +1,406 @@
+package download
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ReasonYTDLPUpdate is the health condition raised when failures look like extractor breakage.
+const ReasonYTDLPUpdate = "ytdlp_update_recommended"
+
+const (
+	ytdlpReleaseBase    = "https://github.com/yt-dlp/yt-dlp/releases/latest/download/"
+	ytdlpMaxBinaryBytes = 256 << 20
+	ytdlpUpdateTimeout  = 5 * time.Minute
+	breakageWindow      = time.Hour
+	breakageThreshold   = 3
+	breakageSampleLimit = 5
+	ytdlpVersionTimeout = 15 * time.Second
+)
+
+// Update methods accepted by YTDLPUpdater.Update.
+const (
+	UpdateMethodRelease = "release" // download the latest release binary into the managed path
+	UpdateMethodSelf    = "self"    // run the current binary with -U
+)
+
+// breakageSignatures are substrings yt-dlp prints when an extractor no longer
+// matches the site, as opposed to per-video problems like private or removed media.
+var breakageSignatures = []string{
+	"please report this issue on",
+	"confirm you are on the latest version",
+	"unable to extract",
+	"nsig extraction failed",
+	"signature extraction failed",
+	"some formats may be missing",
+}
+
+var ytdlpPath atomic.Value // string
+
+// YTDLPPath returns the yt-dlp binary used for every invocation.
+func YTDLPPath() string {
+	if p, ok := ytdlpPath.Load().(string); ok && p != "" {
+		return p
+	}
+	return "yt-dlp"
+}
+
+// SetYTDLPPath switches the yt-dlp binary for subsequent invocations; running jobs keep theirs.
+func SetYTDLPPath(path string) {
+	ytdlpPath.Store(path)
+}
+
+// YTDLPVersion runs `yt-dlp --version` with the current binary.
+func YTDLPVersion(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, ytdlpVersionTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, YTDLPPath(), "--version").Output()
+	if err != nil {
+		return "", fmt.Errorf("yt-dlp --version: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// IsExtractorBreakage reports whether a failure message matches a known extractor-breakage signature.
+func IsExtractorBreakage(msg string) bool {
+	lower := strings.ToLower(msg)
+	for _, sig := range breakageSignatures {
+		if strings.Contains(lower, sig) {
+			return true
+		}
+	}
+	return false
+}
+
+// YTDLPStatus describes the yt-dlp binary in use and whether an update is recommended.
+type YTDLPStatus struct {
+	Path              string             `json:"path"`
+	Version           string             `json:"version"`
+	Managed           bool               `json:"managed"`
+	CheckedAt         time.Time          `json:"checked_at"`
+	UpdateRecommended bool               `json:"update_recommended"`
+	RecentBreakages   int                `json:"recent_breakages"`
+	BreakageSamples   []string           `json:"breakage_samples,omitempty"`
+	Updating          bool               `json:"updating"`
+	LastUpdate        *YTDLPUpdateResult `json:"last_update,omitempty"`
+}
+
+// YTDLPUpdateResult records the outcome of one update attempt.
+type YTDLPUpdateResult struct {
+	Method      string    `json:"method"`
+	FromVersion string    `json:"from_version"`
+	ToVersion   string    `json:"to_version,omitempty"`
+	Path        string    `json:"path"`
+	Output      string    `json:"output,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	FinishedAt  time.Time `json:"finished_at"`
+}
+
+// YTDLPUpdater tracks the yt-dlp version, updates it in place and watches for
+// extractor-breakage failures across jobs.
+type YTDLPUpdater struct {
+	managedPath string
+	releaseURL  string
+	client      *http.Client
+	now         func() time.Time
+
+	updating atomic.Bool
+
+	mu         sync.Mutex
+	version    string
+	checkedAt  time.Time
+	lastUpdate *YTDLPUpdateResult
+	breakages  []breakage
+}
+
+type breakage struct {
+	at  time.Time
+	msg string
+}
+
+// NewYTDLPUpdater creates an updater that installs release binaries into
+// managedDir. A previously installed managed binary is adopted immediately so
+// updates survive restarts.
+func NewYTDLPUpdater(managedDir string) *YTDLPUpdater {
+	u := &YTDLPUpdater{
+		managedPath: filepath.Join(managedDir, ytdlpBinaryName()),
+		releaseURL:  ytdlpReleaseBase + ytdlpReleaseAsset(),
+		client:      &http.Client{Timeout: ytdlpUpdateTimeout},
+		now:         time.Now,
+	}
+	if info, err := os.Stat(u.managedPath); err == nil && info.Mode().IsRegular() {
+		SetYTDLPPath(u.managedPath)
+	}
+	return u
+}
+
+// Refresh re-reads the version of the binary in use.
+func (u *YTDLPUpdater) Refresh(ctx context.Context) (string, error) {
+	v, err := YTDLPVersion(ctx)
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.checkedAt = u.now().UTC()
+	if err != nil {
+		return u.version, err
+	}
+	u.version = v
+	return v, nil
+}
+
+// Status returns the current version and breakage signal.
+func (u *YTDLPUpdater) Status() YTDLPStatus {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.pruneBreakagesLocked()
+	st := YTDLPStatus{
+		Path:              YTDLPPath(),
+		Version:           u.version,
+		Managed:           YTDLPPath() == u.managedPath,
+		CheckedAt:         u.checkedAt,
+		RecentBreakages:   len(u.breakages),
+		UpdateRecommended: len(u.breakages) >= breakageThreshold,
+		Updating:          u.updating.Load(),
+		LastUpdate:        u.lastUpdate,
+	}
+	for i := len(u.breakages) - 1; i >= 0 && len(st.BreakageSamples) < breakageSampleLimit; i-- {
+		st.BreakageSamples = append(st.BreakageSamples, u.breakages[i].msg)
+	}
+	return st
+}
+
+// ObserveFailure records a job failure; only extractor-breakage signatures count.
+func (u *YTDLPUpdater) ObserveFailure(msg string) {
+	if !IsExtractorBreakage(msg) {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.breakages = append(u.breakages, breakage{at: u.now(), msg: truncateUTF8(msg, 200)})
+	u.pruneBreakagesLocked()
+	if len(u.breakages) == breakageThreshold {
+		slog.Warn("repeated extractor failures; yt-dlp update recommended",
+			"event", "ytdlp_update_recommended",
+			"version", u.version,
+			"failures", len(u.breakages))
+	}
+}
+
+func (u *YTDLPUpdater) pruneBreakagesLocked() {
+	cutoff := u.now().Add(-breakageWindow)
+	keep := u.breakages[:0]
+	for _, b := range u.breakages {
+		if b.at.After(cutoff) {
+			keep = append(keep, b)
+		}
+	}
+	u.breakages = keep
+}
+
+// Update installs a newer yt-dlp using method and switches future jobs to it.
+// Only one update runs at a time; concurrent callers get ErrUpdateInProgress.
+func (u *YTDLPUpdater) Update(ctx context.Context, method string) (YTDLPUpdateResult, error) {
+	if method == "" {
+		method = UpdateMethodRelease
+	}
+	if method != UpdateMethodRelease && method != UpdateMethodSelf {
+		return YTDLPUpdateResult{}, ErrUnknownUpdateMethod
+	}
+	if !u.updating.CompareAndSwap(false, true) {
+		return YTDLPUpdateResult{}, ErrUpdateInProgress
+	}
+	defer u.updating.Store(false)
+
+	ctx, cancel := context.WithTimeout(ctx, ytdlpUpdateTimeout)
+	defer cancel()
+
+	u.mu.Lock()
+	res := YTDLPUpdateResult{Method: method, FromVersion: u.version}
+	u.mu.Unlock()
+
+	var err error
+	switch method {
+	case UpdateMethodSelf:
+		res.Path = YTDLPPath()
+		res.Output, err = runSelfUpdate(ctx, res.Path)
+	case UpdateMethodRelease:
+		res.Path = u.managedPath
+		err = u.installRelease(ctx)
+	}
+	if err == nil {
+		err = CheckYTDLP()
+	}
+	if err == nil {
+		res.ToVersion, err = u.Refresh(ctx)
+	}
+	res.FinishedAt = u.now().UTC()
+	if err != nil {
+		res.Error = err.Error()
+	}
+
+	u.mu.Lock()
+	u.lastUpdate = &res
+	if err == nil {
+		u.breakages = nil
+	}
+	u.mu.Unlock()
+
+	if err != nil {
+		slog.Error("yt-dlp update failed", "event", "ytdlp_update_error", "method", method, "error", err)
+		return res, err
+	}
+	slog.Info("yt-dlp updated",
+		"event", "ytdlp_updated",
+		"method", method,
+		"from", res.FromVersion,
+		"to", res.ToVersion,
+		"path", res.Path)
+	return res, nil
+}
+
+func runSelfUpdate(ctx context.Context, path string) (string, error) {
+	out, err := exec.CommandContext(ctx, path, "-U").CombinedOutput()
+	tail := tailString(string(out), 1024)
+	if err != nil {
+		return tail, fmt.Errorf("yt-dlp -U: %w", err)
+	}
+	return tail, nil
+}
+
+// installRelease downloads the release binary for this platform, verifies it
+// against the published SHA2-256SUMS, checks that it runs and atomically
+// replaces the managed binary.
+func (u *YTDLPUpdater) installRelease(ctx context.Context) error {
+	if err := os.MkdirAll(filepath.Dir(u.managedPath), 0o755); err != nil {
+		return fmt.Errorf("create managed dir: %w", err)
+	}
+	want, err := u.fetchChecksum(ctx)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(u.managedPath), ".yt-dlp-update-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	body, err := u.get(ctx, u.releaseURL)
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tmp, h), io.LimitReader(body, ytdlpMaxBinaryBytes+1))
+	body.Close()
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("download release: %w", err)
+	}
+	if n > ytdlpMaxBinaryBytes {
+		return fmt.Errorf("download release: binary exceeds %d bytes", ytdlpMaxBinaryBytes)
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(got, want) {
+		return fmt.Errorf("download release: checksum mismatch (got %s, want %s)", got, want)
+	}
+	if err := os.Chmod(tmpPath, 0o755); err != nil {
+		return err
+	}
+	if out, err := exec.CommandContext(ctx, tmpPath, "--version").CombinedOutput(); err != nil {
+		return fmt.Errorf("downloaded binary not runnable: %w: %s", err, tailString(string(out), 256))
+	}
+	if err := os.Rename(tmpPath, u.managedPath); err != nil {
+		return fmt.Errorf("install release: %w", err)
+	}
+	SetYTDLPPath(u.managedPath)
+	return nil
+}
+
+func (u *YTDLPUpdater) fetchChecksum(ctx context.Context) (string, error) {
+	sumsURL := u.releaseURL[:strings.LastIndex(u.releaseURL, "/")+1] + "SHA2-256SUMS"
+	body, err := u.get(ctx, sumsURL)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+	asset := u.releaseURL[strings.LastIndex(u.releaseURL, "/")+1:]
+	sc := bufio.NewScanner(io.LimitReader(body, 1<<20))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == asset {
+			return fields[0], nil
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return "", fmt.Errorf("read checksums: %w", err)
+	}
+	return "", fmt.Errorf("no checksum published for %s", asset)
+}
+
+func (u *YTDLPUpdater) get(ctx context.Context, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch %s: %w", url, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		resp.Body.Close()
+		return nil, fmt.Errorf("fetch %s: %s: %s", url, resp.Status, bytes.TrimSpace(snippet))
+	}
+	return resp.Body, nil
+}
+
+func ytdlpBinaryName() string {
+	if runtime.GOOS == "windows" {
+		return "yt-dlp.exe"
+	}
+	return "yt-dlp"
+}
+
+// ytdlpReleaseAsset names the standalone release build for this platform.
+func ytdlpReleaseAsset() string {
+	switch runtime.GOOS {
+	case "windows":
+		switch runtime.GOARCH {
+		case "arm64":
+			return "yt-dlp_arm64.exe"
+		case "386":
+			return "yt-dlp_x86.exe"
+		}
+		return "yt-dlp.exe"
+	case "darwin":
+		return "yt-dlp_macos"
+	case "linux":
+		switch runtime.GOARCH {
+		case "amd64":
+			return "yt-dlp_linux"
+		case "arm64":
+			return "yt-dlp_linux_aarch64"
+		case "arm":
+			return "yt-dlp_linux_armv7l"
+		}
+	}
+	// Zipapp; needs python3 on PATH.
+	return "yt-dlp"
+}
@@ -0,0 +1,131 @@
+package download
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+const fakeYTDLPScript = "#!/bin/sh\ncase \"$1\" in --version) echo 2099.01.01;; *) echo \"--progress-template\";; esac\n"
+
+func newReleaseServer(t *testing.T, binary, sums string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch filepath.Base(r.URL.Path) {
+		case "SHA2-256SUMS":
+			fmt.Fprint(w, sums)
+		case "yt-dlp_test":
+			fmt.Fprint(w, binary)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func restoreYTDLPPath(t *testing.T) {
+	prev := YTDLPPath()
+	t.Cleanup(func() { SetYTDLPPath(prev) })
+}
+
+func TestIsExtractorBreakage(t *testing.T) {
+	cases := map[string]bool{
+		"ERROR: [youtube] abc: Unable to extract uploader id; please report this issue on https://github.com/yt-dlp/yt-dlp/issues": true,
+		"ERROR: [youtube] abc: nsig extraction failed: Some formats may be missing":                                                true,
+		"ERROR: [youtube] abc: Private video. Sign in if you've been granted access":                                               false,
+		"yt-dlp: exit status 1": false,
+	}
+	for msg, want := range cases {
+		if got := IsExtractorBreakage(msg); got != want {
+			t.Errorf("IsExtractorBreakage(%q) = %v, want %v", msg, got, want)
+		}
+	}
+}
+
+func TestYTDLPUpdater_RecommendsUpdateAfterRepeatedBreakage(t *testing.T) {
+	u := NewYTDLPUpdater(t.TempDir())
+	now := time.Now()
+	u.now = func() time.Time { return now }
+
+	for i := 0; i < breakageThreshold-1; i++ {
+		u.ObserveFailure("ERROR: Unable to extract video data")
+	}
+	u.ObserveFailure("ERROR: Video unavailable")
+	if st := u.Status(); st.UpdateRecommended || st.RecentBreakages != breakageThreshold-1 {
+		t.Fatalf("expected no recommendation below threshold, got %+v", st)
+	}
+
+	u.ObserveFailure("ERROR: Unable to extract video data")
+	if st := u.Status(); !st.UpdateRecommended || len(st.BreakageSamples) == 0 {
+		t.Fatalf("expected update recommendation at threshold, got %+v", st)
+	}
+
+	now = now.Add(breakageWindow + time.Minute)
+	if st := u.Status(); st.UpdateRecommended || st.RecentBreakages != 0 {
+		t.Fatalf("expected breakages to age out, got %+v", st)
+	}
+}
+
+func TestYTDLPUpdater_InstallsVerifiedRelease(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the fake binary")
+	}
+	restoreYTDLPPath(t)
+	sum := sha256.Sum256([]byte(fakeYTDLPScript))
+	srv := newReleaseServer(t, fakeYTDLPScript, hex.EncodeToString(sum[:])+"  yt-dlp_test\n")
+
+	dir := t.TempDir()
+	u := NewYTDLPUpdater(dir)
+	u.releaseURL = srv.URL + "/download/yt-dlp_test"
+	u.ObserveFailure("Unable to extract")
+
+	res, err := u.Update(context.Background(), UpdateMethodRelease)
+	if err != nil {
+		t.Fatalf("update failed: %v (%+v)", err, res)
+	}
+	if res.ToVersion != "2099.01.01" || YTDLPPath() != filepath.Join(dir, "yt-dlp") {
+		t.Fatalf("expected managed binary in use, got result=%+v path=%s", res, YTDLPPath())
+	}
+	st := u.Status()
+	if !st.Managed || st.Version != "2099.01.01" || st.RecentBreakages != 0 || st.LastUpdate == nil {
+		t.Fatalf("unexpected status after update: %+v", st)
+	}
+}
+
+func TestYTDLPUpdater_RejectsChecksumMismatch(t *testing.T) {
+	restoreYTDLPPath(t)
+	srv := newReleaseServer(t, fakeYTDLPScript, "deadbeef  yt-dlp_test\n")
+
+	dir := t.TempDir()
+	u := NewYTDLPUpdater(dir)
+	u.releaseURL = srv.URL + "/download/yt-dlp_test"
+	before := YTDLPPath()
+
+	res, err := u.Update(context.Background(), UpdateMethodRelease)
+	if err == nil || res.Error == "" {
+		t.Fatalf("expected checksum failure, got %+v", res)
+	}
+	if YTDLPPath() != before {
+		t.Fatalf("expected binary path unchanged after failed update")
+	}
+}
+
+func TestYTDLPUpdater_UnknownMethodAndConcurrentUpdate(t *testing.T) {
+	u := NewYTDLPUpdater(t.TempDir())
+	if _, err := u.Update(context.Background(), "apt"); !errors.Is(err, ErrUnknownUpdateMethod) {
+		t.Fatalf("expected ErrUnknownUpdateMethod, got %v", err)
+	}
+	u.updating.Store(true)
+	if _, err := u.Update(context.Background(), UpdateMethodSelf); !errors.Is(err, ErrUpdateInProgress) {
+		t.Fatalf("expected ErrUpdateInProgress, got %v", err)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"net/url"
 	"os/exec"
 	"strings"
+	"time"
 )
 
 // MediaInfo contains minimal metadata extracted from yt-dlp -j.
@@ -15,6 +16,15 @@ type MediaInfo struct {
 	Title        string
 	DurationSec  int64
 	ThumbnailURL string
+	// FilesizeBytes is yt-dlp's exact or approximate size for the selected format; 0 if unknown.
+	FilesizeBytes int64
+	// Uploader is the publishing account (uploader, falling back to channel); Description is the media description.
+	Uploader    string
+	Description string
+	// InfoJSON is the raw `yt-dlp -j` line, which the download step can load
+	// instead of extracting again; ProbeTime is how long that probe took.
+	InfoJSON  string
+	ProbeTime time.Duration
 }
 
 // FetchMediaInfo runs `yt-dlp -j` and returns the first parsed media info.
@@ -35,7 +45,7 @@ func FetchMediaInfo(ctx context.Context, inputURL string) (MediaInfo, error) {
 	}
 	// Mirror the Rust example: use -j and pass extractor args to impersonate
 	// the generic extractor when probing metadata to improve robustness.
-	cmd := exec.CommandContext(ctx, "yt-dlp", "-j", "--extractor-args", "generic:impersonate", "--no-playlist", inputURL)
+	cmd := exec.CommandContext(ctx, YTDLPPath(), "-j", "--extractor-args", "generic:impersonate", "--no-playlist", inputURL)
 	out, err := cmd.StdoutPipe()
 	if err != nil {
 		return MediaInfo{}, err
@@ -58,50 +68,71 @@ func FetchMediaInfo(ctx context.Context, inputURL string) (MediaInfo, error) {
 		if err := json.Unmarshal([]byte(ln), &m); err != nil {
 			continue
 		}
-		var title string
-		if v, ok := m["title"].(string); ok && v != "" {
-			title = v
-		} else {
-			title = inputURL
-		}
-		var duration int64
-		switch dv := m["duration"].(type) {
-		case float64:
-			duration = int64(dv)
-		case int64:
-			duration = dv
-		}
-		var thumb string
-		if v, ok := m["thumbnail"].(string); ok {
-			thumb = v
-		}
-		if thumb == "" {
-			// Try to find the best thumbnail from thumbnails array
-			if arr, ok := m["thumbnails"].([]any); ok && len(arr) > 0 {
-				// Look for high-quality thumbnails first (maxresdefault, hqdefault, etc.)
-				for _, item := range arr {
-					if obj, ok := item.(map[string]any); ok {
-						if u, ok := obj["url"].(string); ok {
-							// Prefer higher resolution thumbnails
-							if strings.Contains(u, "maxresdefault") || strings.Contains(u, "hqdefault") {
-								thumb = u
-								break
-							}
-							// Fallback to any thumbnail if we haven't found one yet
-							if thumb == "" {
-								thumb = u
-							}
+		return mediaInfoFromJSON(m, inputURL, ln), nil
+	}
+	if err := sc.Err(); err != nil {
+		return MediaInfo{}, err
+	}
+	return MediaInfo{}, ErrNoMediaInfo
+}
+
+// mediaInfoFromJSON reads the fields MediaInfo needs from one parsed yt-dlp
+// info JSON document; raw is kept as InfoJSON.
+func mediaInfoFromJSON(m map[string]any, inputURL, raw string) MediaInfo {
+	var title string
+	if v, ok := m["title"].(string); ok && v != "" {
+		title = v
+	} else {
+		title = inputURL
+	}
+	var duration int64
+	switch dv := m["duration"].(type) {
+	case float64:
+		duration = int64(dv)
+	case int64:
+		duration = dv
+	}
+	var thumb string
+	if v, ok := m["thumbnail"].(string); ok {
+		thumb = v
+	}
+	if thumb == "" {
+		// Try to find the best thumbnail from thumbnails array
+		if arr, ok := m["thumbnails"].([]any); ok && len(arr) > 0 {
+			// Look for high-quality thumbnails first (maxresdefault, hqdefault, etc.)
+			for _, item := range arr {
+				if obj, ok := item.(map[string]any); ok {
+					if u, ok := obj["url"].(string); ok {
+						// Prefer higher resolution thumbnails
+						if strings.Contains(u, "maxresdefault") || strings.Contains(u, "hqdefault") {
+							thumb = u
+							break
+						}
+						// Fallback to any thumbnail if we haven't found one yet
+						if thumb == "" {
+							thumb = u
 						}
 					}
 				}
 			}
 		}
-		return MediaInfo{Title: title, DurationSec: duration, ThumbnailURL: thumb}, nil
 	}
-	if err := sc.Err(); err != nil {
-		return MediaInfo{}, err
+	var size int64
+	for _, key := range []string{"filesize", "filesize_approx"} {
+		if v, ok := m[key].(float64); ok && v > 0 {
+			size = int64(v)
+			break
+		}
 	}
-	return MediaInfo{}, ErrNoMediaInfo
+	var uploader string
+	for _, key := range []string{"uploader", "channel"} {
+		if v, ok := m[key].(string); ok && v != "" {
+			uploader = v
+			break
+		}
+	}
+	description, _ := m["description"].(string)
+	return MediaInfo{Title: title, DurationSec: duration, ThumbnailURL: thumb, FilesizeBytes: size, Uploader: uploader, Description: description, InfoJSON: raw}
 }
 
 // validateURL ensures the URL is safe to pass to external commands
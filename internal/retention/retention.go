@@ -0,0 +1,399 @@
+// Package retention prunes completed downloads by age, per-site count and total size.
+package retention
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"videofetch/internal/logging"
+	"videofetch/internal/store"
+)
+
+// Reasons recorded on rows selected for removal.
+const (
+	ReasonMaxAge     = "max_age"
+	ReasonKeepNewest = "keep_newest"
+	ReasonQuota      = "quota"
+)
+
+// Policy describes which completed downloads to remove. Zero fields disable a rule.
+type Policy struct {
+	MaxAge        time.Duration // remove downloads created longer ago than this
+	KeepPerDomain int           // keep only the newest N downloads per site
+	MaxTotalBytes int64         // evict least recently used downloads above this total
+}
+
+// Enabled reports whether any rule is configured.
+func (p Policy) Enabled() bool {
+	return p.MaxAge > 0 || p.KeepPerDomain > 0 || p.MaxTotalBytes > 0
+}
+
+// Store is the subset of store operations the janitor needs.
+type Store interface {
+	ListDownloads(ctx context.Context, f store.ListFilter) ([]store.Download, error)
+	DeleteDownload(ctx context.Context, id int64) error
+	MarkRetention(ctx context.Context, reasons map[int64]string) error
+}
+
+// Cleaner deletes a download's files; download.Downloader.CleanupArtifacts satisfies it
+// and refuses to touch anything outside the output directory.
+type Cleaner interface {
+	CleanupArtifacts(id, filename string, trackedPaths []string) error
+}
+
+// Scope applies a Policy to the completed downloads of one library.
+type Scope struct {
+	Library string  // store library name; empty means store.DefaultLibrary
+	Root    string  // library root used for rows that did not record one
+	Cleaner Cleaner // must only delete files under Root
+	Policy  Policy
+}
+
+// Candidate is a completed download selected for removal.
+type Candidate struct {
+	ID        int64     `json:"id"`
+	Library   string    `json:"library"`
+	URL       string    `json:"url"`
+	Title     string    `json:"title"`
+	Domain    string    `json:"domain"`
+	Filename  string    `json:"filename"`
+	SizeBytes int64     `json:"size_bytes"`
+	CreatedAt time.Time `json:"created_at"`
+	Reason    string    `json:"reason"`
+
+	artifacts []string
+	cleaner   Cleaner
+}
+
+// Report summarizes one retention pass.
+type Report struct {
+	DryRun     bool        `json:"dry_run"`
+	Candidates []Candidate `json:"candidates"`
+	Removed    int         `json:"removed"`
+	FreedBytes int64       `json:"freed_bytes"`
+	Failed     int         `json:"failed"`
+}
+
+// Janitor evaluates each Scope's Policy against the store and removes matching downloads.
+type Janitor struct {
+	store  Store
+	scopes []Scope
+	now    func() time.Time
+
+	onRemoved func(id int64) // runs after each row is deleted
+
+	runMu  sync.Mutex // serializes passes so API and background runs do not race
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New creates a janitor for completed downloads in the default library stored under outputDir.
+func New(st Store, cleaner Cleaner, outputDir string, policy Policy) *Janitor {
+	return NewWithScopes(st, []Scope{{Library: store.DefaultLibrary, Root: outputDir, Cleaner: cleaner, Policy: policy}})
+}
+
+// NewWithScopes creates a janitor that applies a separate policy to each library.
+// Rows of libraries without a scope are never removed.
+func NewWithScopes(st Store, scopes []Scope) *Janitor {
+	ctx, cancel := context.WithCancel(context.Background())
+	normalized := make([]Scope, len(scopes))
+	for i, sc := range scopes {
+		if sc.Library == "" {
+			sc.Library = store.DefaultLibrary
+		}
+		normalized[i] = sc
+	}
+	return &Janitor{
+		store:  st,
+		scopes: normalized,
+		now:    time.Now,
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+}
+
+// OnRemoved registers fn to run after the janitor deletes a row, so state
+// keyed by its ID, such as cached thumbnails, goes with it. Call before Start.
+func (j *Janitor) OnRemoved(fn func(id int64)) {
+	j.onRemoved = fn
+}
+
+// Policy returns the rules of the default library.
+func (j *Janitor) Policy() Policy {
+	for _, sc := range j.scopes {
+		if sc.Library == store.DefaultLibrary {
+			return sc.Policy
+		}
+	}
+	return Policy{}
+}
+
+// Enabled reports whether any scope has rules.
+func (j *Janitor) Enabled() bool {
+	for _, sc := range j.scopes {
+		if sc.Policy.Enabled() {
+			return true
+		}
+	}
+	return false
+}
+
+// Start runs the policies every interval in the background. It is a no-op when
+// no scope has rules.
+func (j *Janitor) Start(interval time.Duration) {
+	if !j.Enabled() || interval <= 0 {
+		close(j.done)
+		return
+	}
+	go j.loop(interval)
+}
+
+// Stop cancels the background loop and waits for an in-flight pass to finish.
+func (j *Janitor) Stop() {
+	j.cancel()
+	<-j.done
+}
+
+func (j *Janitor) loop(interval time.Duration) {
+	defer close(j.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-j.ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := j.Run(j.ctx, false); err != nil && !errors.Is(err, context.Canceled) {
+				slog.Error("retention pass failed", "event", "retention_error", "error", err)
+			}
+		}
+	}
+}
+
+// Run evaluates the policy. With dryRun the selected rows are only tagged
+// with their reason; otherwise their files and rows are deleted.
+func (j *Janitor) Run(ctx context.Context, dryRun bool) (Report, error) {
+	j.runMu.Lock()
+	defer j.runMu.Unlock()
+
+	candidates := make([]Candidate, 0)
+	for _, sc := range j.scopes {
+		planned, err := j.plan(ctx, sc)
+		if err != nil {
+			return Report{}, err
+		}
+		candidates = append(candidates, planned...)
+	}
+	report := Report{DryRun: dryRun, Candidates: candidates}
+
+	if dryRun {
+		reasons := make(map[int64]string, len(candidates))
+		for _, c := range candidates {
+			reasons[c.ID] = c.Reason
+		}
+		if err := j.store.MarkRetention(ctx, reasons); err != nil {
+			return Report{}, err
+		}
+		return report, nil
+	}
+
+	for _, c := range candidates {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		if err := c.cleaner.CleanupArtifacts("", c.Filename, c.artifacts); err != nil {
+			report.Failed++
+			slog.Warn("retention could not delete files",
+				"event", "retention_delete_error",
+				"id", c.ID,
+				"library", c.Library,
+				"reason", c.Reason,
+				"error", err)
+			continue
+		}
+		if err := j.store.DeleteDownload(ctx, c.ID); err != nil {
+			report.Failed++
+			logging.LogDBOperation("delete_download", c.ID, err)
+			continue
+		}
+		if j.onRemoved != nil {
+			j.onRemoved(c.ID)
+		}
+		report.Removed++
+		report.FreedBytes += c.SizeBytes
+		slog.Info("retention removed download",
+			"event", "retention_removed",
+			"id", c.ID,
+			"library", c.Library,
+			"reason", c.Reason,
+			"url", logging.RedactURL(c.URL),
+			"size_bytes", c.SizeBytes)
+	}
+	return report, nil
+}
+
+// plan selects a scope's candidates in rule order: age, then per-site count, then quota.
+// Exempt rows are never selected but still count toward the quota total.
+func (j *Janitor) plan(ctx context.Context, sc Scope) ([]Candidate, error) {
+	policy := sc.Policy
+	if !policy.Enabled() {
+		return []Candidate{}, nil
+	}
+	rows, err := j.store.ListDownloads(ctx, store.ListFilter{Status: "completed", Sort: "created_at", Order: "desc"})
+	if err != nil {
+		return nil, err
+	}
+
+	type entry struct {
+		row    store.Download
+		size   int64
+		reason string
+	}
+	entries := make([]*entry, 0, len(rows))
+	for _, row := range rows {
+		if libraryOf(row) != sc.Library {
+			continue
+		}
+		entries = append(entries, &entry{row: row, size: sizeOf(row, sc.Root)})
+	}
+
+	out := make([]Candidate, 0)
+	selectEntry := func(e *entry, reason string) {
+		e.reason = reason
+		out = append(out, Candidate{
+			ID:        e.row.ID,
+			Library:   sc.Library,
+			URL:       e.row.URL,
+			Title:     e.row.Title,
+			Domain:    domainOf(e.row.URL),
+			Filename:  e.row.Filename,
+			SizeBytes: e.size,
+			CreatedAt: e.row.CreatedAt,
+			Reason:    reason,
+			artifacts: e.row.ArtifactPaths,
+			cleaner:   sc.Cleaner,
+		})
+	}
+
+	if policy.MaxAge > 0 {
+		cutoff := j.now().Add(-policy.MaxAge)
+		for _, e := range entries {
+			if !exempt(e.row) && e.row.CreatedAt.Before(cutoff) {
+				selectEntry(e, ReasonMaxAge)
+			}
+		}
+	}
+
+	if policy.KeepPerDomain > 0 {
+		kept := make(map[string]int)
+		for _, e := range entries { // newest first
+			if e.reason != "" || exempt(e.row) {
+				continue
+			}
+			domain := domainOf(e.row.URL)
+			if kept[domain] < policy.KeepPerDomain {
+				kept[domain]++
+				continue
+			}
+			selectEntry(e, ReasonKeepNewest)
+		}
+	}
+
+	if policy.MaxTotalBytes > 0 {
+		var total int64
+		lru := make([]*entry, 0, len(entries))
+		for _, e := range entries {
+			if e.reason != "" {
+				continue
+			}
+			total += e.size
+			if !exempt(e.row) {
+				lru = append(lru, e)
+			}
+		}
+		sort.SliceStable(lru, func(a, b int) bool {
+			return lastUsed(lru[a].row).Before(lastUsed(lru[b].row))
+		})
+		for _, e := range lru {
+			if total <= policy.MaxTotalBytes {
+				break
+			}
+			selectEntry(e, ReasonQuota)
+			total -= e.size
+		}
+	}
+	return out, nil
+}
+
+// exempt reports rows retention must leave alone: pinned rows, rows being
+// uploaded, and uploaded rows, which are the only record of their remote copy.
+func exempt(row store.Download) bool {
+	return row.Pinned || row.UploadPhase == store.UploadPhaseUploading || row.RemoteKey != ""
+}
+
+// libraryOf returns the row's library; rows from before libraries existed belong to the default one.
+func libraryOf(row store.Download) string {
+	if row.Library == "" {
+		return store.DefaultLibrary
+	}
+	return row.Library
+}
+
+// sizeOf sums the on-disk size of a row's file and tracked artifacts.
+// Relative names resolve against the row's recorded root, falling back to root.
+func sizeOf(row store.Download, root string) int64 {
+	if row.LibraryRoot != "" {
+		root = row.LibraryRoot
+	}
+	paths := make([]string, 0, len(row.ArtifactPaths)+1)
+	paths = append(paths, row.ArtifactPaths...)
+	if strings.TrimSpace(row.Filename) != "" {
+		paths = append(paths, row.Filename)
+	}
+	seen := make(map[string]struct{}, len(paths))
+	var total int64
+	for _, p := range paths {
+		full := strings.TrimSpace(p)
+		if full == "" {
+			continue
+		}
+		if !filepath.IsAbs(full) {
+			full = filepath.Join(root, full)
+		}
+		full = filepath.Clean(full)
+		if _, dup := seen[full]; dup {
+			continue
+		}
+		seen[full] = struct{}{}
+		if info, err := os.Stat(full); err == nil && info.Mode().IsRegular() {
+			total += info.Size()
+		}
+	}
+	return total
+}
+
+func lastUsed(row store.Download) time.Time {
+	if row.LastAccessedAt != nil && row.LastAccessedAt.After(row.UpdatedAt) {
+		return *row.LastAccessedAt
+	}
+	return row.UpdatedAt
+}
+
+// domainOf groups URLs by host, ignoring a leading "www.".
+func domainOf(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Hostname() == "" {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
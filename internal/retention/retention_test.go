@@ -0,0 +1,234 @@
+package retention
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"videofetch/internal/download"
+	"videofetch/internal/store"
+)
+
+func newTestStore(t *testing.T) *store.Store {
+	t.Helper()
+	st, err := store.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { _ = st.Close() })
+	return st
+}
+
+func addCompleted(t *testing.T, st *store.Store, outDir, url, filename string, size int) int64 {
+	t.Helper()
+	ctx := context.Background()
+	id, err := st.CreateDownload(ctx, url, filename, 0, "", "completed", 100)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := st.UpdateFilename(ctx, id, filename); err != nil {
+		t.Fatalf("update filename: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(outDir, filename), make([]byte, size), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	return id
+}
+
+func reasonsByID(cands []Candidate) map[int64]string {
+	out := make(map[int64]string, len(cands))
+	for _, c := range cands {
+		out[c.ID] = c.Reason
+	}
+	return out
+}
+
+func TestRun_MaxAgeSkipsPinnedAndDryRunMarksRows(t *testing.T) {
+	ctx := context.Background()
+	st := newTestStore(t)
+	outDir := t.TempDir()
+
+	oldID := addCompleted(t, st, outDir, "https://example.com/a", "a.mp4", 10)
+	pinnedID := addCompleted(t, st, outDir, "https://example.com/b", "b.mp4", 10)
+	if _, err := st.SetPinned(ctx, pinnedID, true); err != nil {
+		t.Fatalf("pin: %v", err)
+	}
+
+	j := New(st, download.NewDownloader(outDir), outDir, Policy{MaxAge: 24 * time.Hour})
+	j.now = func() time.Time { return time.Now().Add(48 * time.Hour) }
+
+	report, err := j.Run(ctx, true)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if got := reasonsByID(report.Candidates); len(got) != 1 || got[oldID] != ReasonMaxAge {
+		t.Fatalf("expected only unpinned row selected for max_age, got %v", got)
+	}
+	row, _, _ := st.GetDownloadByID(ctx, oldID)
+	if row.RetentionReason != ReasonMaxAge {
+		t.Fatalf("expected dry run to record reason, got %q", row.RetentionReason)
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "a.mp4")); err != nil {
+		t.Fatalf("dry run must not delete files: %v", err)
+	}
+
+	report, err = j.Run(ctx, false)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if report.Removed != 1 || report.FreedBytes != 10 {
+		t.Fatalf("expected one 10-byte removal, got %+v", report)
+	}
+	if _, found, _ := st.GetDownloadByID(ctx, oldID); found {
+		t.Fatalf("expected expired row deleted")
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "a.mp4")); !os.IsNotExist(err) {
+		t.Fatalf("expected expired file deleted, stat err=%v", err)
+	}
+	if _, found, _ := st.GetDownloadByID(ctx, pinnedID); !found {
+		t.Fatalf("expected pinned row kept")
+	}
+}
+
+func TestRun_SkipsRowsBeingUploadedOrUploaded(t *testing.T) {
+	ctx := context.Background()
+	st := newTestStore(t)
+	outDir := t.TempDir()
+
+	oldID := addCompleted(t, st, outDir, "https://example.com/a", "a.mp4", 10)
+	uploadingID := addCompleted(t, st, outDir, "https://example.com/b", "b.mp4", 10)
+	if err := st.UpdateUpload(ctx, uploadingID, store.UploadPhaseUploading, 40, ""); err != nil {
+		t.Fatalf("update upload: %v", err)
+	}
+	uploadedID := addCompleted(t, st, outDir, "https://example.com/c", "c.mp4", 10)
+	if err := st.MarkUploaded(ctx, uploadedID, "s3", "c.mp4"); err != nil {
+		t.Fatalf("mark uploaded: %v", err)
+	}
+
+	j := New(st, download.NewDownloader(outDir), outDir, Policy{MaxAge: time.Hour, MaxTotalBytes: 1})
+	j.now = func() time.Time { return time.Now().Add(48 * time.Hour) }
+	report, err := j.Run(ctx, false)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if got := reasonsByID(report.Candidates); len(got) != 1 || got[oldID] != ReasonMaxAge {
+		t.Fatalf("expected only the local-only row selected, got %v", got)
+	}
+	for _, id := range []int64{uploadingID, uploadedID} {
+		if _, found, _ := st.GetDownloadByID(ctx, id); !found {
+			t.Fatalf("expected row %d kept while it has a remote copy or upload", id)
+		}
+	}
+}
+
+func TestRun_KeepNewestPerDomain(t *testing.T) {
+	ctx := context.Background()
+	st := newTestStore(t)
+	outDir := t.TempDir()
+
+	a1 := addCompleted(t, st, outDir, "https://www.example.com/1", "1.mp4", 1)
+	a2 := addCompleted(t, st, outDir, "https://example.com/2", "2.mp4", 1)
+	a3 := addCompleted(t, st, outDir, "https://example.com/3", "3.mp4", 1)
+	b1 := addCompleted(t, st, outDir, "https://other.test/1", "o1.mp4", 1)
+
+	j := New(st, download.NewDownloader(outDir), outDir, Policy{KeepPerDomain: 1})
+	report, err := j.Run(ctx, true)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	got := reasonsByID(report.Candidates)
+	if len(got) != 2 || got[a1] != ReasonKeepNewest || got[a2] != ReasonKeepNewest {
+		t.Fatalf("expected two older example.com rows selected, got %v", got)
+	}
+	if _, ok := got[a3]; ok {
+		t.Fatalf("newest example.com row must be kept")
+	}
+	if _, ok := got[b1]; ok {
+		t.Fatalf("other domain must be kept")
+	}
+}
+
+func TestRun_QuotaEvictsUntilUnderLimit(t *testing.T) {
+	ctx := context.Background()
+	st := newTestStore(t)
+	outDir := t.TempDir()
+
+	addCompleted(t, st, outDir, "https://example.com/1", "1.mp4", 100)
+	addCompleted(t, st, outDir, "https://example.com/2", "2.mp4", 100)
+	pinned := addCompleted(t, st, outDir, "https://example.com/3", "3.mp4", 100)
+	if _, err := st.SetPinned(ctx, pinned, true); err != nil {
+		t.Fatalf("pin: %v", err)
+	}
+
+	j := New(st, download.NewDownloader(outDir), outDir, Policy{MaxTotalBytes: 150})
+	var removed []int64
+	j.OnRemoved(func(id int64) { removed = append(removed, id) })
+	report, err := j.Run(ctx, false)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if report.Removed != 2 || report.FreedBytes != 200 {
+		t.Fatalf("expected both unpinned rows evicted, got %+v", report)
+	}
+	if len(removed) != 2 {
+		t.Fatalf("expected OnRemoved for each evicted row, got %v", removed)
+	}
+	for _, c := range report.Candidates {
+		if c.Reason != ReasonQuota || c.ID == pinned {
+			t.Fatalf("unexpected candidate %+v", c)
+		}
+	}
+}
+
+func TestRun_DisabledPolicySelectsNothing(t *testing.T) {
+	st := newTestStore(t)
+	outDir := t.TempDir()
+	addCompleted(t, st, outDir, "https://example.com/1", "1.mp4", 1)
+
+	j := New(st, download.NewDownloader(outDir), outDir, Policy{})
+	report, err := j.Run(context.Background(), false)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(report.Candidates) != 0 || report.Removed != 0 {
+		t.Fatalf("expected no-op, got %+v", report)
+	}
+}
+
+func TestRun_ScopesApplyPolicyPerLibrary(t *testing.T) {
+	ctx := context.Background()
+	st := newTestStore(t)
+	outDir, scratchDir := t.TempDir(), t.TempDir()
+
+	defaultID := addCompleted(t, st, outDir, "https://example.com/a", "a.mp4", 10)
+	scratchID, err := st.CreateDownloadInLibrary(ctx, "https://example.com/b", "b", 0, "", "completed", 100, "scratch", scratchDir)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	_ = st.UpdateFilename(ctx, scratchID, "b.mp4")
+	if err := os.WriteFile(filepath.Join(scratchDir, "b.mp4"), make([]byte, 20), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	j := NewWithScopes(st, []Scope{
+		{Library: store.DefaultLibrary, Root: outDir, Cleaner: download.NewDownloader(outDir), Policy: Policy{MaxAge: 30 * 24 * time.Hour}},
+		{Library: "scratch", Root: scratchDir, Cleaner: download.NewDownloader(scratchDir), Policy: Policy{MaxAge: time.Hour}},
+	})
+	j.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+
+	report, err := j.Run(ctx, false)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(report.Candidates) != 1 || report.Candidates[0].ID != scratchID || report.Candidates[0].Library != "scratch" || report.Candidates[0].SizeBytes != 20 {
+		t.Fatalf("expected only the scratch row selected, got %+v", report.Candidates)
+	}
+	if _, err := os.Stat(filepath.Join(scratchDir, "b.mp4")); !os.IsNotExist(err) {
+		t.Fatalf("expected scratch file removed from its own root, got %v", err)
+	}
+	if _, found, _ := st.GetDownloadByID(ctx, defaultID); !found {
+		t.Fatalf("expected default library row kept under its longer policy")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -17,13 +18,17 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
 
 	"videofetch/internal/download"
 	"videofetch/internal/logging"
+	"videofetch/internal/retention"
 	"videofetch/internal/store"
+	"videofetch/internal/thumbs"
 	"videofetch/internal/ui"
 )
 
@@ -38,8 +43,177 @@ type downloadManager interface {
 	IsManagedByDBID(dbID int64) bool
 }
 
+// diskStatusSource reports free space on the output volume.
+type diskStatusSource interface {
+	Status() download.DiskStatus
+}
+
 type Options struct {
 	UnsafeLogPayloads bool
+
+	// Disk enables disk-space reporting in /api/health and the dashboard; nil disables it.
+	Disk diskStatusSource
+
+	// Retention enables /api/retention/*; nil disables the endpoints.
+	Retention retentionRunner
+
+	// TempGC enables /api/admin/tempgc; nil disables the endpoint.
+	TempGC tempGCRunner
+
+	// YTDLP enables yt-dlp version reporting and /api/admin/ytdlp*; nil disables them.
+	YTDLP ytdlpTool
+
+	// Pool enables /api/admin/pool and the dashboard pool form; nil disables them.
+	Pool poolResizer
+
+	// Maintenance enables /api/admin/maintenance and the dashboard toggle; nil disables them.
+	Maintenance maintenanceController
+
+	// Events streams live download events to WebSocket clients; nil disables them.
+	Events eventSubscriber
+
+	// Libraries enables named storage libraries; nil accepts only the default library.
+	Libraries libraryCatalog
+
+	// Remote lets /api/download_file redirect to uploaded copies; nil serves local files only.
+	Remote remoteLinker
+
+	// Thumbnails serves cached thumbnails from /api/thumbnail/{id}; nil redirects to the source URL.
+	Thumbnails thumbnailCache
+
+	// Transcripts enables POST /api/transcripts/{id}/index; nil disables reindexing.
+	Transcripts transcriptIndexer
+
+	// Metadata enables POST /api/downloads/{id}/refresh-metadata and its bulk variant; nil disables them.
+	Metadata metadataRefresher
+
+	// Breakers reports per-host circuit breakers in /api/health and enables /api/admin/breakers; nil disables them.
+	Breakers breakerBoard
+
+	// Metrics enables GET /api/metrics; nil disables it.
+	Metrics metricsReporter
+
+	// Prober enables GET /api/probe and probe_token/format_id on /api/download_single; nil disables them.
+	Prober mediaProber
+
+	// MetadataPool adds the metadata pool's depth and active probes to /api/status and /api/health; nil omits them.
+	MetadataPool metadataPoolReporter
+}
+
+// retentionRunner evaluates retention rules; dryRun only tags rows with the reason.
+type retentionRunner interface {
+	Run(ctx context.Context, dryRun bool) (retention.Report, error)
+}
+
+// tempGCRunner exposes the orphaned temp file janitor.
+type tempGCRunner interface {
+	LastReport() *download.TempGCReport
+	RunOnce(ctx context.Context) download.TempGCReport
+}
+
+// ytdlpTool reports the yt-dlp version and updates it in place.
+type ytdlpTool interface {
+	Status() download.YTDLPStatus
+	Update(ctx context.Context, method string) (download.YTDLPUpdateResult, error)
+}
+
+// poolResizer exposes the worker pool size for live changes.
+type poolResizer interface {
+	PoolSize() download.PoolSize
+	Resize(workers, queueCap int) error
+}
+
+// maintenanceController toggles the global maintenance hold.
+type maintenanceController interface {
+	Maintenance() download.MaintenanceStatus
+	EnterMaintenance(pauseActive bool) (download.MaintenanceStatus, error)
+	ExitMaintenance() (download.MaintenanceStatus, error)
+}
+
+// eventSubscriber hands out subscriptions to download lifecycle events.
+type eventSubscriber interface {
+	Subscribe(name string, opts download.SubscribeOptions) *download.Subscription
+}
+
+// libraryCatalog resolves storage libraries by name.
+type libraryCatalog interface {
+	Libraries() []download.Library
+	Library(name string) (download.Library, bool)
+}
+
+// remoteLinker returns links to files uploaded to a remote sink.
+type remoteLinker interface {
+	RemoteURL(ctx context.Context, sink, key string) (string, error)
+}
+
+// remoteStreamer is implemented by remote links whose sink needs its own
+// credentials; their files are streamed through the server instead of
+// redirecting. streamed is false when a redirect works.
+type remoteStreamer interface {
+	OpenRemote(ctx context.Context, sink, key, byteRange string) (resp *http.Response, streamed bool, err error)
+}
+
+// thumbnailCache stores local copies of download thumbnails.
+type thumbnailCache interface {
+	Path(id int64, size thumbs.Size) (string, error)
+	Enqueue(id int64, remote, videoPath string) bool
+	Remove(id int64) error
+}
+
+// transcriptIndexer fetches and indexes subtitles for completed downloads.
+type transcriptIndexer interface {
+	Enqueue(dbID int64)
+}
+
+// Bulk metadata refreshes run in the background, a few rows at a time.
+const (
+	maxBulkRefresh     = 500
+	bulkRefreshWorkers = 2
+)
+
+// Refresh preconditions that map to client errors.
+var (
+	errRefreshInvalidState = errors.New("refresh: row not completed")
+	errRefreshNoFile       = errors.New("refresh: local file missing")
+)
+
+// metadataRefresher re-reads a row's metadata from its source URL or its local file.
+type metadataRefresher interface {
+	RefreshMetadata(ctx context.Context, dbID int64, url string, st download.MetadataStore) (download.MediaInfo, error)
+	RefreshMetadataFromFile(ctx context.Context, dbID int64, path string, st download.MetadataStore) (download.MediaInfo, error)
+}
+
+// breakerBoard exposes the per-host circuit breakers.
+type breakerBoard interface {
+	Breakers() []download.BreakerStatus
+	ResetBreaker(host string) bool
+}
+
+// metricsReporter exposes the download manager's counters.
+type metricsReporter interface {
+	Metrics() download.Metrics
+}
+
+// mediaProber previews URLs before they are enqueued.
+type mediaProber interface {
+	Probe(ctx context.Context, url string) (download.ProbeResult, error)
+	LookupProbe(token string) (download.ProbeResult, bool)
+}
+
+// metadataPoolReporter exposes the pool probing pending rows.
+type metadataPoolReporter interface {
+	MetadataPool() download.MetadataPoolStatus
+}
+
+// optionsEnqueuer is implemented by managers that accept per-job options.
+type optionsEnqueuer interface {
+	EnqueueWithOptions(url string, opts download.EnqueueOptions) (string, error)
+}
+
+// healthCondition is a degraded-service signal surfaced by /api/health and the dashboard.
+type healthCondition struct {
+	Name    string `json:"name"`
+	Message string `json:"message"`
 }
 
 var wsUpgrader = websocket.Upgrader{
@@ -60,9 +234,114 @@ func New(mgr downloadManager, st *store.Store, outputDir string, opts ...Options
 
 	mux := http.NewServeMux()
 	// helpers
-	var storeCreate func(ctx context.Context, url, title string, duration int64, thumbnail string, status string, progress float64) (int64, error)
+	// storeCreate inserts a pending row together with its job options, so the
+	// DB worker never sees the row without them.
+	var storeCreate func(ctx context.Context, url string, lib download.Library, opts store.JobOptions) (int64, error)
 	if st != nil {
-		storeCreate = st.CreateDownload
+		storeCreate = func(ctx context.Context, url string, lib download.Library, opts store.JobOptions) (int64, error) {
+			return st.CreatePendingDownload(ctx, url, lib.Name, lib.Root, opts)
+		}
+	}
+	// resolveLibrary maps a requested library name to a configured one; paths are never accepted.
+	resolveLibrary := func(name string) (download.Library, bool) {
+		name = strings.TrimSpace(name)
+		if serverOpts.Libraries != nil {
+			return serverOpts.Libraries.Library(name)
+		}
+		if name == "" || name == download.DefaultLibraryName {
+			return download.Library{Name: download.DefaultLibraryName, Root: outputDir}, true
+		}
+		return download.Library{}, false
+	}
+	listLibraries := func() []download.Library {
+		if serverOpts.Libraries != nil {
+			return serverOpts.Libraries.Libraries()
+		}
+		return []download.Library{{Name: download.DefaultLibraryName, Root: outputDir}}
+	}
+	// rootFor returns the directory a row's files live under.
+	rootFor := func(row store.Download) string {
+		if row.LibraryRoot != "" {
+			return row.LibraryRoot
+		}
+		return outputDir
+	}
+	// evictThumbnails drops the cached thumbnails of deleted rows. SQLite may
+	// hand a deleted row's ID to a new one, which must not inherit them.
+	evictThumbnails := func(ids ...int64) {
+		if serverOpts.Thumbnails == nil {
+			return
+		}
+		for _, id := range ids {
+			if err := serverOpts.Thumbnails.Remove(id); err != nil {
+				slog.Warn("failed to remove cached thumbnail", "event", "thumbnail_remove_error", "id", id, "error", err)
+			}
+		}
+	}
+	// chapterFiles lists the pieces of a row split by chapters that exist
+	// inside the row's library.
+	chapterFiles := func(row store.Download) []download.ChapterFile {
+		root, err := filepath.Abs(rootFor(row))
+		if err != nil {
+			return nil
+		}
+		var out []download.ChapterFile
+		for _, c := range download.ChapterFiles(row.ArtifactPaths) {
+			if !isPathWithin(root, c.Path) {
+				continue
+			}
+			if _, err := os.Stat(c.Path); err == nil {
+				out = append(out, c)
+			}
+		}
+		return out
+	}
+	chapterFile := func(row store.Download, number int) (download.ChapterFile, bool) {
+		for _, c := range chapterFiles(row) {
+			if c.Number == number {
+				return c, true
+			}
+		}
+		return download.ChapterFile{}, false
+	}
+	// sidecars lists the archival files of a row that exist inside the
+	// row's library.
+	sidecars := func(row store.Download) []download.Sidecar {
+		root, err := filepath.Abs(rootFor(row))
+		if err != nil {
+			return nil
+		}
+		var out []download.Sidecar
+		for _, sc := range download.Sidecars(row.ArtifactPaths) {
+			if !isPathWithin(root, sc.Path) {
+				continue
+			}
+			if _, err := os.Stat(sc.Path); err == nil {
+				out = append(out, sc)
+			}
+		}
+		return out
+	}
+	enqueueDirect := func(url string, lib download.Library, opts download.EnqueueOptions) (string, error) {
+		if oe, ok := mgr.(optionsEnqueuer); ok {
+			opts.Library = lib.Name
+			return oe.EnqueueWithOptions(url, opts)
+		}
+		if lib.Name != download.DefaultLibraryName {
+			return "", download.ErrUnknownLibrary
+		}
+		return mgr.Enqueue(url)
+	}
+
+	// tagDownloads attaches enqueue-time tags. A failure is logged rather than
+	// failing the request, since the rows are already queued.
+	tagDownloads := func(ctx context.Context, ids []int64, tags []string) {
+		if st == nil || len(ids) == 0 || len(tags) == 0 {
+			return
+		}
+		if err := st.AddTags(ctx, ids, tags); err != nil {
+			logging.LogDBOperation("add_tags", ids[0], err)
+		}
 	}
 
 	// Routes
@@ -72,7 +351,15 @@ func New(mgr downloadManager, st *store.Store, outputDir string, opts ...Options
 			return
 		}
 		var req struct {
-			URL string `json:"url"`
+			URL         string   `json:"url"`
+			Library     string   `json:"library"`
+			Tags        []string `json:"tags"`
+			MaxDuration string   `json:"max_duration"`
+			ProbeToken  string   `json:"probe_token"`
+			FormatID    string   `json:"format_id"`
+			Sections    []string `json:"sections"`
+			// SplitChapters also writes each chapter to its own file.
+			SplitChapters bool `json:"split_chapters"`
 		}
 		if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&req); err != nil || req.URL == "" {
 			writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "invalid_request"})
@@ -82,9 +369,52 @@ func New(mgr downloadManager, st *store.Store, outputDir string, opts ...Options
 			writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "invalid_url"})
 			return
 		}
-		// If store available, check for duplicates first.
+		lib, ok := resolveLibrary(req.Library)
+		if !ok {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "unknown_library"})
+			return
+		}
+		tags, err := store.NormalizeTags(req.Tags)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "invalid_tag"})
+			return
+		}
+		maxDuration, ok := parseMaxDuration(req.MaxDuration)
+		if !ok {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "invalid_max_duration"})
+			return
+		}
+		sections, err := download.ParseSections(req.Sections)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "invalid_sections"})
+			return
+		}
+		sectionSpecs := download.SectionSpecs(sections)
+		// A probe token reuses an /api/probe result; a format can only be
+		// pinned from that result's list.
+		if req.FormatID != "" && req.ProbeToken == "" {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "probe_token_required"})
+			return
+		}
+		if req.ProbeToken != "" {
+			var probe download.ProbeResult
+			found := false
+			if serverOpts.Prober != nil {
+				probe, found = serverOpts.Prober.LookupProbe(req.ProbeToken)
+			}
+			if !found || probe.URL != req.URL {
+				writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "invalid_probe_token"})
+				return
+			}
+			if req.FormatID != "" && !probe.HasFormat(req.FormatID) {
+				writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "unknown_format"})
+				return
+			}
+		}
+		// If store available, check for duplicates first. Clips with other
+		// sections, or the whole video, are different downloads.
 		if st != nil {
-			if existing, found, err := st.GetLatestDownloadByURL(r.Context(), req.URL); err == nil && found {
+			if existing, found, err := st.GetLatestDownloadBySections(r.Context(), req.URL, sectionSpecs); err == nil && found {
 				writeJSON(w, http.StatusOK, map[string]any{
 					"status":          "success",
 					"message":         "already_exists",
@@ -99,15 +429,22 @@ func New(mgr downloadManager, st *store.Store, outputDir string, opts ...Options
 		var dbid int64
 		if storeCreate != nil {
 			// Fast insertion: store as pending with URL as title, no metadata fetching
-			if idv, err := storeCreate(r.Context(), req.URL, req.URL, 0, "", "pending", 0); err == nil {
+			jobOpts := store.JobOptions{MaxDuration: maxDuration, FormatID: req.FormatID, ProbeToken: req.ProbeToken, Sections: sectionSpecs, SplitChapters: req.SplitChapters}
+			if idv, err := storeCreate(r.Context(), req.URL, lib, jobOpts); err == nil {
 				dbid = idv
+				tagDownloads(r.Context(), []int64{dbid}, tags)
 			} else {
 				logging.LogDBOperation("create_download", 0, err)
 				writeJSON(w, http.StatusInternalServerError, map[string]any{"status": "error", "message": "internal_error"})
 				return
 			}
 		} else {
-			if _, err := mgr.Enqueue(req.URL); err != nil {
+			opts := download.EnqueueOptions{MaxDuration: maxDuration, FormatID: req.FormatID, ProbeToken: req.ProbeToken, Sections: sections, SplitChapters: req.SplitChapters}
+			if _, err := enqueueDirect(req.URL, lib, opts); err != nil {
+				if errors.Is(err, download.ErrUnknownLibrary) {
+					writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "unknown_library"})
+					return
+				}
 				msg := "internal_error"
 				if err == download.ErrQueueFull {
 					msg = "queue_full"
@@ -133,12 +470,31 @@ func New(mgr downloadManager, st *store.Store, outputDir string, opts ...Options
 			return
 		}
 		var req struct {
-			URLs []string `json:"urls"`
+			URLs          []string `json:"urls"`
+			Library       string   `json:"library"`
+			Tags          []string `json:"tags"`
+			MaxDuration   string   `json:"max_duration"`
+			SplitChapters bool     `json:"split_chapters"`
 		}
 		if err := json.NewDecoder(io.LimitReader(r.Body, 4<<20)).Decode(&req); err != nil || len(req.URLs) == 0 {
 			writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "invalid_request"})
 			return
 		}
+		lib, ok := resolveLibrary(req.Library)
+		if !ok {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "unknown_library"})
+			return
+		}
+		tags, err := store.NormalizeTags(req.Tags)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "invalid_tag"})
+			return
+		}
+		maxDuration, ok := parseMaxDuration(req.MaxDuration)
+		if !ok {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "invalid_max_duration"})
+			return
+		}
 		dbIDs := make([]int64, 0, len(req.URLs))
 		validURLCount := 0
 		duplicateCount := 0
@@ -152,7 +508,7 @@ func New(mgr downloadManager, st *store.Store, outputDir string, opts ...Options
 
 			// If store available, check for duplicates and skip existing URLs.
 			if st != nil {
-				if _, found, err := st.GetLatestDownloadByURL(r.Context(), u); err == nil && found {
+				if _, found, err := st.GetLatestDownloadBySections(r.Context(), u, nil); err == nil && found {
 					duplicateCount++
 					continue
 				}
@@ -160,7 +516,7 @@ func New(mgr downloadManager, st *store.Store, outputDir string, opts ...Options
 			var dbid int64
 			if storeCreate != nil {
 				// Fast insertion: store as pending with URL as title, no metadata fetching
-				if idv, err := storeCreate(r.Context(), u, u, 0, "", "pending", 0); err == nil {
+				if idv, err := storeCreate(r.Context(), u, lib, store.JobOptions{MaxDuration: maxDuration, SplitChapters: req.SplitChapters}); err == nil {
 					dbid = idv
 					dbIDs = append(dbIDs, dbid)
 				} else {
@@ -170,6 +526,7 @@ func New(mgr downloadManager, st *store.Store, outputDir string, opts ...Options
 			}
 		}
 
+		tagDownloads(r.Context(), dbIDs, tags)
 		statusCode, response := buildBatchResponse(validURLCount, dbIDs, duplicateCount, createFailureCount)
 		writeJSON(w, statusCode, response)
 	})
@@ -181,7 +538,11 @@ func New(mgr downloadManager, st *store.Store, outputDir string, opts ...Options
 		}
 		id := r.URL.Query().Get("id")
 		items := mgr.Snapshot(id)
-		writeJSON(w, http.StatusOK, map[string]any{"status": "success", "downloads": items})
+		response := map[string]any{"status": "success", "downloads": items}
+		if serverOpts.MetadataPool != nil {
+			response["metadata_pool"] = serverOpts.MetadataPool.MetadataPool()
+		}
+		writeJSON(w, http.StatusOK, response)
 	})
 
 	// Optional DB-backed listing; only registered if store is provided via main.
@@ -191,7 +552,7 @@ func New(mgr downloadManager, st *store.Store, outputDir string, opts ...Options
 				methodNotAllowed(w)
 				return
 			}
-			affected, err := st.RetryFailedDownloads(r.Context())
+			affected, err := st.RetryFailedDownloadsByTag(r.Context(), r.URL.Query().Get("tag"))
 			if err != nil {
 				logging.LogRetryFailed(0, err)
 				writeJSON(w, http.StatusInternalServerError, map[string]any{"status": "error", "message": "internal_error"})
@@ -217,6 +578,7 @@ func New(mgr downloadManager, st *store.Store, outputDir string, opts ...Options
 				writeJSON(w, http.StatusInternalServerError, map[string]any{"status": "error", "message": "internal_error"})
 				return
 			}
+			evictThumbnails(req.ID)
 			writeJSON(w, http.StatusOK, map[string]any{"status": "success", "message": "deleted"})
 		})
 
@@ -225,13 +587,83 @@ func New(mgr downloadManager, st *store.Store, outputDir string, opts ...Options
 				methodNotAllowed(w)
 				return
 			}
-			deleted, err := st.DeleteHistory(r.Context())
+			deleted, err := st.DeleteHistoryByTag(r.Context(), r.URL.Query().Get("tag"))
 			if err != nil {
 				logging.LogDBOperation("delete_history", 0, err)
 				writeJSON(w, http.StatusInternalServerError, map[string]any{"status": "error", "message": "internal_error"})
 				return
 			}
-			writeJSON(w, http.StatusOK, map[string]any{"status": "success", "message": "cleared", "count": deleted})
+			evictThumbnails(deleted...)
+			writeJSON(w, http.StatusOK, map[string]any{"status": "success", "message": "cleared", "count": len(deleted)})
+		})
+
+		mux.HandleFunc("/api/tags", func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				tags, err := st.ListTags(r.Context())
+				if err != nil {
+					logging.LogDBOperation("list_tags", 0, err)
+					writeJSON(w, http.StatusInternalServerError, map[string]any{"status": "error", "message": "internal_error"})
+					return
+				}
+				writeJSON(w, http.StatusOK, map[string]any{"status": "success", "tags": tags})
+			case http.MethodPost:
+				var req struct {
+					IDs    []int64  `json:"ids"`
+					Add    []string `json:"add"`
+					Remove []string `json:"remove"`
+				}
+				if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&req); err != nil || len(req.IDs) == 0 || (len(req.Add) == 0 && len(req.Remove) == 0) {
+					writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "invalid_request"})
+					return
+				}
+				for _, id := range req.IDs {
+					if id <= 0 {
+						writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "invalid_id"})
+						return
+					}
+				}
+				add, err := store.NormalizeTags(req.Add)
+				if err == nil {
+					_, err = store.NormalizeTags(req.Remove)
+				}
+				if err != nil {
+					writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "invalid_tag"})
+					return
+				}
+				if err := st.AddTags(r.Context(), req.IDs, add); err != nil {
+					logging.LogDBOperation("add_tags", req.IDs[0], err)
+					writeJSON(w, http.StatusInternalServerError, map[string]any{"status": "error", "message": "internal_error"})
+					return
+				}
+				if err := st.RemoveTags(r.Context(), req.IDs, req.Remove); err != nil {
+					logging.LogDBOperation("remove_tags", req.IDs[0], err)
+					writeJSON(w, http.StatusInternalServerError, map[string]any{"status": "error", "message": "internal_error"})
+					return
+				}
+				writeJSON(w, http.StatusOK, map[string]any{"status": "success", "message": "updated", "count": len(req.IDs)})
+			case http.MethodDelete:
+				var req struct {
+					Name string `json:"name"`
+				}
+				if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&req); err != nil || strings.TrimSpace(req.Name) == "" {
+					writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "invalid_request"})
+					return
+				}
+				found, err := st.DeleteTag(r.Context(), req.Name)
+				if err != nil {
+					logging.LogDBOperation("delete_tag", 0, err)
+					writeJSON(w, http.StatusInternalServerError, map[string]any{"status": "error", "message": "internal_error"})
+					return
+				}
+				if !found {
+					writeJSON(w, http.StatusNotFound, map[string]any{"status": "error", "message": "not_found"})
+					return
+				}
+				writeJSON(w, http.StatusOK, map[string]any{"status": "success", "message": "deleted"})
+			default:
+				methodNotAllowed(w)
+			}
 		})
 
 		mux.HandleFunc("/api/delete", func(w http.ResponseWriter, r *http.Request) {
@@ -258,11 +690,12 @@ func New(mgr downloadManager, st *store.Store, outputDir string, opts ...Options
 				return
 			}
 
-			if err := removeTrackedFiles(outputDir, row.ArtifactPaths); err != nil {
+			root := rootFor(row)
+			if err := removeTrackedFiles(root, row.ArtifactPaths); err != nil {
 				writeJSON(w, http.StatusInternalServerError, map[string]any{"status": "error", "message": "delete_failed"})
 				return
 			}
-			if err := removeDownloadFile(outputDir, row.Filename); err != nil {
+			if err := removeDownloadFile(root, row.Filename); err != nil {
 				writeJSON(w, http.StatusInternalServerError, map[string]any{"status": "error", "message": "delete_failed"})
 				return
 			}
@@ -272,6 +705,7 @@ func New(mgr downloadManager, st *store.Store, outputDir string, opts ...Options
 				writeJSON(w, http.StatusInternalServerError, map[string]any{"status": "error", "message": "internal_error"})
 				return
 			}
+			evictThumbnails(req.ID)
 			writeJSON(w, http.StatusOK, map[string]any{"status": "success", "message": "deleted", "id": req.ID})
 		})
 
@@ -301,10 +735,61 @@ func New(mgr downloadManager, st *store.Store, outputDir string, opts ...Options
 				return
 			}
 
-			// Check if file exists in output directory
+			// Check if file exists in the row's library
 			filename := row.Filename
-			fullPath := filepath.Join(outputDir, filename)
+			fullPath := filepath.Join(rootFor(row), filename)
+			chapter := r.URL.Query().Get("chapter")
+			if chapter != "" {
+				n, err := strconv.Atoi(chapter)
+				if err != nil || n < 1 {
+					writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "invalid_chapter"})
+					return
+				}
+				piece, ok := chapterFile(row, n)
+				if !ok {
+					writeJSON(w, http.StatusNotFound, map[string]any{"status": "error", "message": "file_not_found"})
+					return
+				}
+				filename, fullPath = piece.Filename, piece.Path
+			}
 			if _, err := os.Stat(fullPath); os.IsNotExist(err) {
+				// The local copy may have been removed after upload; send the client to the remote one.
+				if chapter == "" && row.RemoteKey != "" && serverOpts.Remote != nil {
+					if rs, ok := serverOpts.Remote.(remoteStreamer); ok {
+						resp, streamed, err := rs.OpenRemote(r.Context(), row.RemoteSink, row.RemoteKey, r.Header.Get("Range"))
+						if streamed {
+							if err != nil {
+								slog.Error("failed to open remote copy", "event", "remote_open_error", "id", id, "error", err)
+								writeJSON(w, http.StatusBadGateway, map[string]any{"status": "error", "message": "remote_unavailable"})
+								return
+							}
+							defer resp.Body.Close()
+							if err := st.TouchAccessed(r.Context(), id); err != nil {
+								logging.LogDBOperation("touch_accessed", id, err)
+							}
+							for _, k := range []string{"Content-Type", "Content-Length", "Content-Range", "Accept-Ranges", "Last-Modified", "ETag"} {
+								if v := resp.Header.Get(k); v != "" {
+									w.Header().Set(k, v)
+								}
+							}
+							w.Header().Set("Content-Disposition", fmt.Sprintf("%s; filename=%q", disposition(r), filepath.Base(filename)))
+							w.WriteHeader(resp.StatusCode)
+							_, _ = io.Copy(w, resp.Body)
+							return
+						}
+					}
+					link, err := serverOpts.Remote.RemoteURL(r.Context(), row.RemoteSink, row.RemoteKey)
+					if err != nil {
+						slog.Error("failed to build remote link", "event", "remote_link_error", "id", id, "error", err)
+						writeJSON(w, http.StatusInternalServerError, map[string]any{"status": "error", "message": "internal_error"})
+						return
+					}
+					if err := st.TouchAccessed(r.Context(), id); err != nil {
+						logging.LogDBOperation("touch_accessed", id, err)
+					}
+					http.Redirect(w, r, link, http.StatusFound)
+					return
+				}
 				writeJSON(w, http.StatusNotFound, map[string]any{"status": "error", "message": "file_not_found"})
 				return
 			} else if err != nil {
@@ -316,11 +801,257 @@ func New(mgr downloadManager, st *store.Store, outputDir string, opts ...Options
 				return
 			}
 
-			// Serve the file
-			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+			if err := st.TouchAccessed(r.Context(), id); err != nil {
+				logging.LogDBOperation("touch_accessed", id, err)
+			}
+
+			w.Header().Set("Content-Disposition", fmt.Sprintf("%s; filename=%q", disposition(r), filepath.Base(filename)))
 			http.ServeFile(w, r, fullPath)
 		})
 
+		mux.HandleFunc("/api/thumbnail/", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				methodNotAllowed(w)
+				return
+			}
+			id, err := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, "/api/thumbnail/"), 10, 64)
+			if err != nil || id <= 0 {
+				writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "invalid_id"})
+				return
+			}
+			size, ok := thumbs.ParseSize(r.URL.Query().Get("size"))
+			if !ok {
+				writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "invalid_size"})
+				return
+			}
+
+			var path string
+			if serverOpts.Thumbnails != nil {
+				path, err = serverOpts.Thumbnails.Path(id, size)
+			}
+			if serverOpts.Thumbnails == nil || errors.Is(err, thumbs.ErrNotCached) {
+				row, found, lookupErr := st.GetDownloadByID(r.Context(), id)
+				if lookupErr != nil {
+					writeJSON(w, http.StatusInternalServerError, map[string]any{"status": "error", "message": "internal_error"})
+					return
+				}
+				if !found {
+					writeJSON(w, http.StatusNotFound, map[string]any{"status": "error", "message": "not_found"})
+					return
+				}
+				if serverOpts.Thumbnails == nil {
+					if row.ThumbnailURL == "" {
+						writeJSON(w, http.StatusNotFound, map[string]any{"status": "error", "message": "thumbnail_not_found"})
+						return
+					}
+					http.Redirect(w, r, row.ThumbnailURL, http.StatusFound)
+					return
+				}
+				// Rows created before the cache existed, or whose fetch failed, are
+				// filled in the background; the dashboard asks again on its next poll.
+				video := ""
+				if row.Status == "completed" && row.Filename != "" {
+					video = filepath.Join(rootFor(row), row.Filename)
+				}
+				serverOpts.Thumbnails.Enqueue(id, row.ThumbnailURL, video)
+			}
+			if errors.Is(err, thumbs.ErrNotCached) {
+				writeJSON(w, http.StatusNotFound, map[string]any{"status": "error", "message": "thumbnail_not_found"})
+				return
+			}
+			if err != nil {
+				writeJSON(w, http.StatusInternalServerError, map[string]any{"status": "error", "message": "internal_error"})
+				return
+			}
+
+			f, err := os.Open(path)
+			if err != nil {
+				writeJSON(w, http.StatusNotFound, map[string]any{"status": "error", "message": "thumbnail_not_found"})
+				return
+			}
+			defer f.Close()
+			info, err := f.Stat()
+			if err != nil {
+				writeJSON(w, http.StatusInternalServerError, map[string]any{"status": "error", "message": "internal_error"})
+				return
+			}
+			w.Header().Set("Cache-Control", "private, max-age=86400")
+			w.Header().Set("ETag", fmt.Sprintf(`"%d-%s-%x-%x"`, id, size, info.ModTime().UnixNano(), info.Size()))
+			http.ServeContent(w, r, "", info.ModTime(), f)
+		})
+
+		mux.HandleFunc("/api/transcripts/search", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				methodNotAllowed(w)
+				return
+			}
+			q := strings.TrimSpace(r.URL.Query().Get("q"))
+			if q == "" {
+				writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "invalid_request"})
+				return
+			}
+			limit := 20
+			if n, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && n > 0 {
+				limit = min(n, 100)
+			}
+			hits, err := st.SearchTranscripts(r.Context(), q, limit)
+			if err != nil {
+				logging.LogDBOperation("search_transcripts", 0, err)
+				writeJSON(w, http.StatusInternalServerError, map[string]any{"status": "error", "message": "internal_error"})
+				return
+			}
+			if hits == nil {
+				hits = []store.TranscriptHit{}
+			}
+			writeJSON(w, http.StatusOK, map[string]any{"status": "success", "results": hits})
+		})
+
+		mux.HandleFunc("/api/chapters/", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				methodNotAllowed(w)
+				return
+			}
+			id, err := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, "/api/chapters/"), 10, 64)
+			if err != nil || id <= 0 {
+				writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "invalid_id"})
+				return
+			}
+			row, found, err := st.GetDownloadByID(r.Context(), id)
+			if err != nil {
+				writeJSON(w, http.StatusInternalServerError, map[string]any{"status": "error", "message": "internal_error"})
+				return
+			}
+			if !found {
+				writeJSON(w, http.StatusNotFound, map[string]any{"status": "error", "message": "not_found"})
+				return
+			}
+			type chapterEntry struct {
+				download.ChapterFile
+				DownloadURL string `json:"download_url"`
+				PlayURL     string `json:"play_url"`
+			}
+			chapters := []chapterEntry{}
+			for _, c := range chapterFiles(row) {
+				link := fmt.Sprintf("/api/download_file?id=%d&chapter=%d", id, c.Number)
+				chapters = append(chapters, chapterEntry{ChapterFile: c, DownloadURL: link, PlayURL: link + "&inline=1"})
+			}
+			writeJSON(w, http.StatusOK, map[string]any{"status": "success", "id": id, "split_chapters": row.SplitChapters, "chapters": chapters})
+		})
+
+		// GET /api/sidecars/<id> lists a download's archival sidecars;
+		// GET /api/sidecars/<id>/<kind> serves one of them.
+		mux.HandleFunc("/api/sidecars/", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				methodNotAllowed(w)
+				return
+			}
+			idPart, kind, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/sidecars/"), "/")
+			id, err := strconv.ParseInt(idPart, 10, 64)
+			if err != nil || id <= 0 {
+				writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "invalid_id"})
+				return
+			}
+			contentType := ""
+			switch kind {
+			case "":
+			case download.SidecarDescription:
+				contentType = "text/plain; charset=utf-8"
+			case download.SidecarInfoJSON, download.SidecarComments:
+				contentType = "application/json"
+			default:
+				writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "invalid_sidecar"})
+				return
+			}
+			row, found, err := st.GetDownloadByID(r.Context(), id)
+			if err != nil {
+				writeJSON(w, http.StatusInternalServerError, map[string]any{"status": "error", "message": "internal_error"})
+				return
+			}
+			if !found {
+				writeJSON(w, http.StatusNotFound, map[string]any{"status": "error", "message": "not_found"})
+				return
+			}
+			if kind == "" {
+				type sidecarEntry struct {
+					download.Sidecar
+					URL string `json:"url"`
+				}
+				entries := []sidecarEntry{}
+				for _, sc := range sidecars(row) {
+					entries = append(entries, sidecarEntry{Sidecar: sc, URL: fmt.Sprintf("/api/sidecars/%d/%s", id, sc.Kind)})
+				}
+				writeJSON(w, http.StatusOK, map[string]any{"status": "success", "id": id, "sidecars": entries})
+				return
+			}
+			for _, sc := range sidecars(row) {
+				if sc.Kind != kind {
+					continue
+				}
+				disposition := "attachment"
+				if r.URL.Query().Get("inline") == "1" {
+					disposition = "inline"
+				}
+				w.Header().Set("Content-Type", contentType)
+				w.Header().Set("Content-Disposition", fmt.Sprintf("%s; filename=%q", disposition, sc.Filename))
+				http.ServeFile(w, r, sc.Path)
+				return
+			}
+			writeJSON(w, http.StatusNotFound, map[string]any{"status": "error", "message": "file_not_found"})
+		})
+
+		mux.HandleFunc("/api/transcripts/", func(w http.ResponseWriter, r *http.Request) {
+			rest := strings.TrimPrefix(r.URL.Path, "/api/transcripts/")
+			idPart, action, _ := strings.Cut(rest, "/")
+			id, err := strconv.ParseInt(idPart, 10, 64)
+			if err != nil || id <= 0 {
+				writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "invalid_id"})
+				return
+			}
+			switch {
+			case action == "" && r.Method == http.MethodGet:
+			case action == "index" && r.Method == http.MethodPost:
+			case action == "" || action == "index":
+				methodNotAllowed(w)
+				return
+			default:
+				http.NotFound(w, r)
+				return
+			}
+			row, found, err := st.GetDownloadByID(r.Context(), id)
+			if err != nil {
+				writeJSON(w, http.StatusInternalServerError, map[string]any{"status": "error", "message": "internal_error"})
+				return
+			}
+			if !found {
+				writeJSON(w, http.StatusNotFound, map[string]any{"status": "error", "message": "not_found"})
+				return
+			}
+			if action == "index" {
+				if serverOpts.Transcripts == nil {
+					http.NotFound(w, r)
+					return
+				}
+				if row.Status != "completed" {
+					writeJSON(w, http.StatusConflict, map[string]any{"status": "error", "message": "invalid_state"})
+					return
+				}
+				serverOpts.Transcripts.Enqueue(id)
+				writeJSON(w, http.StatusOK, map[string]any{"status": "success", "message": "queued", "id": id})
+				return
+			}
+			cues, err := st.TranscriptCues(r.Context(), id)
+			if err != nil {
+				logging.LogDBOperation("transcript_cues", id, err)
+				writeJSON(w, http.StatusInternalServerError, map[string]any{"status": "error", "message": "internal_error"})
+				return
+			}
+			if len(cues) == 0 {
+				writeJSON(w, http.StatusNotFound, map[string]any{"status": "error", "message": "transcript_not_found"})
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]any{"status": "success", "id": id, "lang": row.TranscriptLang, "cues": cues})
+		})
+
 		mux.HandleFunc("/api/downloads", func(w http.ResponseWriter, r *http.Request) {
 			if r.Method != http.MethodGet {
 				methodNotAllowed(w)
@@ -360,6 +1091,170 @@ func New(mgr downloadManager, st *store.Store, outputDir string, opts ...Options
 			writeJSON(w, http.StatusOK, response)
 		})
 
+		if serverOpts.Metadata != nil {
+			// refreshMetadata re-reads one row's metadata. source "file" reads the
+			// tags of a completed download's local file; anything else re-probes the URL.
+			refreshMetadata := func(ctx context.Context, row store.Download, source string) error {
+				var err error
+				if source == "file" {
+					if row.Status != "completed" {
+						return errRefreshInvalidState
+					}
+					if row.Filename == "" {
+						return errRefreshNoFile
+					}
+					path := filepath.Join(rootFor(row), row.Filename)
+					if _, statErr := os.Stat(path); statErr != nil {
+						return errRefreshNoFile
+					}
+					_, err = serverOpts.Metadata.RefreshMetadataFromFile(ctx, row.ID, path, st)
+				} else {
+					_, err = serverOpts.Metadata.RefreshMetadata(ctx, row.ID, row.URL, st)
+				}
+				if err != nil {
+					return err
+				}
+				// A new thumbnail URL invalidates the cached copy.
+				if serverOpts.Thumbnails != nil {
+					if updated, found, err := st.GetDownloadByID(ctx, row.ID); err == nil && found && updated.ThumbnailURL != row.ThumbnailURL {
+						if err := serverOpts.Thumbnails.Remove(row.ID); err != nil {
+							slog.Warn("failed to remove cached thumbnail", "event", "thumbnail_remove_error", "id", row.ID, "error", err)
+						}
+						serverOpts.Thumbnails.Enqueue(row.ID, updated.ThumbnailURL, "")
+					}
+				}
+				return nil
+			}
+			var bulkRefreshing atomic.Bool
+
+			mux.HandleFunc("/api/downloads/", func(w http.ResponseWriter, r *http.Request) {
+				rest := strings.TrimPrefix(r.URL.Path, "/api/downloads/")
+				var req struct {
+					IDs    []int64 `json:"ids"`
+					Stale  bool    `json:"stale"`
+					Source string  `json:"source"`
+				}
+				decode := func() bool {
+					if r.ContentLength == 0 {
+						return true
+					}
+					if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+						return false
+					}
+					return req.Source == "" || req.Source == "remote" || req.Source == "file"
+				}
+
+				if rest == "refresh-metadata" {
+					if r.Method != http.MethodPost {
+						methodNotAllowed(w)
+						return
+					}
+					if !decode() || (len(req.IDs) == 0 && !req.Stale) {
+						writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "invalid_request"})
+						return
+					}
+					ids := req.IDs
+					for _, id := range ids {
+						if id <= 0 {
+							writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "invalid_id"})
+							return
+						}
+					}
+					if req.Stale {
+						stale, err := st.ListStaleMetadata(r.Context(), maxBulkRefresh)
+						if err != nil {
+							logging.LogDBOperation("list_stale_metadata", 0, err)
+							writeJSON(w, http.StatusInternalServerError, map[string]any{"status": "error", "message": "internal_error"})
+							return
+						}
+						ids = append(ids, stale...)
+					}
+					if len(ids) > maxBulkRefresh {
+						ids = ids[:maxBulkRefresh]
+					}
+					if !bulkRefreshing.CompareAndSwap(false, true) {
+						writeJSON(w, http.StatusConflict, map[string]any{"status": "error", "message": "refresh_in_progress"})
+						return
+					}
+					go func(ids []int64, source string) {
+						defer bulkRefreshing.Store(false)
+						jobs := make(chan int64)
+						var wg sync.WaitGroup
+						for i := 0; i < bulkRefreshWorkers; i++ {
+							wg.Add(1)
+							go func() {
+								defer wg.Done()
+								for id := range jobs {
+									ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+									row, found, err := st.GetDownloadByID(ctx, id)
+									if err == nil && found {
+										err = refreshMetadata(ctx, row, source)
+									}
+									cancel()
+									if err != nil {
+										slog.Warn("metadata refresh failed", "event", "metadata_refresh_error", "db_id", id, "error", err)
+									}
+								}
+							}()
+						}
+						for _, id := range ids {
+							jobs <- id
+						}
+						close(jobs)
+						wg.Wait()
+						slog.Info("bulk metadata refresh finished", "event", "metadata_refresh_bulk", "count", len(ids))
+					}(ids, req.Source)
+					writeJSON(w, http.StatusAccepted, map[string]any{"status": "success", "message": "queued", "count": len(ids)})
+					return
+				}
+
+				idPart, action, _ := strings.Cut(rest, "/")
+				id, err := strconv.ParseInt(idPart, 10, 64)
+				if err != nil || id <= 0 || action != "refresh-metadata" {
+					http.NotFound(w, r)
+					return
+				}
+				if r.Method != http.MethodPost {
+					methodNotAllowed(w)
+					return
+				}
+				if !decode() {
+					writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "invalid_request"})
+					return
+				}
+				row, found, err := st.GetDownloadByID(r.Context(), id)
+				if err != nil {
+					writeJSON(w, http.StatusInternalServerError, map[string]any{"status": "error", "message": "internal_error"})
+					return
+				}
+				if !found {
+					writeJSON(w, http.StatusNotFound, map[string]any{"status": "error", "message": "not_found"})
+					return
+				}
+				switch err := refreshMetadata(r.Context(), row, req.Source); {
+				case errors.Is(err, errRefreshInvalidState):
+					writeJSON(w, http.StatusConflict, map[string]any{"status": "error", "message": "invalid_state"})
+					return
+				case errors.Is(err, errRefreshNoFile):
+					writeJSON(w, http.StatusNotFound, map[string]any{"status": "error", "message": "file_not_found"})
+					return
+				case errors.Is(err, download.ErrNoMediaInfo):
+					writeJSON(w, http.StatusUnprocessableEntity, map[string]any{"status": "error", "message": "no_metadata"})
+					return
+				case err != nil:
+					slog.Warn("metadata refresh failed", "event", "metadata_refresh_error", "db_id", id, "error", err)
+					writeJSON(w, http.StatusBadGateway, map[string]any{"status": "error", "message": "metadata_fetch_failed"})
+					return
+				}
+				updated, _, err := st.GetDownloadByID(r.Context(), id)
+				if err != nil {
+					writeJSON(w, http.StatusInternalServerError, map[string]any{"status": "error", "message": "internal_error"})
+					return
+				}
+				writeJSON(w, http.StatusOK, map[string]any{"status": "success", "message": "refreshed", "download": updated})
+			})
+		}
+
 		mux.HandleFunc("/api/control/pause", func(w http.ResponseWriter, r *http.Request) {
 			if r.Method != http.MethodPost {
 				methodNotAllowed(w)
@@ -484,7 +1379,7 @@ func New(mgr downloadManager, st *store.Store, outputDir string, opts ...Options
 				writeJSON(w, http.StatusNotFound, map[string]any{"status": "error", "message": "not_found"})
 				return
 			}
-			if row.Status == "pending" {
+			if row.Status == "pending" || row.Status == "deferred" {
 				writeJSON(w, http.StatusOK, map[string]any{"status": "success", "message": "already_running"})
 				return
 			}
@@ -698,9 +1593,9 @@ func New(mgr downloadManager, st *store.Store, outputDir string, opts ...Options
 			// For active cancellations, worker shutdown/cleanup is asynchronous; avoid
 			// purging files here based on a timeout-window state sample.
 			if !cancelRequested {
-				_ = removeTrackedFiles(outputDir, updated.ArtifactPaths)
+				_ = removeTrackedFiles(rootFor(updated), updated.ArtifactPaths)
 				if updated.Filename != "" {
-					_ = removeDownloadFile(outputDir, updated.Filename)
+					_ = removeDownloadFile(rootFor(updated), updated.Filename)
 				}
 			}
 			writeJSON(w, http.StatusOK, map[string]any{"status": "success", "message": "canceled", "download": updated})
@@ -729,7 +1624,15 @@ func New(mgr downloadManager, st *store.Store, outputDir string, opts ...Options
 				return
 			}
 
-			fullPath := filepath.Join(outputDir, row.Filename)
+			fullPath := filepath.Join(rootFor(row), row.Filename)
+			if req.Chapter > 0 {
+				piece, ok := chapterFile(row, req.Chapter)
+				if !ok {
+					writeJSON(w, http.StatusNotFound, map[string]any{"status": "error", "message": "file_not_found"})
+					return
+				}
+				fullPath = piece.Path
+			}
 			if _, err := os.Stat(fullPath); err != nil {
 				writeJSON(w, http.StatusNotFound, map[string]any{"status": "error", "message": "file_not_found"})
 				return
@@ -739,6 +1642,9 @@ func New(mgr downloadManager, st *store.Store, outputDir string, opts ...Options
 				writeJSON(w, http.StatusInternalServerError, map[string]any{"status": "error", "message": "player_launch_failed"})
 				return
 			}
+			if err := st.TouchAccessed(r.Context(), req.ID); err != nil {
+				logging.LogDBOperation("touch_accessed", req.ID, err)
+			}
 			writeJSON(w, http.StatusOK, map[string]any{
 				"status":   "success",
 				"message":  "play_started",
@@ -746,6 +1652,29 @@ func New(mgr downloadManager, st *store.Store, outputDir string, opts ...Options
 			})
 		})
 
+		mux.HandleFunc("/api/control/pin", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				methodNotAllowed(w)
+				return
+			}
+			var req pinRequest
+			if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&req); err != nil || req.ID <= 0 || req.Pinned == nil {
+				writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "invalid_request"})
+				return
+			}
+			ok, err := st.SetPinned(r.Context(), req.ID, *req.Pinned)
+			if err != nil {
+				logging.LogDBOperation("set_pinned", req.ID, err)
+				writeJSON(w, http.StatusInternalServerError, map[string]any{"status": "error", "message": "internal_error"})
+				return
+			}
+			if !ok {
+				writeJSON(w, http.StatusNotFound, map[string]any{"status": "error", "message": "not_found"})
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]any{"status": "success", "message": "updated", "id": req.ID, "pinned": *req.Pinned})
+		})
+
 		mux.HandleFunc("/api/ws/downloads", func(w http.ResponseWriter, r *http.Request) {
 			if r.Method != http.MethodGet {
 				methodNotAllowed(w)
@@ -774,6 +1703,14 @@ func New(mgr downloadManager, st *store.Store, outputDir string, opts ...Options
 			}
 			changes, unsubscribe := st.SubscribeChanges(512)
 			defer unsubscribe()
+			// Live events carry the item snapshot, so progress reaches clients
+			// without waiting for the next coalesced diff.
+			var events <-chan download.Event
+			if serverOpts.Events != nil {
+				sub := serverOpts.Events.Subscribe("websocket", download.SubscribeOptions{Policy: download.DropOldest, Buffer: 256})
+				defer sub.Close()
+				events = sub.Events()
+			}
 			prevRows, err := writeSnapshot()
 			if err != nil {
 				return
@@ -794,6 +1731,27 @@ func New(mgr downloadManager, st *store.Store, outputDir string, opts ...Options
 						return
 					}
 					pendingDiff = true
+				case e, ok := <-events:
+					if !ok {
+						events = nil
+						continue
+					}
+					// Only rows the client already sees, so list filters still apply.
+					row, visible := prevByID[e.Item.DBID]
+					if !visible {
+						continue
+					}
+					row = liveRow(row, e.Item)
+					prevByID[row.ID] = row
+					if err := conn.WriteJSON(map[string]any{
+						"type":    "diff",
+						"event":   e.Type,
+						"upserts": []store.Download{row},
+						"deletes": []int64{},
+						"at":      e.At.Format(time.RFC3339Nano),
+					}); err != nil {
+						return
+					}
 				case <-coalesceTicker.C:
 					if !pendingDiff {
 						continue
@@ -865,11 +1823,13 @@ func New(mgr downloadManager, st *store.Store, outputDir string, opts ...Options
 		status := strings.ToLower(strings.TrimSpace(q.Get("status")))
 		sortBy := strings.ToLower(strings.TrimSpace(q.Get("sort")))
 		order := strings.ToLower(strings.TrimSpace(q.Get("order")))
+		tag := strings.TrimSpace(q.Get("tag"))
+		search := strings.TrimSpace(q.Get("q"))
 
 		var items []*download.Item
 		if st != nil {
 			// Prefer persisted listing when DB is enabled
-			f := store.ListFilter{Status: status, Sort: sortBy, Order: order}
+			f := store.ListFilter{Status: status, Tag: tag, Query: search, Sort: sortBy, Order: order}
 			rows, err := st.ListDownloads(r.Context(), f)
 			if err != nil {
 				slog.Error("failed to list downloads for dashboard",
@@ -894,11 +1854,14 @@ func New(mgr downloadManager, st *store.Store, outputDir string, opts ...Options
 					stt = download.StatePaused
 				case "canceled":
 					stt = download.StateCanceled
+				case "deferred":
+					stt = download.StateDeferred
 				default:
 					stt = download.StateQueued
 				}
 				items = append(items, &download.Item{
 					ID:           fmt.Sprintf("%d", d.ID),
+					DBID:         d.ID,
 					URL:          d.URL,
 					Title:        d.Title,
 					Duration:     d.Duration,
@@ -906,7 +1869,17 @@ func New(mgr downloadManager, st *store.Store, outputDir string, opts ...Options
 					Progress:     d.Progress,
 					State:        stt,
 					Error:        d.ErrorMessage,
+					ErrorClass:   d.ErrorClass,
 					Filename:     d.Filename,
+					Library:      d.Library,
+
+					UploadPhase:    d.UploadPhase,
+					UploadProgress: d.UploadProgress,
+					UploadError:    d.UploadError,
+					Tags:           d.Tags,
+					Snippet:        d.Snippet,
+					Sections:       d.Sections,
+					Chapters:       download.ChapterFiles(d.ArtifactPaths),
 				})
 			}
 		} else {
@@ -976,9 +1949,24 @@ func New(mgr downloadManager, st *store.Store, outputDir string, opts ...Options
 			return
 		}
 
+		lib, ok := resolveLibrary(r.Form.Get("library"))
+		if !ok {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`<div class="text-red-600 text-sm">Unknown library</div>`))
+			return
+		}
+		tags, err := store.NormalizeTags(strings.Split(r.Form.Get("tags"), ","))
+		if err != nil {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`<div class="text-red-600 text-sm">Invalid tag</div>`))
+			return
+		}
+
 		// Check for duplicates first (before any DB write)
 		if st != nil {
-			if _, found, err := st.GetLatestDownloadByURL(r.Context(), u); err == nil && found {
+			if _, found, err := st.GetLatestDownloadBySections(r.Context(), u, nil); err == nil && found {
 				w.Header().Set("Content-Type", "text/html; charset=utf-8")
 				w.WriteHeader(http.StatusOK)
 				response := `<div class="text-blue-600 text-sm">✓ Video already exists <script>
@@ -993,13 +1981,15 @@ func New(mgr downloadManager, st *store.Store, outputDir string, opts ...Options
 		// Create minimal DB record (async pattern - no blocking on metadata)
 		if storeCreate != nil {
 			// Fast insertion: store as pending with URL as title, no metadata fetching
-			if _, err := storeCreate(r.Context(), u, u, 0, "", "pending", 0); err != nil {
+			dbid, err := storeCreate(r.Context(), u, lib, store.JobOptions{SplitChapters: r.Form.Get("split_chapters") != ""})
+			if err != nil {
 				logging.LogDBOperation("create_download", 0, err)
 				w.Header().Set("Content-Type", "text/html; charset=utf-8")
 				w.WriteHeader(http.StatusInternalServerError)
 				_, _ = w.Write([]byte(`<div class="text-red-600 text-sm">Failed to queue video</div>`))
 				return
 			}
+			tagDownloads(r.Context(), []int64{dbid}, tags)
 		}
 
 		// Return immediate success response with auto-clear and trigger queue refresh
@@ -1044,6 +2034,7 @@ func New(mgr downloadManager, st *store.Store, outputDir string, opts ...Options
 				_, _ = w.Write([]byte(`<div class="text-red-600 text-sm">Failed to remove item</div>`))
 				return
 			}
+			evictThumbnails(id)
 
 			// Return success response and trigger queue refresh
 			w.Header().Set("Content-Type", "text/html; charset=utf-8")
@@ -1055,13 +2046,56 @@ func New(mgr downloadManager, st *store.Store, outputDir string, opts ...Options
 			_, _ = w.Write([]byte(response))
 		})
 
+		// Dashboard transcript search results
+		mux.HandleFunc("/dashboard/transcripts", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				methodNotAllowed(w)
+				return
+			}
+			hits, err := st.SearchTranscripts(r.Context(), r.URL.Query().Get("q"), 10)
+			if err != nil {
+				logging.LogDBOperation("search_transcripts", 0, err)
+			}
+			results := make([]ui.TranscriptResult, 0, len(hits))
+			for _, h := range hits {
+				res := ui.TranscriptResult{DBID: h.Download.ID, Title: h.Download.Title, Playable: h.Download.Status == "completed" && h.Download.Filename != ""}
+				if res.Title == "" {
+					res.Title = h.Download.URL
+				}
+				for _, c := range h.Cues {
+					res.Cues = append(res.Cues, ui.TranscriptCue{Seconds: c.StartMS / 1000, Snippet: c.Snippet})
+				}
+				results = append(results, res)
+			}
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_ = ui.TranscriptResults(results).Render(r.Context(), w)
+		})
+
+		// Dashboard tag sidebar
+		mux.HandleFunc("/dashboard/tags", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				methodNotAllowed(w)
+				return
+			}
+			tags, err := st.ListTags(r.Context())
+			if err != nil {
+				logging.LogDBOperation("list_tags", 0, err)
+			}
+			sidebar := make([]ui.Tag, 0, len(tags))
+			for _, t := range tags {
+				sidebar = append(sidebar, ui.Tag{Name: t.Name, Count: t.Count})
+			}
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_ = ui.TagSidebar(sidebar, strings.TrimSpace(r.URL.Query().Get("tag"))).Render(r.Context(), w)
+		})
+
 		// Dashboard retry failed endpoint
 		mux.HandleFunc("/dashboard/retry_failed", func(w http.ResponseWriter, r *http.Request) {
 			if r.Method != http.MethodPost {
 				methodNotAllowed(w)
 				return
 			}
-			affected, err := st.RetryFailedDownloads(r.Context())
+			affected, err := st.RetryFailedDownloadsByTag(r.Context(), r.FormValue("tag"))
 			if err != nil {
 				logging.LogRetryFailed(0, err)
 				w.Header().Set("Content-Type", "text/html; charset=utf-8")
@@ -1080,15 +2114,356 @@ func New(mgr downloadManager, st *store.Store, outputDir string, opts ...Options
 		})
 	}
 
+	if serverOpts.Pool != nil {
+		// resizePool applies a new size and persists it so it survives restarts.
+		// Zero values keep the current setting.
+		resizePool := func(ctx context.Context, workers, queueCap int) (download.PoolSize, error) {
+			current := serverOpts.Pool.PoolSize()
+			if workers == 0 {
+				workers = current.Workers
+			}
+			if queueCap == 0 {
+				queueCap = current.QueueCapacity
+			}
+			if err := serverOpts.Pool.Resize(workers, queueCap); err != nil {
+				return current, err
+			}
+			if st != nil {
+				if err := st.SetSetting(ctx, store.SettingWorkers, strconv.Itoa(workers)); err != nil {
+					logging.LogDBOperation("set_setting", 0, err)
+				}
+				if err := st.SetSetting(ctx, store.SettingQueueCapacity, strconv.Itoa(queueCap)); err != nil {
+					logging.LogDBOperation("set_setting", 0, err)
+				}
+			}
+			return serverOpts.Pool.PoolSize(), nil
+		}
+
+		mux.HandleFunc("/api/admin/pool", func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				writeJSON(w, http.StatusOK, map[string]any{"status": "success", "pool": serverOpts.Pool.PoolSize()})
+			case http.MethodPost:
+				var req struct {
+					Workers       int `json:"workers"`
+					QueueCapacity int `json:"queue_capacity"`
+				}
+				if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&req); err != nil ||
+					req.Workers < 0 || req.QueueCapacity < 0 ||
+					req.Workers > download.MaxWorkers || req.QueueCapacity > download.MaxQueueCapacity {
+					writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "invalid_request"})
+					return
+				}
+				size, err := resizePool(r.Context(), req.Workers, req.QueueCapacity)
+				switch {
+				case errors.Is(err, download.ErrQueueTooSmall):
+					writeJSON(w, http.StatusConflict, map[string]any{"status": "error", "message": "queue_too_small", "pool": size})
+				case errors.Is(err, download.ErrShuttingDown):
+					writeJSON(w, http.StatusServiceUnavailable, map[string]any{"status": "error", "message": "shutting_down"})
+				case err != nil:
+					writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "invalid_request"})
+				default:
+					writeJSON(w, http.StatusOK, map[string]any{"status": "success", "message": "resized", "pool": size})
+				}
+			default:
+				methodNotAllowed(w)
+			}
+		})
+
+		mux.HandleFunc("/dashboard/pool", func(w http.ResponseWriter, r *http.Request) {
+			message := ""
+			size := serverOpts.Pool.PoolSize()
+			switch r.Method {
+			case http.MethodGet:
+			case http.MethodPost:
+				workers, errW := strconv.Atoi(strings.TrimSpace(r.FormValue("workers")))
+				queueCap, errQ := strconv.Atoi(strings.TrimSpace(r.FormValue("queue_capacity")))
+				if errW != nil || errQ != nil {
+					message = "Enter whole numbers for workers and queue"
+					break
+				}
+				var err error
+				size, err = resizePool(r.Context(), workers, queueCap)
+				switch {
+				case errors.Is(err, download.ErrQueueTooSmall):
+					message = "Queue cannot be smaller than the jobs already waiting"
+				case err != nil:
+					message = "Could not resize: " + err.Error()
+				default:
+					message = "Saved"
+				}
+			default:
+				methodNotAllowed(w)
+				return
+			}
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_ = ui.PoolSettings(size, message).Render(r.Context(), w)
+		})
+	}
+
+	mux.HandleFunc("/api/libraries", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"status": "success", "libraries": listLibraries()})
+	})
+
+	mux.HandleFunc("/dashboard/libraries", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = ui.LibrarySelect(listLibraries()).Render(r.Context(), w)
+	})
+
+	if serverOpts.Maintenance != nil {
+		setMaintenance := func(enabled, pauseActive bool) (download.MaintenanceStatus, error) {
+			if enabled {
+				return serverOpts.Maintenance.EnterMaintenance(pauseActive)
+			}
+			return serverOpts.Maintenance.ExitMaintenance()
+		}
+
+		mux.HandleFunc("/api/admin/maintenance", func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				writeJSON(w, http.StatusOK, map[string]any{"status": "success", "maintenance": serverOpts.Maintenance.Maintenance()})
+			case http.MethodPost:
+				var req struct {
+					Enabled     *bool `json:"enabled"`
+					PauseActive bool  `json:"pause_active"`
+				}
+				if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&req); err != nil || req.Enabled == nil {
+					writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "invalid_request"})
+					return
+				}
+				st, err := setMaintenance(*req.Enabled, req.PauseActive)
+				if err != nil {
+					slog.Error("maintenance toggle failed", "event", "maintenance_error", "enabled", *req.Enabled, "error", err)
+					writeJSON(w, http.StatusInternalServerError, map[string]any{"status": "error", "message": "internal_error", "maintenance": st})
+					return
+				}
+				writeJSON(w, http.StatusOK, map[string]any{"status": "success", "maintenance": st})
+			default:
+				methodNotAllowed(w)
+			}
+		})
+
+		mux.HandleFunc("/dashboard/maintenance", func(w http.ResponseWriter, r *http.Request) {
+			message := ""
+			st := serverOpts.Maintenance.Maintenance()
+			switch r.Method {
+			case http.MethodGet:
+			case http.MethodPost:
+				enabled := r.FormValue("enabled") == "true"
+				var err error
+				st, err = setMaintenance(enabled, r.FormValue("pause_active") == "true")
+				if err != nil {
+					message = "Could not save: " + err.Error()
+				}
+			default:
+				methodNotAllowed(w)
+				return
+			}
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_ = ui.MaintenanceToggle(st, message).Render(r.Context(), w)
+		})
+	}
+
+	if serverOpts.Breakers != nil {
+		mux.HandleFunc("/api/admin/breakers", func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				writeJSON(w, http.StatusOK, map[string]any{"status": "success", "breakers": serverOpts.Breakers.Breakers()})
+			case http.MethodPost:
+				// Closing a breaker by hand requeues the host's deferred jobs right away.
+				var req struct {
+					Host string `json:"host"`
+				}
+				if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&req); err != nil || strings.TrimSpace(req.Host) == "" {
+					writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "invalid_request"})
+					return
+				}
+				if !serverOpts.Breakers.ResetBreaker(req.Host) {
+					writeJSON(w, http.StatusNotFound, map[string]any{"status": "error", "message": "not_found"})
+					return
+				}
+				writeJSON(w, http.StatusOK, map[string]any{"status": "success", "message": "reset", "breakers": serverOpts.Breakers.Breakers()})
+			default:
+				methodNotAllowed(w)
+			}
+		})
+	}
+
+	if serverOpts.Prober != nil {
+		mux.HandleFunc("/api/probe", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				methodNotAllowed(w)
+				return
+			}
+			u := strings.TrimSpace(r.URL.Query().Get("url"))
+			if u == "" {
+				writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "invalid_request"})
+				return
+			}
+			if !validURL(u) {
+				writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "invalid_url"})
+				return
+			}
+			probe, err := serverOpts.Prober.Probe(r.Context(), u)
+			switch {
+			case errors.Is(err, download.ErrNoMediaInfo):
+				writeJSON(w, http.StatusUnprocessableEntity, map[string]any{"status": "error", "message": "no_metadata"})
+				return
+			case errors.Is(err, download.ErrTimeout):
+				writeJSON(w, http.StatusGatewayTimeout, map[string]any{"status": "error", "message": "timeout"})
+				return
+			case err != nil:
+				slog.Warn("probe failed", "event", "probe_error", "url", logging.RedactURL(u), "error", err)
+				writeJSON(w, http.StatusBadGateway, map[string]any{"status": "error", "message": "metadata_fetch_failed"})
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]any{"status": "success", "probe": probe})
+		})
+	}
+
+	if serverOpts.Metrics != nil {
+		mux.HandleFunc("/api/metrics", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				methodNotAllowed(w)
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]any{"status": "success", "metrics": serverOpts.Metrics.Metrics()})
+		})
+	}
+
 	// Static files
 	mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("./static/"))))
 
 	// Healthcheck
+	if serverOpts.Retention != nil {
+		runRetention := func(w http.ResponseWriter, r *http.Request, dryRun bool) {
+			report, err := serverOpts.Retention.Run(r.Context(), dryRun)
+			if err != nil {
+				slog.Error("retention run failed", "event", "retention_error", "dry_run", dryRun, "error", err)
+				writeJSON(w, http.StatusInternalServerError, map[string]any{"status": "error", "message": "internal_error"})
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]any{"status": "success", "report": report})
+		}
+		mux.HandleFunc("/api/retention/preview", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				methodNotAllowed(w)
+				return
+			}
+			runRetention(w, r, true)
+		})
+		mux.HandleFunc("/api/retention/run", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				methodNotAllowed(w)
+				return
+			}
+			runRetention(w, r, false)
+		})
+	}
+
+	if serverOpts.TempGC != nil {
+		mux.HandleFunc("/api/admin/tempgc", func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				writeJSON(w, http.StatusOK, map[string]any{"status": "success", "report": serverOpts.TempGC.LastReport()})
+			case http.MethodPost:
+				report := serverOpts.TempGC.RunOnce(r.Context())
+				writeJSON(w, http.StatusOK, map[string]any{"status": "success", "report": report})
+			default:
+				methodNotAllowed(w)
+			}
+		})
+	}
+
+	if serverOpts.YTDLP != nil {
+		mux.HandleFunc("/api/admin/ytdlp", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				methodNotAllowed(w)
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]any{"status": "success", "ytdlp": serverOpts.YTDLP.Status()})
+		})
+		mux.HandleFunc("/api/admin/ytdlp/update", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				methodNotAllowed(w)
+				return
+			}
+			var req struct {
+				Method string `json:"method"`
+			}
+			if r.ContentLength != 0 {
+				if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+					writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "invalid_request"})
+					return
+				}
+			}
+			result, err := serverOpts.YTDLP.Update(r.Context(), strings.TrimSpace(req.Method))
+			switch {
+			case errors.Is(err, download.ErrUnknownUpdateMethod):
+				writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "invalid_request"})
+			case errors.Is(err, download.ErrUpdateInProgress):
+				writeJSON(w, http.StatusConflict, map[string]any{"status": "error", "message": "update_in_progress"})
+			case err != nil:
+				writeJSON(w, http.StatusBadGateway, map[string]any{"status": "error", "message": "update_failed", "result": result})
+			default:
+				writeJSON(w, http.StatusOK, map[string]any{"status": "success", "message": "updated", "result": result})
+			}
+		})
+	}
+
 	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte("ok"))
 	})
 
+	mux.HandleFunc("/api/health", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w)
+			return
+		}
+		conditions := collectHealthConditions(serverOpts)
+		response := map[string]any{
+			"status":     "success",
+			"healthy":    len(conditions) == 0,
+			"conditions": conditions,
+		}
+		if serverOpts.Disk != nil {
+			response["disk"] = serverOpts.Disk.Status()
+		}
+		if serverOpts.YTDLP != nil {
+			response["ytdlp"] = serverOpts.YTDLP.Status()
+		}
+		if serverOpts.Breakers != nil {
+			response["breakers"] = serverOpts.Breakers.Breakers()
+		}
+		if serverOpts.MetadataPool != nil {
+			response["metadata_pool"] = serverOpts.MetadataPool.MetadataPool()
+		}
+		writeJSON(w, http.StatusOK, response)
+	})
+
+	mux.HandleFunc("/dashboard/health", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w)
+			return
+		}
+		conditions := collectHealthConditions(serverOpts)
+		banner := make([]ui.Condition, 0, len(conditions))
+		for _, c := range conditions {
+			banner = append(banner, ui.Condition{Name: c.Name, Message: c.Message})
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = ui.HealthBanner(banner).Render(context.Background(), w)
+	})
+
 	// Add minimal logging + recover
 	return recoverer(logger(mux))
 }
@@ -1099,6 +2474,74 @@ func methodNotAllowed(w http.ResponseWriter) {
 	writeJSON(w, http.StatusMethodNotAllowed, map[string]any{"status": "error", "message": "method_not_allowed"})
 }
 
+// disposition is how a served file is presented; inline=1 lets browsers play
+// it, e.g. at a #t= timestamp.
+func disposition(r *http.Request) string {
+	if r.URL.Query().Get("inline") == "1" {
+		return "inline"
+	}
+	return "attachment"
+}
+
+func collectHealthConditions(opts Options) []healthCondition {
+	conditions := make([]healthCondition, 0, 3)
+	if opts.Maintenance != nil {
+		if st := opts.Maintenance.Maintenance(); st.Active {
+			conditions = append(conditions, healthCondition{
+				Name:    download.ReasonMaintenance,
+				Message: "Maintenance mode is on; new downloads are held until it is turned off",
+			})
+		}
+	}
+	if opts.Disk != nil {
+		if disk := opts.Disk.Status(); disk.Low {
+			conditions = append(conditions, healthCondition{
+				Name: download.ReasonDiskLow,
+				Message: fmt.Sprintf("Free space %s is below the %s reserve; downloads are paused",
+					formatBytes(disk.FreeBytes), formatBytes(disk.ReserveBytes)),
+			})
+		}
+	}
+	if opts.Breakers != nil {
+		for _, b := range opts.Breakers.Breakers() {
+			if b.State == download.BreakerClosed {
+				continue
+			}
+			msg := fmt.Sprintf("Downloads from %s failed %d times in a row; %d deferred", b.Host, b.ConsecutiveFailures, b.Deferred)
+			if b.State == download.BreakerHalfOpen {
+				msg += ", probing with one download"
+			} else if b.RetryAt != nil {
+				msg += ", next probe at " + b.RetryAt.Local().Format("15:04:05")
+			}
+			conditions = append(conditions, healthCondition{Name: download.ReasonCircuitOpen, Message: msg})
+		}
+	}
+	if opts.YTDLP != nil {
+		if st := opts.YTDLP.Status(); st.UpdateRecommended {
+			conditions = append(conditions, healthCondition{
+				Name: download.ReasonYTDLPUpdate,
+				Message: fmt.Sprintf("%d recent failures look like extractor breakage in yt-dlp %s; an update is recommended",
+					st.RecentBreakages, st.Version),
+			})
+		}
+	}
+	return conditions
+}
+
+// formatBytes renders a byte count with binary units for operator-facing messages.
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
 func writeJSON(w http.ResponseWriter, code int, v any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(code)
@@ -1107,6 +2550,13 @@ func writeJSON(w http.ResponseWriter, code int, v any) {
 
 type controlRequest struct {
 	ID int64 `json:"id"`
+	// Chapter selects a piece of a download split by chapters (play only); 0 is the full file.
+	Chapter int `json:"chapter,omitempty"`
+}
+
+type pinRequest struct {
+	ID     int64 `json:"id"`
+	Pinned *bool `json:"pinned"`
 }
 
 func parseControlRequest(w http.ResponseWriter, r *http.Request) (controlRequest, bool) {
@@ -1121,6 +2571,8 @@ func parseControlRequest(w http.ResponseWriter, r *http.Request) (controlRequest
 func parseListFilter(q url.Values) store.ListFilter {
 	f := store.ListFilter{
 		Status: q.Get("status"),
+		Tag:    q.Get("tag"),
+		Query:  q.Get("q"),
 		Sort:   q.Get("sort"),
 		Order:  q.Get("order"),
 	}
@@ -1177,6 +2629,23 @@ func buildDownloadsDiff(prev, current map[int64]store.Download) downloadsDiff {
 	return diff
 }
 
+// liveRow applies a live event's item snapshot to the row a client last saw,
+// so event frames carry rows of the same shape as snapshot and diff frames.
+func liveRow(row store.Download, it download.Item) store.Download {
+	row.Progress = it.Progress
+	if it.State != "" {
+		row.Status = download.StoreStatus(it.State)
+		row.ErrorMessage, row.ErrorClass = it.Error, it.ErrorClass
+	}
+	if it.Title != "" {
+		row.Title, row.Duration, row.ThumbnailURL = it.Title, it.Duration, it.ThumbnailURL
+	}
+	if it.Filename != "" {
+		row.Filename = it.Filename
+	}
+	return row
+}
+
 func downloadsEqual(a, b store.Download) bool {
 	if a.ID != b.ID ||
 		a.URL != b.URL ||
@@ -1187,11 +2656,16 @@ func downloadsEqual(a, b store.Download) bool {
 		a.Progress != b.Progress ||
 		a.Filename != b.Filename ||
 		a.ErrorMessage != b.ErrorMessage ||
+		a.ErrorClass != b.ErrorClass ||
+		a.Pinned != b.Pinned ||
+		a.RetentionReason != b.RetentionReason ||
+		a.Uploader != b.Uploader ||
+		a.Snippet != b.Snippet ||
 		!a.CreatedAt.Equal(b.CreatedAt) ||
 		!a.UpdatedAt.Equal(b.UpdatedAt) {
 		return false
 	}
-	if len(a.ArtifactPaths) != len(b.ArtifactPaths) {
+	if len(a.ArtifactPaths) != len(b.ArtifactPaths) || len(a.Tags) != len(b.Tags) {
 		return false
 	}
 	for i := range a.ArtifactPaths {
@@ -1199,6 +2673,11 @@ func downloadsEqual(a, b store.Download) bool {
 			return false
 		}
 	}
+	for i := range a.Tags {
+		if a.Tags[i] != b.Tags[i] {
+			return false
+		}
+	}
 	return true
 }
 
@@ -1251,6 +2730,21 @@ func validURL(u string) bool {
 	return true
 }
 
+// parseMaxDuration reads an optional per-request job time limit such as "45m".
+// Empty means no override; limits are stored in whole seconds, so anything
+// under a second is rejected.
+func parseMaxDuration(s string) (time.Duration, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, true
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < time.Second {
+		return 0, false
+	}
+	return d, true
+}
+
 func isAllowedWebSocketOrigin(r *http.Request) bool {
 	origin := strings.TrimSpace(r.Header.Get("Origin"))
 	if origin == "" {
@@ -1440,8 +2934,8 @@ func logger(next http.Handler) http.Handler {
 		start := time.Now()
 		rec := newResponseRecorder(w)
 		next.ServeHTTP(rec, r)
-		// Skip noisy log line for HTMX row polling endpoint
-		if r.URL.Path == "/dashboard/rows" {
+		// Skip noisy log lines for HTMX polling endpoints
+		if r.URL.Path == "/dashboard/rows" || r.URL.Path == "/dashboard/health" {
 			return
 		}
 		logging.LogHTTPRequest(r.Method, r.URL.Path, r.RemoteAddr, time.Since(start), rec.statusCode, rec.bytesWritten)
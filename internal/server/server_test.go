@@ -4,7 +4,10 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"image"
+	"image/png"
 	"io"
 	"log/slog"
 	"net"
@@ -13,13 +16,16 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/gorilla/websocket"
 	"videofetch/internal/download"
 	"videofetch/internal/logging"
+	"videofetch/internal/retention"
 	"videofetch/internal/store"
+	"videofetch/internal/thumbs"
 )
 
 // helpers
@@ -340,6 +346,63 @@ func TestHealthz_OK(t *testing.T) {
 	}
 }
 
+type staticDisk struct{ status download.DiskStatus }
+
+func (s staticDisk) Status() download.DiskStatus { return s.status }
+
+func TestHealthAPI_ReportsDiskLow(t *testing.T) {
+	mgr := &mockMgr{enqueueFn: func(url string) (string, error) { return "", nil }, snapshotFn: func(id string) []*download.Item { return nil }}
+	h := New(mgr, nil, "/tmp/test", Options{Disk: staticDisk{status: download.DiskStatus{
+		Path:         "/tmp/test",
+		FreeBytes:    512 << 20,
+		ReserveBytes: 1 << 30,
+		Low:          true,
+	}}})
+
+	w := doJSON(t, h, http.MethodGet, "/api/health", "", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Healthy    bool `json:"healthy"`
+		Conditions []struct {
+			Name    string `json:"name"`
+			Message string `json:"message"`
+		} `json:"conditions"`
+		Disk download.DiskStatus `json:"disk"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Healthy || len(resp.Conditions) != 1 || resp.Conditions[0].Name != "disk_low" {
+		t.Fatalf("expected disk_low condition, got %+v", resp)
+	}
+	if !strings.Contains(resp.Conditions[0].Message, "512.0 MiB") {
+		t.Fatalf("expected human-readable free space in message, got %q", resp.Conditions[0].Message)
+	}
+	if !resp.Disk.Low || resp.Disk.ReserveBytes != 1<<30 {
+		t.Fatalf("expected disk status in response, got %+v", resp.Disk)
+	}
+
+	banner := doJSON(t, h, http.MethodGet, "/dashboard/health", "", nil)
+	if !strings.Contains(banner.Body.String(), `data-condition="disk_low"`) {
+		t.Fatalf("expected dashboard banner for disk_low, got %q", banner.Body.String())
+	}
+}
+
+func TestHealthAPI_HealthyWithoutConditions(t *testing.T) {
+	h := New(&mockMgr{enqueueFn: func(url string) (string, error) { return "", nil }, snapshotFn: func(id string) []*download.Item { return nil }}, nil, "/tmp/test")
+	w := doJSON(t, h, http.MethodGet, "/api/health", "", nil)
+	var resp map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusOK || resp["healthy"] != true {
+		t.Fatalf("expected healthy response, code=%d body=%s", w.Code, w.Body.String())
+	}
+	if _, ok := resp["disk"]; ok {
+		t.Fatalf("expected no disk section without a disk source")
+	}
+}
+
 func TestDownloadSingle_DuplicateCompleted(t *testing.T) {
 	// Create test store with completed download
 	testStore := setupTestServerStore(t)
@@ -1607,6 +1670,57 @@ func TestWebsocketDownloads_DiffEvents(t *testing.T) {
 	}
 }
 
+func TestWebsocketDownloads_ForwardsLiveEventsForVisibleRows(t *testing.T) {
+	testStore := setupTestServerStore(t)
+	defer testStore.Close()
+	ctx := context.Background()
+	id, err := testStore.CreateDownload(ctx, "https://example.com/video", "Video", 0, "", "downloading", 0)
+	if err != nil {
+		t.Fatalf("CreateDownload() failed: %v", err)
+	}
+
+	bus := download.NewEventBus()
+	defer bus.Close()
+	h := New(&mockMgr{
+		enqueueFn:  func(url string) (string, error) { return "", nil },
+		snapshotFn: func(id string) []*download.Item { return nil },
+	}, testStore, t.TempDir(), Options{Events: bus})
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/api/ws/downloads?limit=50"
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial websocket failed: %v", err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	var snapshot map[string]any
+	if err := conn.ReadJSON(&snapshot); err != nil || snapshot["type"] != "snapshot" {
+		t.Fatalf("expected initial snapshot, got %+v (%v)", snapshot, err)
+	}
+
+	bus.Publish(download.Event{Type: download.EventProgress, Item: download.Item{DBID: id + 100, Progress: 7}})
+	bus.Publish(download.Event{Type: download.EventProgress, Item: download.Item{DBID: id, Progress: 42}})
+
+	var msg struct {
+		Type    string           `json:"type"`
+		Event   string           `json:"event"`
+		Upserts []store.Download `json:"upserts"`
+	}
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("read ws message failed: %v", err)
+	}
+	if msg.Type != "diff" || msg.Event != "progress" || len(msg.Upserts) != 1 {
+		t.Fatalf("expected a one-row diff for the visible row only, got %+v", msg)
+	}
+	// The row has the snapshot's shape, with the live progress applied.
+	if row := msg.Upserts[0]; row.ID != id || row.Progress != 42 || row.URL == "" || row.Status == "" {
+		t.Fatalf("expected the stored row with live progress, got %+v", row)
+	}
+}
+
 func TestWebsocketDownloads_CoalescesBurstChanges(t *testing.T) {
 	testStore := setupTestServerStore(t)
 	defer testStore.Close()
@@ -1735,6 +1849,276 @@ func latestHTTPAccessLog(t *testing.T, logs string) (status int, responseBytes i
 	return 0, 0, false
 }
 
+func TestPinAndRetentionPreview(t *testing.T) {
+	st := setupTestServerStore(t)
+	defer st.Close()
+	outDir := t.TempDir()
+	ctx := context.Background()
+
+	ids := make([]int64, 0, 2)
+	for _, name := range []string{"a.mp4", "b.mp4"} {
+		id, err := st.CreateDownload(ctx, "https://example.com/"+name, name, 0, "", "completed", 100)
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		_ = st.UpdateFilename(ctx, id, name)
+		ids = append(ids, id)
+	}
+
+	janitor := retention.New(st, download.NewDownloader(outDir), outDir, retention.Policy{MaxAge: time.Nanosecond})
+	mgr := &mockMgr{enqueueFn: func(url string) (string, error) { return "", nil }, snapshotFn: func(id string) []*download.Item { return nil }}
+	h := New(mgr, st, outDir, Options{Retention: janitor})
+
+	w := doJSON(t, h, http.MethodPost, "/api/control/pin", "", map[string]any{"id": ids[0], "pinned": true})
+	if w.Code != http.StatusOK {
+		t.Fatalf("pin status=%d body=%s", w.Code, w.Body.String())
+	}
+	if w := doJSON(t, h, http.MethodPost, "/api/control/pin", "", map[string]any{"id": ids[0]}); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without pinned field, got %d", w.Code)
+	}
+	if w := doJSON(t, h, http.MethodPost, "/api/control/pin", "", map[string]any{"id": 9999, "pinned": true}); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown id, got %d", w.Code)
+	}
+
+	w = doJSON(t, h, http.MethodGet, "/api/retention/preview", "", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("preview status=%d body=%s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Report retention.Report `json:"report"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !resp.Report.DryRun || len(resp.Report.Candidates) != 1 || resp.Report.Candidates[0].ID != ids[1] {
+		t.Fatalf("expected only unpinned row in preview, got %+v", resp.Report)
+	}
+	row, _, _ := st.GetDownloadByID(ctx, ids[1])
+	if row.RetentionReason != retention.ReasonMaxAge {
+		t.Fatalf("expected preview to record reason on row, got %q", row.RetentionReason)
+	}
+
+	if w := doJSON(t, h, http.MethodGet, "/api/retention/run", "", nil); w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405 for GET run, got %d", w.Code)
+	}
+	w = doJSON(t, h, http.MethodPost, "/api/retention/run", "", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("run status=%d body=%s", w.Code, w.Body.String())
+	}
+	if _, found, _ := st.GetDownloadByID(ctx, ids[1]); found {
+		t.Fatalf("expected unpinned row removed")
+	}
+	if _, found, _ := st.GetDownloadByID(ctx, ids[0]); !found {
+		t.Fatalf("expected pinned row kept")
+	}
+}
+
+type stubTempGC struct {
+	last *download.TempGCReport
+	runs int
+}
+
+func (s *stubTempGC) LastReport() *download.TempGCReport { return s.last }
+
+func (s *stubTempGC) RunOnce(ctx context.Context) download.TempGCReport {
+	s.runs++
+	r := download.TempGCReport{ReclaimedBytes: 42, Removed: []download.OrphanEntry{{Path: "/x/.yt-dlp-tmp/a", Kind: "temp_dir", SizeBytes: 42}}}
+	s.last = &r
+	return r
+}
+
+func TestAdminTempGC_GetAndRun(t *testing.T) {
+	gc := &stubTempGC{}
+	mgr := &mockMgr{enqueueFn: func(url string) (string, error) { return "", nil }, snapshotFn: func(id string) []*download.Item { return nil }}
+	h := New(mgr, nil, "/tmp/test", Options{TempGC: gc})
+
+	w := doJSON(t, h, http.MethodGet, "/api/admin/tempgc", "", nil)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"report":null`) {
+		t.Fatalf("expected empty report before first run, code=%d body=%s", w.Code, w.Body.String())
+	}
+
+	w = doJSON(t, h, http.MethodPost, "/api/admin/tempgc", "", nil)
+	var resp struct {
+		Report download.TempGCReport `json:"report"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if gc.runs != 1 || resp.Report.ReclaimedBytes != 42 || len(resp.Report.Removed) != 1 {
+		t.Fatalf("expected run report, got runs=%d report=%+v", gc.runs, resp.Report)
+	}
+
+	if w := doJSON(t, h, http.MethodDelete, "/api/admin/tempgc", "", nil); w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", w.Code)
+	}
+}
+
+type stubYTDLP struct {
+	status    download.YTDLPStatus
+	updateErr error
+	methods   []string
+}
+
+func (s *stubYTDLP) Status() download.YTDLPStatus { return s.status }
+
+func (s *stubYTDLP) Update(ctx context.Context, method string) (download.YTDLPUpdateResult, error) {
+	s.methods = append(s.methods, method)
+	return download.YTDLPUpdateResult{Method: method, FromVersion: "2024.01.01", ToVersion: "2025.01.01"}, s.updateErr
+}
+
+func TestYTDLPAdmin_StatusHealthAndUpdate(t *testing.T) {
+	tool := &stubYTDLP{status: download.YTDLPStatus{Version: "2024.01.01", UpdateRecommended: true, RecentBreakages: 4}}
+	mgr := &mockMgr{enqueueFn: func(url string) (string, error) { return "", nil }, snapshotFn: func(id string) []*download.Item { return nil }}
+	h := New(mgr, nil, "/tmp/test", Options{YTDLP: tool})
+
+	w := doJSON(t, h, http.MethodGet, "/api/health", "", nil)
+	if !strings.Contains(w.Body.String(), `"name":"ytdlp_update_recommended"`) || !strings.Contains(w.Body.String(), `"version":"2024.01.01"`) {
+		t.Fatalf("expected update recommendation and version in health, got %s", w.Body.String())
+	}
+
+	w = doJSON(t, h, http.MethodPost, "/api/admin/ytdlp/update", "", nil)
+	if w.Code != http.StatusOK || len(tool.methods) != 1 || tool.methods[0] != "" {
+		t.Fatalf("expected default update to succeed, code=%d body=%s methods=%v", w.Code, w.Body.String(), tool.methods)
+	}
+	w = doJSON(t, h, http.MethodPost, "/api/admin/ytdlp/update", "", map[string]any{"method": "self"})
+	if w.Code != http.StatusOK || tool.methods[1] != "self" {
+		t.Fatalf("expected self update, code=%d methods=%v", w.Code, tool.methods)
+	}
+
+	for _, tc := range []struct {
+		err  error
+		code int
+		msg  string
+	}{
+		{download.ErrUpdateInProgress, http.StatusConflict, "update_in_progress"},
+		{download.ErrUnknownUpdateMethod, http.StatusBadRequest, "invalid_request"},
+		{errors.New("boom"), http.StatusBadGateway, "update_failed"},
+	} {
+		tool.updateErr = tc.err
+		w = doJSON(t, h, http.MethodPost, "/api/admin/ytdlp/update", "", map[string]any{"method": "release"})
+		var resp map[string]any
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		if w.Code != tc.code || resp["message"] != tc.msg {
+			t.Fatalf("err=%v: expected %d/%s, got %d %s", tc.err, tc.code, tc.msg, w.Code, w.Body.String())
+		}
+	}
+}
+
+type stubPool struct {
+	size      download.PoolSize
+	resizeErr error
+}
+
+func (s *stubPool) PoolSize() download.PoolSize { return s.size }
+
+func (s *stubPool) Resize(workers, queueCap int) error {
+	if s.resizeErr != nil {
+		return s.resizeErr
+	}
+	s.size.Workers, s.size.QueueCapacity = workers, queueCap
+	return nil
+}
+
+func TestAdminPool_ResizePersistsSettings(t *testing.T) {
+	st := setupTestServerStore(t)
+	defer st.Close()
+	pool := &stubPool{size: download.PoolSize{Workers: 2, QueueCapacity: 16}}
+	mgr := &mockMgr{enqueueFn: func(url string) (string, error) { return "", nil }, snapshotFn: func(id string) []*download.Item { return nil }}
+	h := New(mgr, st, "/tmp/test", Options{Pool: pool})
+
+	w := doJSON(t, h, http.MethodPost, "/api/admin/pool", "", map[string]any{"workers": 6})
+	if w.Code != http.StatusOK {
+		t.Fatalf("resize status=%d body=%s", w.Code, w.Body.String())
+	}
+	if pool.size.Workers != 6 || pool.size.QueueCapacity != 16 {
+		t.Fatalf("expected workers changed and queue kept, got %+v", pool.size)
+	}
+	for key, want := range map[string]string{store.SettingWorkers: "6", store.SettingQueueCapacity: "16"} {
+		if got, found, _ := st.GetSetting(context.Background(), key); !found || got != want {
+			t.Fatalf("expected persisted %s=%s, got %q (found=%v)", key, want, got, found)
+		}
+	}
+
+	pool.resizeErr = download.ErrQueueTooSmall
+	w = doJSON(t, h, http.MethodPost, "/api/admin/pool", "", map[string]any{"queue_capacity": 1})
+	if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), "queue_too_small") {
+		t.Fatalf("expected queue_too_small conflict, got %d %s", w.Code, w.Body.String())
+	}
+	if w := doJSON(t, h, http.MethodPost, "/api/admin/pool", "", map[string]any{"workers": -1}); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for negative workers, got %d", w.Code)
+	}
+	pool.resizeErr = nil
+	if w := doJSON(t, h, http.MethodPost, "/api/admin/pool", "", map[string]any{"queue_capacity": download.MaxQueueCapacity + 1}); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 above the queue maximum, got %d", w.Code)
+	}
+	if got, _, _ := st.GetSetting(context.Background(), store.SettingQueueCapacity); got != "16" {
+		t.Fatalf("expected rejected size not persisted, got %q", got)
+	}
+}
+
+func TestDashboardPool_FormResize(t *testing.T) {
+	pool := &stubPool{size: download.PoolSize{Workers: 2, QueueCapacity: 16}}
+	mgr := &mockMgr{enqueueFn: func(url string) (string, error) { return "", nil }, snapshotFn: func(id string) []*download.Item { return nil }}
+	h := New(mgr, nil, "/tmp/test", Options{Pool: pool})
+
+	req := httptest.NewRequest(http.MethodPost, "/dashboard/pool", strings.NewReader("workers=3&queue_capacity=32"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || pool.size.Workers != 3 || pool.size.QueueCapacity != 32 {
+		t.Fatalf("expected form resize, code=%d size=%+v", w.Code, pool.size)
+	}
+	if !strings.Contains(w.Body.String(), `value="3"`) || !strings.Contains(w.Body.String(), "Saved") {
+		t.Fatalf("expected updated form in response, got %s", w.Body.String())
+	}
+}
+
+type stubMaintenance struct {
+	status      download.MaintenanceStatus
+	pauseActive bool
+}
+
+func (s *stubMaintenance) Maintenance() download.MaintenanceStatus { return s.status }
+
+func (s *stubMaintenance) EnterMaintenance(pauseActive bool) (download.MaintenanceStatus, error) {
+	s.status.Active, s.pauseActive = true, pauseActive
+	return s.status, nil
+}
+
+func (s *stubMaintenance) ExitMaintenance() (download.MaintenanceStatus, error) {
+	s.status.Active = false
+	return s.status, nil
+}
+
+func TestAdminMaintenance_ToggleAndHealth(t *testing.T) {
+	maint := &stubMaintenance{}
+	mgr := &mockMgr{enqueueFn: func(url string) (string, error) { return "", nil }, snapshotFn: func(id string) []*download.Item { return nil }}
+	h := New(mgr, nil, "/tmp/test", Options{Maintenance: maint})
+
+	if w := doJSON(t, h, http.MethodPost, "/api/admin/maintenance", "", map[string]any{"pause_active": true}); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without enabled, got %d", w.Code)
+	}
+	w := doJSON(t, h, http.MethodPost, "/api/admin/maintenance", "", map[string]any{"enabled": true, "pause_active": true})
+	if w.Code != http.StatusOK || !maint.status.Active || !maint.pauseActive {
+		t.Fatalf("expected maintenance on, code=%d body=%s", w.Code, w.Body.String())
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/health", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if !strings.Contains(rec.Body.String(), `"maintenance"`) {
+		t.Fatalf("expected maintenance health condition, got %s", rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/dashboard/maintenance", strings.NewReader("enabled=false"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || maint.status.Active || !strings.Contains(rec.Body.String(), "Enter maintenance") {
+		t.Fatalf("expected dashboard toggle off, code=%d body=%s", rec.Code, rec.Body.String())
+	}
+}
+
 // setupTestServerStore creates an in-memory test store
 func setupTestServerStore(t *testing.T) *store.Store {
 	tempDir := t.TempDir()
@@ -1747,3 +2131,1057 @@ func setupTestServerStore(t *testing.T) *store.Store {
 
 	return testStore
 }
+
+type stubLibraries struct {
+	libs []download.Library
+}
+
+func (s *stubLibraries) Libraries() []download.Library { return s.libs }
+
+func (s *stubLibraries) Library(name string) (download.Library, bool) {
+	if name == "" {
+		name = download.DefaultLibraryName
+	}
+	for _, lib := range s.libs {
+		if lib.Name == name {
+			return lib, true
+		}
+	}
+	return download.Library{}, false
+}
+
+func TestLibraries_EnqueueAndResolveFilesPerRow(t *testing.T) {
+	st := setupTestServerStore(t)
+	defer st.Close()
+	ctx := context.Background()
+	outDir, musicDir := t.TempDir(), t.TempDir()
+	libs := &stubLibraries{libs: []download.Library{
+		{Name: download.DefaultLibraryName, Root: outDir},
+		{Name: "music", Root: musicDir},
+	}}
+	mgr := &mockMgr{enqueueFn: func(url string) (string, error) { return "", nil }, snapshotFn: func(id string) []*download.Item { return nil }}
+	h := New(mgr, st, outDir, Options{Libraries: libs})
+
+	w := doJSON(t, h, http.MethodGet, "/api/libraries", "", nil)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"music"`) {
+		t.Fatalf("libraries status=%d body=%s", w.Code, w.Body.String())
+	}
+
+	for _, body := range []map[string]any{
+		{"url": "https://example.com/a", "library": "/etc"},
+		{"url": "https://example.com/a", "library": "videos"},
+	} {
+		if w := doJSON(t, h, http.MethodPost, "/api/download_single", "", body); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "unknown_library") {
+			t.Fatalf("expected unknown_library for %v, got %d %s", body["library"], w.Code, w.Body.String())
+		}
+	}
+	if w := doJSON(t, h, http.MethodPost, "/api/download", "", map[string]any{"urls": []string{"https://example.com/a"}, "library": "nope"}); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected batch with unknown library rejected, got %d", w.Code)
+	}
+
+	w = doJSON(t, h, http.MethodPost, "/api/download_single", "", map[string]any{"url": "https://example.com/song", "library": "music"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("enqueue status=%d body=%s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		DBID int64 `json:"db_id"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	row, found, _ := st.GetDownloadByID(ctx, resp.DBID)
+	if !found || row.Library != "music" || row.LibraryRoot != musicDir {
+		t.Fatalf("expected row bound to music library, got %+v", row)
+	}
+
+	// Complete the row with a file that only exists under the music root.
+	_ = st.UpdateStatus(ctx, row.ID, "completed", "")
+	_ = st.UpdateFilename(ctx, row.ID, "song.opus")
+	if err := os.WriteFile(filepath.Join(musicDir, "song.opus"), []byte("ok"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/download_file?id=%d", row.ID), nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || rec.Body.String() != "ok" {
+		t.Fatalf("expected file served from library root, got %d %q", rec.Code, rec.Body.String())
+	}
+
+	if w := doJSON(t, h, http.MethodDelete, "/api/delete", "", map[string]any{"id": row.ID}); w.Code != http.StatusOK {
+		t.Fatalf("delete status=%d body=%s", w.Code, w.Body.String())
+	}
+	if _, err := os.Stat(filepath.Join(musicDir, "song.opus")); !os.IsNotExist(err) {
+		t.Fatalf("expected file removed from library root, got %v", err)
+	}
+}
+
+func TestDownloadSingle_WithoutCatalogOnlyDefaultLibrary(t *testing.T) {
+	h := New(&mockMgr{
+		enqueueFn:  func(url string) (string, error) { return "abc123", nil },
+		snapshotFn: func(id string) []*download.Item { return nil },
+	}, nil, "/tmp/test")
+	if w := doJSON(t, h, http.MethodPost, "/api/download_single", "", map[string]any{"url": "https://example.com/v", "library": "default"}); w.Code != http.StatusOK {
+		t.Fatalf("expected default library accepted, got %d %s", w.Code, w.Body.String())
+	}
+	if w := doJSON(t, h, http.MethodPost, "/api/download_single", "", map[string]any{"url": "https://example.com/v", "library": "music"}); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected unknown library rejected, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+type stubRemote struct {
+	sink, key string
+}
+
+func (s *stubRemote) RemoteURL(ctx context.Context, sink, key string) (string, error) {
+	s.sink, s.key = sink, key
+	return "https://bucket.example/" + key + "?X-Amz-Signature=abc", nil
+}
+
+func TestDownloadFile_RedirectsToRemoteCopyWhenLocalFileGone(t *testing.T) {
+	st := setupTestServerStore(t)
+	defer st.Close()
+	ctx := context.Background()
+	outDir := t.TempDir()
+	id, err := st.CreateDownload(ctx, "https://example.com/v", "video", 0, "", "completed", 100)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	_ = st.UpdateFilename(ctx, id, "video.mp4")
+	if err := st.MarkUploaded(ctx, id, "s3", "video.mp4"); err != nil {
+		t.Fatalf("mark uploaded: %v", err)
+	}
+	remote := &stubRemote{}
+	mgr := &mockMgr{enqueueFn: func(url string) (string, error) { return "", nil }, snapshotFn: func(id string) []*download.Item { return nil }}
+	h := New(mgr, st, outDir, Options{Remote: remote})
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/download_file?id=%d", id), nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != "https://bucket.example/video.mp4?X-Amz-Signature=abc" {
+		t.Fatalf("expected redirect to remote copy, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+	if remote.sink != "s3" || remote.key != "video.mp4" {
+		t.Fatalf("expected recorded remote location used, got %s/%s", remote.sink, remote.key)
+	}
+
+	// A local copy still wins over the remote one.
+	if err := os.WriteFile(filepath.Join(outDir, "video.mp4"), []byte("ok"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/download_file?id=%d", id), nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "ok" {
+		t.Fatalf("expected local file served, got %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+// streamingRemote stands in for a WebDAV sink, whose links need credentials.
+type streamingRemote struct {
+	stubRemote
+	byteRange string
+}
+
+func (s *streamingRemote) OpenRemote(ctx context.Context, sink, key, byteRange string) (*http.Response, bool, error) {
+	s.sink, s.key, s.byteRange = sink, key, byteRange
+	resp := &http.Response{StatusCode: http.StatusPartialContent, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("234"))}
+	resp.Header.Set("Content-Range", "bytes 2-4/10")
+	resp.Header.Set("Content-Type", "video/mp4")
+	return resp, true, nil
+}
+
+func TestDownloadFile_StreamsRemoteCopyWhenSinkNeedsCredentials(t *testing.T) {
+	st := setupTestServerStore(t)
+	defer st.Close()
+	ctx := context.Background()
+	id, err := st.CreateDownload(ctx, "https://example.com/v", "video", 0, "", "completed", 100)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	_ = st.UpdateFilename(ctx, id, "video.mp4")
+	if err := st.MarkUploaded(ctx, id, "webdav", "video.mp4"); err != nil {
+		t.Fatalf("mark uploaded: %v", err)
+	}
+	remote := &streamingRemote{}
+	mgr := &mockMgr{enqueueFn: func(url string) (string, error) { return "", nil }, snapshotFn: func(id string) []*download.Item { return nil }}
+	h := New(mgr, st, t.TempDir(), Options{Remote: remote})
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/download_file?id=%d&inline=1", id), nil)
+	req.Header.Set("Range", "bytes=2-4")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusPartialContent || rec.Body.String() != "234" || rec.Header().Get("Content-Range") != "bytes 2-4/10" {
+		t.Fatalf("expected ranged remote body streamed, got %d %q %v", rec.Code, rec.Body.String(), rec.Header())
+	}
+	if !strings.HasPrefix(rec.Header().Get("Content-Disposition"), "inline;") || remote.byteRange != "bytes=2-4" || remote.sink != "webdav" {
+		t.Fatalf("unexpected stream request: %+v, disposition %q", remote, rec.Header().Get("Content-Disposition"))
+	}
+}
+
+func TestThumbnail_ServesCachedCopyWithCachingHeaders(t *testing.T) {
+	st := setupTestServerStore(t)
+	defer st.Close()
+	ctx := context.Background()
+
+	var img bytes.Buffer
+	if err := png.Encode(&img, image.NewRGBA(image.Rect(0, 0, 640, 360))); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	hits := 0
+	src := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(img.Bytes())
+	}))
+	defer src.Close()
+
+	id, err := st.CreateDownload(ctx, "https://example.com/v", "video", 0, src.URL+"/maxres.png", "completed", 100)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	cache, err := thumbs.New(t.TempDir(), thumbs.Options{})
+	if err != nil {
+		t.Fatalf("cache: %v", err)
+	}
+	cache.Start()
+	defer cache.Stop()
+	mgr := &mockMgr{enqueueFn: func(url string) (string, error) { return "", nil }, snapshotFn: func(id string) []*download.Item { return nil }}
+	h := New(mgr, st, t.TempDir(), Options{Thumbnails: cache})
+
+	// A miss answers at once and fills the cache in the background for a row
+	// fetched before it existed.
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/thumbnail/%d?size=small", id), nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 on a cache miss, got %d %s", rec.Code, rec.Body.String())
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/thumbnail/%d?size=small", id), nil))
+		if rec.Code == http.StatusOK || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "image/jpeg" {
+		t.Fatalf("expected small jpeg once warmed, got %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	etag := rec.Header().Get("ETag")
+	if etag == "" || !strings.Contains(rec.Header().Get("Cache-Control"), "max-age") {
+		t.Fatalf("expected caching headers, got %v", rec.Header())
+	}
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/thumbnail/%d?size=small", id), nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified || hits != 1 {
+		t.Fatalf("expected 304 from cache, got %d after %d source hits", rec.Code, hits)
+	}
+
+	for path, want := range map[string]int{
+		fmt.Sprintf("/api/thumbnail/%d?size=huge", id): http.StatusBadRequest,
+		"/api/thumbnail/abc":                           http.StatusBadRequest,
+		"/api/thumbnail/999999":                        http.StatusNotFound,
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != want {
+			t.Errorf("%s: expected %d, got %d %s", path, want, rec.Code, rec.Body.String())
+		}
+	}
+
+	// Deleting the download drops its cached thumbnail.
+	if w := doJSON(t, h, http.MethodDelete, "/api/delete", "", map[string]any{"id": id}); w.Code != http.StatusOK {
+		t.Fatalf("delete status=%d body=%s", w.Code, w.Body.String())
+	}
+	if _, err := cache.Path(id, thumbs.SizeOriginal); !errors.Is(err, thumbs.ErrNotCached) {
+		t.Fatalf("expected cached thumbnail removed, got %v", err)
+	}
+}
+
+func TestThumbnail_EvictedOnRemoveAndHistoryClear(t *testing.T) {
+	st := setupTestServerStore(t)
+	defer st.Close()
+	ctx := context.Background()
+
+	var img bytes.Buffer
+	if err := png.Encode(&img, image.NewRGBA(image.Rect(0, 0, 64, 36))); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	src := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(img.Bytes())
+	}))
+	defer src.Close()
+
+	cache, err := thumbs.New(t.TempDir(), thumbs.Options{})
+	if err != nil {
+		t.Fatalf("cache: %v", err)
+	}
+	var ids []int64
+	for _, u := range []string{"https://example.com/a", "https://example.com/b", "https://example.com/c"} {
+		id, err := st.CreateDownload(ctx, u, "video", 0, src.URL+"/t.png", "completed", 100)
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if err := cache.Warm(ctx, id, src.URL+"/t.png", ""); err != nil {
+			t.Fatalf("warm: %v", err)
+		}
+		if _, err := cache.Path(id, thumbs.SizeOriginal); err != nil {
+			t.Fatalf("expected thumbnail of %d cached: %v", id, err)
+		}
+		ids = append(ids, id)
+	}
+	mgr := &mockMgr{enqueueFn: func(url string) (string, error) { return "", nil }, snapshotFn: func(id string) []*download.Item { return nil }}
+	h := New(mgr, st, t.TempDir(), Options{Thumbnails: cache})
+
+	if w := doJSON(t, h, http.MethodDelete, "/api/remove", "", map[string]any{"id": ids[0]}); w.Code != http.StatusOK {
+		t.Fatalf("remove status=%d body=%s", w.Code, w.Body.String())
+	}
+	form := strings.NewReader(fmt.Sprintf("id=%d", ids[1]))
+	req := httptest.NewRequest(http.MethodPost, "/dashboard/remove", form)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("dashboard remove status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	if w := doJSON(t, h, http.MethodDelete, "/api/history/clear", "", nil); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"count":1`) {
+		t.Fatalf("clear status=%d body=%s", w.Code, w.Body.String())
+	}
+	for _, id := range ids {
+		if _, err := cache.Path(id, thumbs.SizeOriginal); !errors.Is(err, thumbs.ErrNotCached) {
+			t.Fatalf("expected cached thumbnail of %d removed, got %v", id, err)
+		}
+	}
+}
+
+func TestThumbnail_WithoutCacheRedirectsToSource(t *testing.T) {
+	st := setupTestServerStore(t)
+	defer st.Close()
+	id, err := st.CreateDownload(context.Background(), "https://example.com/v", "video", 0, "https://img.example/v.jpg", "pending", 0)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	mgr := &mockMgr{enqueueFn: func(url string) (string, error) { return "", nil }, snapshotFn: func(id string) []*download.Item { return nil }}
+	h := New(mgr, st, t.TempDir())
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/thumbnail/%d", id), nil))
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != "https://img.example/v.jpg" {
+		t.Fatalf("expected redirect to source, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+}
+
+func TestTags_EnqueueFilterBulkAndDashboard(t *testing.T) {
+	st := setupTestServerStore(t)
+	defer st.Close()
+	ctx := context.Background()
+	mgr := &mockMgr{enqueueFn: func(url string) (string, error) { return "", nil }, snapshotFn: func(id string) []*download.Item { return nil }}
+	h := New(mgr, st, t.TempDir())
+
+	if w := doJSON(t, h, http.MethodPost, "/api/download_single", "", map[string]any{"url": "https://example.com/x", "tags": []string{"bad,tag"}}); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "invalid_tag") {
+		t.Fatalf("expected invalid_tag, got %d %s", w.Code, w.Body.String())
+	}
+	w := doJSON(t, h, http.MethodPost, "/api/download_single", "", map[string]any{"url": "https://example.com/lecture", "tags": []string{"Course X"}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("enqueue status=%d body=%s", w.Code, w.Body.String())
+	}
+	var single struct {
+		DBID int64 `json:"db_id"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &single)
+	if w := doJSON(t, h, http.MethodPost, "/api/download", "", map[string]any{"urls": []string{"https://example.com/b1", "https://example.com/b2"}, "tags": []string{"for Mom"}}); w.Code != http.StatusOK {
+		t.Fatalf("batch status=%d body=%s", w.Code, w.Body.String())
+	}
+
+	w = doJSON(t, h, http.MethodGet, "/api/downloads?tag=for+mom", "", nil)
+	var list struct {
+		Downloads []store.Download `json:"downloads"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	if len(list.Downloads) != 2 || list.Downloads[0].Tags[0] != "for Mom" {
+		t.Fatalf("expected two rows tagged for Mom, got %s", w.Body.String())
+	}
+
+	// Bulk assign, then list with counts.
+	if w := doJSON(t, h, http.MethodPost, "/api/tags", "", map[string]any{"ids": []int64{single.DBID}, "add": []string{"reference"}, "remove": []string{"course x"}}); w.Code != http.StatusOK {
+		t.Fatalf("bulk tag status=%d body=%s", w.Code, w.Body.String())
+	}
+	if w := doJSON(t, h, http.MethodPost, "/api/tags", "", map[string]any{"ids": []int64{single.DBID}}); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected request without add/remove rejected, got %d", w.Code)
+	}
+	w = doJSON(t, h, http.MethodGet, "/api/tags", "", nil)
+	var tags struct {
+		Tags []store.TagCount `json:"tags"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &tags)
+	if len(tags.Tags) != 2 || tags.Tags[0] != (store.TagCount{Name: "for Mom", Count: 2}) || tags.Tags[1].Name != "reference" {
+		t.Fatalf("unexpected tag list %s", w.Body.String())
+	}
+
+	// Retry is scoped to the tag.
+	for _, d := range list.Downloads {
+		_ = st.UpdateStatus(ctx, d.ID, "error", "boom")
+	}
+	_ = st.UpdateStatus(ctx, single.DBID, "error", "boom")
+	w = doJSON(t, h, http.MethodPost, "/api/retry_failed?tag=for%20Mom", "", nil)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"count":2`) {
+		t.Fatalf("retry by tag status=%d body=%s", w.Code, w.Body.String())
+	}
+	if row, _, _ := st.GetDownloadByID(ctx, single.DBID); row.Status != "error" {
+		t.Fatalf("row outside the tag must stay failed, got %s", row.Status)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/dashboard/tags?tag=reference", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `data-tag="reference"`) || !strings.Contains(rec.Body.String(), `aria-pressed="true"`) {
+		t.Fatalf("unexpected tag sidebar %d %s", rec.Code, rec.Body.String())
+	}
+	req = httptest.NewRequest(http.MethodGet, "/dashboard/rows?tag=reference", nil)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if !strings.Contains(rec.Body.String(), "example.com/lecture") || strings.Contains(rec.Body.String(), "example.com/b1") {
+		t.Fatalf("expected dashboard rows filtered by tag, got %s", rec.Body.String())
+	}
+
+	if w := doJSON(t, h, http.MethodDelete, "/api/tags", "", map[string]any{"name": "reference"}); w.Code != http.StatusOK {
+		t.Fatalf("delete tag status=%d body=%s", w.Code, w.Body.String())
+	}
+	if w := doJSON(t, h, http.MethodDelete, "/api/tags", "", map[string]any{"name": "reference"}); w.Code != http.StatusNotFound {
+		t.Fatalf("expected not_found for a deleted tag, got %d", w.Code)
+	}
+}
+
+func TestSearch_APIAndDashboardRows(t *testing.T) {
+	st := setupTestServerStore(t)
+	defer st.Close()
+	ctx := context.Background()
+	talk, _ := st.CreateDownload(ctx, "https://example.com/1", "Concurrency <in> Go", 0, "", "completed", 100)
+	other, _ := st.CreateDownload(ctx, "https://example.com/2", "Pasta night", 0, "", "completed", 100)
+	_ = st.UpdateDetails(ctx, other, "Chef", "mentions concurrency once")
+	mgr := &mockMgr{enqueueFn: func(url string) (string, error) { return "", nil }, snapshotFn: func(id string) []*download.Item { return nil }}
+	h := New(mgr, st, t.TempDir())
+
+	w := doJSON(t, h, http.MethodGet, "/api/downloads?q=concurrency", "", nil)
+	var list struct {
+		Downloads []store.Download `json:"downloads"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	if len(list.Downloads) != 2 || list.Downloads[0].ID != talk || list.Downloads[0].Snippet != "<mark>Concurrency</mark> &lt;in&gt; Go" {
+		t.Fatalf("expected ranked results with snippets, got %s", w.Body.String())
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/dashboard/rows?q=pasta", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	body := rec.Body.String()
+	if !strings.Contains(body, "<mark>Pasta</mark> night") || strings.Contains(body, "example.com/1") {
+		t.Fatalf("expected dashboard rows filtered with highlighted snippet, got %s", body)
+	}
+}
+
+type stubTranscripts struct{ queued []int64 }
+
+func (s *stubTranscripts) Enqueue(dbID int64) { s.queued = append(s.queued, dbID) }
+
+func TestTranscripts_SearchCuesIndexAndDeepLink(t *testing.T) {
+	st := setupTestServerStore(t)
+	defer st.Close()
+	ctx := context.Background()
+	outDir := t.TempDir()
+	id, _ := st.CreateDownload(ctx, "https://example.com/talk", "Streams talk", 0, "", "completed", 100)
+	_ = st.UpdateFilename(ctx, id, "talk.mp4")
+	if err := os.WriteFile(filepath.Join(outDir, "talk.mp4"), []byte("video"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	_ = st.ReplaceTranscript(ctx, id, "en", []store.TranscriptCue{{StartMS: 754000, EndMS: 757000, Text: "this is backpressure"}})
+	pending, _ := st.CreateDownload(ctx, "https://example.com/later", "Later", 0, "", "pending", 0)
+
+	idx := &stubTranscripts{}
+	mgr := &mockMgr{enqueueFn: func(url string) (string, error) { return "", nil }, snapshotFn: func(id string) []*download.Item { return nil }}
+	h := New(mgr, st, outDir, Options{Transcripts: idx})
+
+	w := doJSON(t, h, http.MethodGet, "/api/transcripts/search?q=backpressure", "", nil)
+	var search struct {
+		Results []store.TranscriptHit `json:"results"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &search)
+	if w.Code != http.StatusOK || len(search.Results) != 1 || search.Results[0].Cues[0].StartMS != 754000 {
+		t.Fatalf("unexpected search response %d %s", w.Code, w.Body.String())
+	}
+	if w := doJSON(t, h, http.MethodGet, "/api/transcripts/search", "", nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected missing query rejected, got %d", w.Code)
+	}
+
+	if w := doJSON(t, h, http.MethodGet, fmt.Sprintf("/api/transcripts/%d", id), "", nil); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"lang":"en"`) {
+		t.Fatalf("cues status=%d body=%s", w.Code, w.Body.String())
+	}
+	if w := doJSON(t, h, http.MethodGet, fmt.Sprintf("/api/transcripts/%d", pending), "", nil); w.Code != http.StatusNotFound {
+		t.Fatalf("expected transcript_not_found, got %d", w.Code)
+	}
+	if w := doJSON(t, h, http.MethodPost, fmt.Sprintf("/api/transcripts/%d/index", pending), "", nil); w.Code != http.StatusConflict {
+		t.Fatalf("expected pending row rejected, got %d", w.Code)
+	}
+	if w := doJSON(t, h, http.MethodPost, fmt.Sprintf("/api/transcripts/%d/index", id), "", nil); w.Code != http.StatusOK || len(idx.queued) != 1 {
+		t.Fatalf("index status=%d queued=%v", w.Code, idx.queued)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/dashboard/transcripts?q=backpressure", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	link := fmt.Sprintf(`href="/api/download_file?id=%d&amp;inline=1#t=754"`, id)
+	if !strings.Contains(rec.Body.String(), link) || !strings.Contains(rec.Body.String(), "12:34") || !strings.Contains(rec.Body.String(), "<mark>backpressure</mark>") {
+		t.Fatalf("expected deep link to the cue, got %s", rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/download_file?id=%d&inline=1", id), nil)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if cd := rec.Header().Get("Content-Disposition"); !strings.HasPrefix(cd, "inline;") {
+		t.Fatalf("expected inline disposition, got %q", cd)
+	}
+}
+
+type stubMetadata struct {
+	mu    sync.Mutex
+	urls  []string
+	paths []string
+	err   error
+}
+
+func (s *stubMetadata) RefreshMetadata(ctx context.Context, dbID int64, url string, st download.MetadataStore) (download.MediaInfo, error) {
+	s.mu.Lock()
+	s.urls = append(s.urls, url)
+	s.mu.Unlock()
+	if s.err != nil {
+		return download.MediaInfo{}, s.err
+	}
+	info := download.MediaInfo{Title: "Fetched " + url, DurationSec: 60, Uploader: "Remote"}
+	_ = st.UpdateMeta(ctx, dbID, info.Title, info.DurationSec, "")
+	_ = st.UpdateDetails(ctx, dbID, info.Uploader, "")
+	return info, nil
+}
+
+func (s *stubMetadata) RefreshMetadataFromFile(ctx context.Context, dbID int64, path string, st download.MetadataStore) (download.MediaInfo, error) {
+	s.mu.Lock()
+	s.paths = append(s.paths, path)
+	s.mu.Unlock()
+	_ = st.UpdateMeta(ctx, dbID, "Tagged title", 0, "")
+	return download.MediaInfo{Title: "Tagged title"}, nil
+}
+
+func TestRefreshMetadata_SingleBulkAndFile(t *testing.T) {
+	st := setupTestServerStore(t)
+	defer st.Close()
+	ctx := context.Background()
+	outDir := t.TempDir()
+	stale, _ := st.CreateDownload(ctx, "https://example.com/a", "https://example.com/a", 0, "", "error", 0)
+	stale2, _ := st.CreateDownload(ctx, "https://example.com/b", "https://example.com/b", 0, "", "completed", 100)
+	_ = st.UpdateFilename(ctx, stale2, "b.mp4")
+	if err := os.WriteFile(filepath.Join(outDir, "b.mp4"), []byte("video"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	fine, _ := st.CreateDownload(ctx, "https://example.com/c", "Known title", 0, "", "completed", 100)
+	_, _ = st.CreateDownload(ctx, "https://example.com/d", "https://example.com/d", 0, "", "pending", 0)
+
+	meta := &stubMetadata{}
+	mgr := &mockMgr{enqueueFn: func(url string) (string, error) { return "", nil }, snapshotFn: func(id string) []*download.Item { return nil }}
+	h := New(mgr, st, outDir, Options{Metadata: meta})
+
+	w := doJSON(t, h, http.MethodPost, fmt.Sprintf("/api/downloads/%d/refresh-metadata", stale), "", nil)
+	var resp struct {
+		Download store.Download `json:"download"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusOK || resp.Download.Title != "Fetched https://example.com/a" || resp.Download.Uploader != "Remote" {
+		t.Fatalf("refresh status=%d body=%s", w.Code, w.Body.String())
+	}
+
+	// Reading tags from the local file is limited to completed rows with a file.
+	if w := doJSON(t, h, http.MethodPost, fmt.Sprintf("/api/downloads/%d/refresh-metadata", stale), "", map[string]any{"source": "file"}); w.Code != http.StatusConflict {
+		t.Fatalf("expected invalid_state for file refresh of failed row, got %d", w.Code)
+	}
+	if w := doJSON(t, h, http.MethodPost, fmt.Sprintf("/api/downloads/%d/refresh-metadata", fine), "", map[string]any{"source": "file"}); w.Code != http.StatusNotFound || !strings.Contains(w.Body.String(), "file_not_found") {
+		t.Fatalf("expected file_not_found, got %d %s", w.Code, w.Body.String())
+	}
+	if w := doJSON(t, h, http.MethodPost, fmt.Sprintf("/api/downloads/%d/refresh-metadata", stale2), "", map[string]any{"source": "file"}); w.Code != http.StatusOK || len(meta.paths) != 1 || meta.paths[0] != filepath.Join(outDir, "b.mp4") {
+		t.Fatalf("file refresh status=%d paths=%v", w.Code, meta.paths)
+	}
+	if w := doJSON(t, h, http.MethodPost, "/api/downloads/999/refresh-metadata", "", nil); w.Code != http.StatusNotFound {
+		t.Fatalf("expected not_found, got %d", w.Code)
+	}
+	if w := doJSON(t, h, http.MethodPost, fmt.Sprintf("/api/downloads/%d/refresh-metadata", fine), "", map[string]any{"source": "ftp"}); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected unknown source rejected, got %d", w.Code)
+	}
+	meta.err = errors.New("yt-dlp failed")
+	if w := doJSON(t, h, http.MethodPost, fmt.Sprintf("/api/downloads/%d/refresh-metadata", fine), "", nil); w.Code != http.StatusBadGateway {
+		t.Fatalf("expected metadata_fetch_failed, got %d", w.Code)
+	}
+	meta.err = nil
+
+	// The bulk variant picks up rows still titled with their URL, skipping pending ones.
+	_ = st.UpdateMeta(ctx, stale, "https://example.com/a", 0, "")
+	meta.urls = nil
+	if w := doJSON(t, h, http.MethodPost, "/api/downloads/refresh-metadata", "", map[string]any{}); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected empty bulk request rejected, got %d", w.Code)
+	}
+	w = doJSON(t, h, http.MethodPost, "/api/downloads/refresh-metadata", "", map[string]any{"stale": true})
+	if w.Code != http.StatusAccepted || !strings.Contains(w.Body.String(), `"count":1`) {
+		t.Fatalf("bulk status=%d body=%s", w.Code, w.Body.String())
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		row, _, _ := st.GetDownloadByID(ctx, stale)
+		if row.Title == "Fetched https://example.com/a" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("bulk refresh did not update row, title=%q", row.Title)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRefreshMetadata_DisabledWithoutOption(t *testing.T) {
+	st := setupTestServerStore(t)
+	defer st.Close()
+	id, _ := st.CreateDownload(context.Background(), "https://example.com/a", "https://example.com/a", 0, "", "error", 0)
+	mgr := &mockMgr{enqueueFn: func(url string) (string, error) { return "", nil }, snapshotFn: func(id string) []*download.Item { return nil }}
+	h := New(mgr, st, t.TempDir(), Options{})
+	if w := doJSON(t, h, http.MethodPost, fmt.Sprintf("/api/downloads/%d/refresh-metadata", id), "", nil); w.Code != http.StatusNotFound {
+		t.Fatalf("expected endpoint disabled, got %d", w.Code)
+	}
+}
+
+func TestEnqueue_MaxDurationPersisted(t *testing.T) {
+	st := setupTestServerStore(t)
+	defer st.Close()
+	ctx := context.Background()
+	mgr := &mockMgr{enqueueFn: func(url string) (string, error) { return "", nil }, snapshotFn: func(id string) []*download.Item { return nil }}
+	h := New(mgr, st, t.TempDir())
+
+	for _, bad := range []string{"soon", "-5m", "500ms"} {
+		if w := doJSON(t, h, http.MethodPost, "/api/download_single", "", map[string]any{"url": "https://example.com/x", "max_duration": bad}); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "invalid_max_duration") {
+			t.Fatalf("max_duration %q: expected invalid_max_duration, got %d %s", bad, w.Code, w.Body.String())
+		}
+	}
+	w := doJSON(t, h, http.MethodPost, "/api/download_single", "", map[string]any{"url": "https://example.com/live", "max_duration": "90m"})
+	var single struct {
+		DBID int64 `json:"db_id"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &single)
+	if w := doJSON(t, h, http.MethodPost, "/api/download", "", map[string]any{"urls": []string{"https://example.com/b"}, "max_duration": "10m"}); w.Code != http.StatusOK {
+		t.Fatalf("batch status=%d body=%s", w.Code, w.Body.String())
+	}
+
+	row, _, _ := st.GetDownloadByID(ctx, single.DBID)
+	if row.MaxDurationSec != 5400 {
+		t.Fatalf("expected 90m limit stored, got %d", row.MaxDurationSec)
+	}
+	pending, err := st.GetPendingDownloadsForWorker(ctx, 10)
+	if err != nil || len(pending) != 2 {
+		t.Fatalf("pending rows: %v %v", pending, err)
+	}
+	for _, v := range pending {
+		p := v.(map[string]interface{})
+		want := 90 * time.Minute
+		if p["url"] == "https://example.com/b" {
+			want = 10 * time.Minute
+		}
+		if p["max_duration"] != want {
+			t.Fatalf("row %v: expected limit %s, got %v", p["url"], want, p["max_duration"])
+		}
+	}
+}
+
+type stubBreakers struct {
+	list  []download.BreakerStatus
+	reset []string
+}
+
+func (s *stubBreakers) Breakers() []download.BreakerStatus { return s.list }
+
+func (s *stubBreakers) ResetBreaker(host string) bool {
+	for i, b := range s.list {
+		if b.Host == host {
+			s.reset = append(s.reset, host)
+			s.list = append(s.list[:i], s.list[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func TestBreakers_HealthAndAdminReset(t *testing.T) {
+	retry := time.Now().Add(time.Minute)
+	br := &stubBreakers{list: []download.BreakerStatus{
+		{Host: "flaky.example", State: download.BreakerOpen, ConsecutiveFailures: 5, Deferred: 3, RetryAt: &retry},
+		{Host: "steady.example", State: download.BreakerClosed, ConsecutiveFailures: 1},
+	}}
+	mgr := &mockMgr{enqueueFn: func(url string) (string, error) { return "", nil }, snapshotFn: func(id string) []*download.Item { return nil }}
+	h := New(mgr, nil, t.TempDir(), Options{Breakers: br})
+
+	w := doJSON(t, h, http.MethodGet, "/api/health", "", nil)
+	var health struct {
+		Healthy    bool                     `json:"healthy"`
+		Conditions []healthCondition        `json:"conditions"`
+		Breakers   []download.BreakerStatus `json:"breakers"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &health)
+	if health.Healthy || len(health.Conditions) != 1 || health.Conditions[0].Name != download.ReasonCircuitOpen ||
+		!strings.Contains(health.Conditions[0].Message, "flaky.example") || len(health.Breakers) != 2 {
+		t.Fatalf("unexpected health %s", w.Body.String())
+	}
+
+	if w := doJSON(t, h, http.MethodPost, "/api/admin/breakers", "", map[string]any{"host": "unknown.example"}); w.Code != http.StatusNotFound {
+		t.Fatalf("expected not_found for unknown host, got %d", w.Code)
+	}
+	if w := doJSON(t, h, http.MethodPost, "/api/admin/breakers", "", map[string]any{}); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected invalid_request without host, got %d", w.Code)
+	}
+	if w := doJSON(t, h, http.MethodPost, "/api/admin/breakers", "", map[string]any{"host": "flaky.example"}); w.Code != http.StatusOK || len(br.reset) != 1 {
+		t.Fatalf("reset status=%d body=%s", w.Code, w.Body.String())
+	}
+	if w := doJSON(t, h, http.MethodGet, "/api/health", "", nil); !strings.Contains(w.Body.String(), `"healthy":true`) {
+		t.Fatalf("expected healthy after reset, got %s", w.Body.String())
+	}
+	if w := doJSON(t, New(mgr, nil, t.TempDir()), http.MethodGet, "/api/admin/breakers", "", nil); w.Code != http.StatusNotFound {
+		t.Fatalf("expected endpoint disabled without option, got %d", w.Code)
+	}
+}
+
+type stubMetrics struct{ m download.Metrics }
+
+func (s stubMetrics) Metrics() download.Metrics { return s.m }
+
+func TestMetrics_ReportsInfoJSONReuse(t *testing.T) {
+	mgr := &mockMgr{enqueueFn: func(url string) (string, error) { return "", nil }, snapshotFn: func(id string) []*download.Item { return nil }}
+	rep := stubMetrics{m: download.Metrics{InfoJSON: download.InfoJSONMetrics{Saved: 3, Reused: 2, Fallbacks: 1, SavedProbeSeconds: 4.5}}}
+	h := New(mgr, nil, t.TempDir(), Options{Metrics: rep})
+
+	w := doJSON(t, h, http.MethodGet, "/api/metrics", "", nil)
+	var resp struct {
+		Status  string           `json:"status"`
+		Metrics download.Metrics `json:"metrics"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s", w.Code, w.Body.String())
+	}
+	if resp.Status != "success" || resp.Metrics != rep.m {
+		t.Fatalf("unexpected metrics %s", w.Body.String())
+	}
+	if w := doJSON(t, h, http.MethodPost, "/api/metrics", "", nil); w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405 for POST, got %d", w.Code)
+	}
+	if w := doJSON(t, New(mgr, nil, t.TempDir()), http.MethodGet, "/api/metrics", "", nil); w.Code != http.StatusNotFound {
+		t.Fatalf("expected endpoint disabled without option, got %d", w.Code)
+	}
+}
+
+type stubMetadataPool struct{ st download.MetadataPoolStatus }
+
+func (s stubMetadataPool) MetadataPool() download.MetadataPoolStatus { return s.st }
+
+func TestStatusAndHealth_ReportMetadataPool(t *testing.T) {
+	mgr := &mockMgr{enqueueFn: func(url string) (string, error) { return "", nil }, snapshotFn: func(id string) []*download.Item { return nil }}
+	pool := stubMetadataPool{st: download.MetadataPoolStatus{Workers: 4, QueueCapacity: 64, Queued: 12, Active: 4}}
+	h := New(mgr, nil, t.TempDir(), Options{MetadataPool: pool})
+
+	for _, path := range []string{"/api/status", "/api/health"} {
+		w := doJSON(t, h, http.MethodGet, path, "", nil)
+		var resp struct {
+			MetadataPool *download.MetadataPoolStatus `json:"metadata_pool"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.MetadataPool == nil || *resp.MetadataPool != pool.st {
+			t.Fatalf("%s: unexpected body %s", path, w.Body.String())
+		}
+	}
+	if w := doJSON(t, New(mgr, nil, t.TempDir()), http.MethodGet, "/api/status", "", nil); strings.Contains(w.Body.String(), "metadata_pool") {
+		t.Fatalf("expected no metadata_pool without option, got %s", w.Body.String())
+	}
+}
+
+type stubProber struct {
+	probes map[string]download.ProbeResult // by URL
+	err    error
+	calls  int
+}
+
+func (s *stubProber) Probe(ctx context.Context, url string) (download.ProbeResult, error) {
+	s.calls++
+	if s.err != nil {
+		return download.ProbeResult{}, s.err
+	}
+	return s.probes[url], nil
+}
+
+func (s *stubProber) LookupProbe(token string) (download.ProbeResult, bool) {
+	for _, p := range s.probes {
+		if p.Token == token {
+			return p, true
+		}
+	}
+	return download.ProbeResult{}, false
+}
+
+func TestProbe_ListsFormatsAndPinsOneOnEnqueue(t *testing.T) {
+	st := setupTestServerStore(t)
+	defer st.Close()
+	ctx := context.Background()
+	pr := &stubProber{probes: map[string]download.ProbeResult{
+		"https://example.com/v": {Token: "tok1", URL: "https://example.com/v", Title: "Video", DurationSec: 60, Formats: []download.Format{
+			{ID: "137", Resolution: "1920x1080", VCodec: "avc1", ACodec: "none", FPS: 30},
+			{ID: "140", Resolution: "audio only", VCodec: "none", ACodec: "mp4a.40.2"},
+		}},
+	}}
+	mgr := &mockMgr{enqueueFn: func(url string) (string, error) { return "", nil }, snapshotFn: func(id string) []*download.Item { return nil }}
+	h := New(mgr, st, t.TempDir(), Options{Prober: pr})
+
+	w := doJSON(t, h, http.MethodGet, "/api/probe?url=https://example.com/v", "", nil)
+	var resp struct {
+		Probe download.ProbeResult `json:"probe"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("probe status=%d body=%s", w.Code, w.Body.String())
+	}
+	if resp.Probe.Token != "tok1" || len(resp.Probe.Formats) != 2 || resp.Probe.Formats[0].Resolution != "1920x1080" {
+		t.Fatalf("unexpected probe %s", w.Body.String())
+	}
+	if w := doJSON(t, h, http.MethodGet, "/api/probe?url=ftp://example.com/v", "", nil); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "invalid_url") {
+		t.Fatalf("expected invalid_url, got %d %s", w.Code, w.Body.String())
+	}
+
+	cases := []struct {
+		body map[string]any
+		want string
+	}{
+		{map[string]any{"url": "https://example.com/v", "format_id": "137"}, "probe_token_required"},
+		{map[string]any{"url": "https://example.com/v", "probe_token": "nope"}, "invalid_probe_token"},
+		{map[string]any{"url": "https://example.com/other", "probe_token": "tok1"}, "invalid_probe_token"},
+		{map[string]any{"url": "https://example.com/v", "probe_token": "tok1", "format_id": "22"}, "unknown_format"},
+	}
+	for _, c := range cases {
+		if w := doJSON(t, h, http.MethodPost, "/api/download_single", "", c.body); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), c.want) {
+			t.Fatalf("%v: expected %s, got %d %s", c.body, c.want, w.Code, w.Body.String())
+		}
+	}
+
+	w = doJSON(t, h, http.MethodPost, "/api/download_single", "", map[string]any{"url": "https://example.com/v", "probe_token": "tok1", "format_id": "137+140"})
+	var single struct {
+		DBID int64 `json:"db_id"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &single); err != nil || single.DBID == 0 {
+		t.Fatalf("enqueue status=%d body=%s", w.Code, w.Body.String())
+	}
+	row, _, _ := st.GetDownloadByID(ctx, single.DBID)
+	if row.FormatID != "137+140" || row.ProbeToken != "tok1" {
+		t.Fatalf("expected pinned format and probe token stored, got %q %q", row.FormatID, row.ProbeToken)
+	}
+
+	pr.err = download.ErrNoMediaInfo
+	if w := doJSON(t, h, http.MethodGet, "/api/probe?url=https://example.com/empty", "", nil); w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected no_metadata, got %d %s", w.Code, w.Body.String())
+	}
+	if w := doJSON(t, New(mgr, st, t.TempDir()), http.MethodGet, "/api/probe?url=https://example.com/v", "", nil); w.Code != http.StatusNotFound {
+		t.Fatalf("expected endpoint disabled without option, got %d", w.Code)
+	}
+}
+
+func TestDownloadSingle_ClipsOfOneURLAreDistinct(t *testing.T) {
+	st := setupTestServerStore(t)
+	defer st.Close()
+	ctx := context.Background()
+	mgr := &mockMgr{enqueueFn: func(url string) (string, error) { return "", nil }, snapshotFn: func(id string) []*download.Item { return nil }}
+	h := New(mgr, st, t.TempDir())
+
+	enqueue := func(sections []string) (string, int64) {
+		t.Helper()
+		w := doJSON(t, h, http.MethodPost, "/api/download_single", "", map[string]any{"url": "https://example.com/stream", "sections": sections})
+		var resp struct {
+			Message    string `json:"message"`
+			DBID       int64  `json:"db_id"`
+			ExistingID int64  `json:"existing_id"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || w.Code != http.StatusOK {
+			t.Fatalf("enqueue %v: status=%d body=%s", sections, w.Code, w.Body.String())
+		}
+		return resp.Message, resp.DBID + resp.ExistingID
+	}
+
+	msg, clipID := enqueue([]string{"12:00-18:00"})
+	if msg != "enqueued" {
+		t.Fatalf("expected first clip enqueued, got %s", msg)
+	}
+	if msg, _ := enqueue([]string{"30:00-31:00"}); msg != "enqueued" {
+		t.Fatalf("expected a different clip of the same URL to be enqueued, got %s", msg)
+	}
+	if msg, _ := enqueue(nil); msg != "enqueued" {
+		t.Fatalf("expected the whole video to be enqueued next to its clips, got %s", msg)
+	}
+	if msg, id := enqueue([]string{"720-1080"}); msg != "already_exists" || id != clipID {
+		t.Fatalf("expected the same clip written differently to be a duplicate of %d, got %s %d", clipID, msg, id)
+	}
+
+	row, _, _ := st.GetDownloadByID(ctx, clipID)
+	if len(row.Sections) != 1 || row.Sections[0] != "12:00-18:00" {
+		t.Fatalf("expected canonical sections on the row, got %q", row.Sections)
+	}
+	if w := doJSON(t, h, http.MethodPost, "/api/download_single", "", map[string]any{"url": "https://example.com/stream", "sections": []string{"18:00-12:00"}}); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "invalid_sections") {
+		t.Fatalf("expected invalid_sections, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestChapters_ListAndServeSplitPieces(t *testing.T) {
+	st := setupTestServerStore(t)
+	defer st.Close()
+	ctx := context.Background()
+	outDir := t.TempDir()
+	mgr := &mockMgr{enqueueFn: func(url string) (string, error) { return "", nil }, snapshotFn: func(id string) []*download.Item { return nil }}
+	h := New(mgr, st, outDir)
+
+	w := doJSON(t, h, http.MethodPost, "/api/download_single", "", map[string]any{"url": "https://example.com/mix", "split_chapters": true})
+	var single struct {
+		DBID int64 `json:"db_id"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &single); err != nil || single.DBID == 0 {
+		t.Fatalf("enqueue status=%d body=%s", w.Code, w.Body.String())
+	}
+	id := single.DBID
+	if row, _, _ := st.GetDownloadByID(ctx, id); !row.SplitChapters {
+		t.Fatalf("expected split_chapters stored on the row")
+	}
+
+	pieceDir := filepath.Join(outDir, "Mix-abc.chapters")
+	if err := os.MkdirAll(pieceDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	artifacts := []string{filepath.Join(outDir, "Mix-abc.webm")}
+	for _, name := range []string{"001 - Intro.webm", "002 - Outro.webm"} {
+		p := filepath.Join(pieceDir, name)
+		if err := os.WriteFile(p, []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		artifacts = append(artifacts, p)
+	}
+	// A tracked piece outside the library is never listed or served.
+	artifacts = append(artifacts, filepath.Join(t.TempDir(), "x.chapters", "003 - Elsewhere.webm"))
+	if err := os.WriteFile(artifacts[0], []byte("full"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.UpdateArtifacts(ctx, id, artifacts); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.UpdateFilename(ctx, id, "Mix-abc.webm"); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.UpdateStatus(ctx, id, "completed", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	w = doJSON(t, h, http.MethodGet, fmt.Sprintf("/api/chapters/%d", id), "", nil)
+	var list struct {
+		Chapters []struct {
+			Number      int    `json:"number"`
+			Title       string `json:"title"`
+			DownloadURL string `json:"download_url"`
+			PlayURL     string `json:"play_url"`
+		} `json:"chapters"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("chapters status=%d body=%s", w.Code, w.Body.String())
+	}
+	if len(list.Chapters) != 2 || list.Chapters[1].Title != "Outro" || list.Chapters[1].PlayURL != fmt.Sprintf("/api/download_file?id=%d&chapter=2&inline=1", id) {
+		t.Fatalf("unexpected chapters %s", w.Body.String())
+	}
+
+	w = doJSON(t, h, http.MethodGet, list.Chapters[1].PlayURL, "", nil)
+	if w.Code != http.StatusOK || w.Body.String() != "002 - Outro.webm" || !strings.HasPrefix(w.Header().Get("Content-Disposition"), "inline") {
+		t.Fatalf("serve chapter: status=%d body=%q disposition=%q", w.Code, w.Body.String(), w.Header().Get("Content-Disposition"))
+	}
+	if w := doJSON(t, h, http.MethodGet, fmt.Sprintf("/api/download_file?id=%d&chapter=3", id), "", nil); w.Code != http.StatusNotFound {
+		t.Fatalf("expected piece outside the library to be refused, got %d", w.Code)
+	}
+	if w := doJSON(t, h, http.MethodGet, fmt.Sprintf("/api/download_file?id=%d&chapter=x", id), "", nil); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "invalid_chapter") {
+		t.Fatalf("expected invalid_chapter, got %d %s", w.Code, w.Body.String())
+	}
+	if w := doJSON(t, h, http.MethodGet, fmt.Sprintf("/api/download_file?id=%d", id), "", nil); w.Body.String() != "full" {
+		t.Fatalf("expected the full file without chapter, got %q", w.Body.String())
+	}
+}
+
+func TestSidecars_ListServeAndDelete(t *testing.T) {
+	st := setupTestServerStore(t)
+	defer st.Close()
+	ctx := context.Background()
+	outDir := t.TempDir()
+	mgr := &mockMgr{enqueueFn: func(url string) (string, error) { return "", nil }, snapshotFn: func(id string) []*download.Item { return nil }}
+	h := New(mgr, st, outDir)
+
+	id, err := st.CreateDownload(ctx, "https://example.com/watch?v=abc", "Talk", 30, "", "completed", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"Talk-abc.mp4":           "video",
+		"Talk-abc.description":   "About this talk",
+		"Talk-abc.comments.json": `[{"text":"nice"}]`,
+	}
+	var artifacts []string
+	for name, body := range files {
+		p := filepath.Join(outDir, name)
+		if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		artifacts = append(artifacts, p)
+	}
+	// Tracked but missing on disk, so neither listed nor served.
+	artifacts = append(artifacts, filepath.Join(outDir, "Talk-abc.info.json"))
+	if err := st.UpdateArtifacts(ctx, id, artifacts); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.UpdateFilename(ctx, id, "Talk-abc.mp4"); err != nil {
+		t.Fatal(err)
+	}
+
+	w := doJSON(t, h, http.MethodGet, fmt.Sprintf("/api/sidecars/%d", id), "", nil)
+	var list struct {
+		Sidecars []struct {
+			Kind     string `json:"kind"`
+			Filename string `json:"filename"`
+			URL      string `json:"url"`
+		} `json:"sidecars"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("sidecars status=%d body=%s", w.Code, w.Body.String())
+	}
+	if len(list.Sidecars) != 2 || list.Sidecars[0].Kind != "comments" || list.Sidecars[1].URL != fmt.Sprintf("/api/sidecars/%d/description", id) {
+		t.Fatalf("unexpected sidecars %s", w.Body.String())
+	}
+
+	w = doJSON(t, h, http.MethodGet, list.Sidecars[1].URL, "", nil)
+	if w.Code != http.StatusOK || w.Body.String() != "About this talk" || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain") {
+		t.Fatalf("serve description: status=%d body=%q type=%q", w.Code, w.Body.String(), w.Header().Get("Content-Type"))
+	}
+	if w := doJSON(t, h, http.MethodGet, fmt.Sprintf("/api/sidecars/%d/comments", id), "", nil); w.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("expected JSON comments, got %q", w.Header().Get("Content-Type"))
+	}
+	if w := doJSON(t, h, http.MethodGet, fmt.Sprintf("/api/sidecars/%d/info_json", id), "", nil); w.Code != http.StatusNotFound {
+		t.Fatalf("expected missing info json to be 404, got %d", w.Code)
+	}
+	if w := doJSON(t, h, http.MethodGet, fmt.Sprintf("/api/sidecars/%d/thumbnail", id), "", nil); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "invalid_sidecar") {
+		t.Fatalf("expected invalid_sidecar, got %d %s", w.Code, w.Body.String())
+	}
+	if w := doJSON(t, h, http.MethodGet, "/api/sidecars/999999", "", nil); w.Code != http.StatusNotFound {
+		t.Fatalf("expected unknown row to be 404, got %d", w.Code)
+	}
+
+	w = doJSON(t, h, http.MethodDelete, "/api/delete", "", map[string]any{"id": id})
+	if w.Code != http.StatusOK {
+		t.Fatalf("delete status=%d body=%s", w.Code, w.Body.String())
+	}
+	for name := range files {
+		if _, err := os.Stat(filepath.Join(outDir, name)); !os.IsNotExist(err) {
+			t.Fatalf("expected %s removed on delete, got %v", name, err)
+		}
+	}
+}
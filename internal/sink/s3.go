@@ -0,0 +1,369 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultS3Region   = "us-east-1"
+	defaultS3PartSize = 16 << 20
+	minS3PartSize     = 5 << 20
+	maxS3Parts        = 10000
+	unsignedPayload   = "UNSIGNED-PAYLOAD"
+	maxPresignTTL     = 7 * 24 * time.Hour
+)
+
+// S3Config configures an S3-compatible bucket addressed path-style
+// (endpoint/bucket/key), which AWS, MinIO and most clones accept.
+type S3Config struct {
+	Endpoint  string // e.g. https://s3.us-east-1.amazonaws.com; empty uses AWS for Region
+	Region    string
+	Bucket    string
+	Prefix    string // prepended to every key
+	AccessKey string
+	SecretKey string
+	PartSize  int64 // files larger than this use multipart upload; 0 uses 16 MiB
+	Client    *http.Client
+}
+
+// S3 uploads to an S3-compatible bucket using Signature Version 4.
+type S3 struct {
+	cfg      S3Config
+	endpoint *url.URL
+	client   *http.Client
+	retry    retryPolicy
+	now      func() time.Time
+}
+
+// NewS3 validates cfg and returns an S3 sink.
+func NewS3(cfg S3Config) (*S3, error) {
+	if cfg.Bucket == "" {
+		return nil, errors.New("s3 sink: bucket is required")
+	}
+	if cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, errors.New("s3 sink: access key and secret key are required")
+	}
+	if cfg.Region == "" {
+		cfg.Region = defaultS3Region
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://s3." + cfg.Region + ".amazonaws.com"
+	}
+	ep, err := url.Parse(cfg.Endpoint)
+	if err != nil || (ep.Scheme != "http" && ep.Scheme != "https") || ep.Host == "" {
+		return nil, fmt.Errorf("s3 sink: invalid endpoint %q", cfg.Endpoint)
+	}
+	if cfg.PartSize == 0 {
+		cfg.PartSize = defaultS3PartSize
+	}
+	if cfg.PartSize < minS3PartSize {
+		return nil, fmt.Errorf("s3 sink: part size must be at least %d bytes", minS3PartSize)
+	}
+	cfg.Prefix = strings.Trim(cfg.Prefix, "/")
+	client := cfg.Client
+	if client == nil {
+		client = &http.Client{Timeout: 15 * time.Minute}
+	}
+	return &S3{cfg: cfg, endpoint: ep, client: client, retry: defaultRetry, now: time.Now}, nil
+}
+
+// Name implements Sink.
+func (s *S3) Name() string { return "s3" }
+
+// Upload implements Sink. Files above the part size go through multipart
+// upload; every request is retried on network errors, 429 and 5xx.
+func (s *S3) Upload(ctx context.Context, key, path string, progress func(sent int64)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	objectKey := joinKey(s.cfg.Prefix, key)
+	if info.Size() <= s.cfg.PartSize {
+		return s.retry.do(ctx, func() error {
+			body := &countingReader{r: io.NewSectionReader(f, 0, info.Size()), fn: progress}
+			resp, err := s.send(ctx, http.MethodPut, objectKey, nil, body, info.Size())
+			if err != nil {
+				return err
+			}
+			defer drain(resp)
+			if resp.StatusCode != http.StatusOK {
+				return statusError("put object", resp)
+			}
+			return nil
+		})
+	}
+	return s.uploadMultipart(ctx, objectKey, f, info.Size(), progress)
+}
+
+type completedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+func (s *S3) uploadMultipart(ctx context.Context, objectKey string, f *os.File, size int64, progress func(int64)) error {
+	partSize := s.cfg.PartSize
+	for (size+partSize-1)/partSize > maxS3Parts {
+		partSize *= 2
+	}
+
+	var uploadID string
+	err := s.retry.do(ctx, func() error {
+		resp, err := s.send(ctx, http.MethodPost, objectKey, url.Values{"uploads": {""}}, nil, 0)
+		if err != nil {
+			return err
+		}
+		defer drain(resp)
+		if resp.StatusCode != http.StatusOK {
+			return statusError("create multipart upload", resp)
+		}
+		var out struct {
+			UploadID string `xml:"UploadId"`
+		}
+		if err := xml.NewDecoder(resp.Body).Decode(&out); err != nil || out.UploadID == "" {
+			return fmt.Errorf("create multipart upload: missing upload id")
+		}
+		uploadID = out.UploadID
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	parts := make([]completedPart, 0, (size+partSize-1)/partSize)
+	for off, num := int64(0), 1; off < size; off, num = off+partSize, num+1 {
+		n := partSize
+		if off+n > size {
+			n = size - off
+		}
+		q := url.Values{"partNumber": {strconv.Itoa(num)}, "uploadId": {uploadID}}
+		var etag string
+		err := s.retry.do(ctx, func() error {
+			body := &countingReader{r: io.NewSectionReader(f, off, n), base: off, fn: progress}
+			resp, err := s.send(ctx, http.MethodPut, objectKey, q, body, n)
+			if err != nil {
+				return err
+			}
+			defer drain(resp)
+			if resp.StatusCode != http.StatusOK {
+				return statusError("upload part "+strconv.Itoa(num), resp)
+			}
+			etag = resp.Header.Get("ETag")
+			return nil
+		})
+		if err != nil {
+			s.abortMultipart(objectKey, uploadID)
+			return err
+		}
+		parts = append(parts, completedPart{PartNumber: num, ETag: etag})
+	}
+
+	payload, err := xml.Marshal(struct {
+		XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+		Parts   []completedPart `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		return err
+	}
+	err = s.retry.do(ctx, func() error {
+		resp, err := s.send(ctx, http.MethodPost, objectKey, url.Values{"uploadId": {uploadID}}, bytes.NewReader(payload), int64(len(payload)))
+		if err != nil {
+			return err
+		}
+		defer drain(resp)
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		// S3 may report a failed completion inside a 200 response.
+		if resp.StatusCode != http.StatusOK || bytes.Contains(body, []byte("<Error>")) {
+			status := resp.StatusCode
+			if status == http.StatusOK {
+				status = http.StatusInternalServerError
+			}
+			return &StatusError{Op: "complete multipart upload", Status: status, Body: strings.TrimSpace(string(body))}
+		}
+		return nil
+	})
+	if err != nil {
+		s.abortMultipart(objectKey, uploadID)
+	}
+	return err
+}
+
+// abortMultipart releases parts of a failed upload; errors are ignored because
+// bucket lifecycle rules clean up leftovers anyway.
+func (s *S3) abortMultipart(objectKey, uploadID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if resp, err := s.send(ctx, http.MethodDelete, objectKey, url.Values{"uploadId": {uploadID}}, nil, 0); err == nil {
+		drain(resp)
+	}
+}
+
+// URL implements Sink with a presigned GET link.
+func (s *S3) URL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	if ttl <= 0 || ttl > maxPresignTTL {
+		ttl = maxPresignTTL
+	}
+	u := s.objectURL(joinKey(s.cfg.Prefix, key), nil)
+	return presignV4(u, s.cfg.AccessKey, s.cfg.SecretKey, s.cfg.Region, s.now(), ttl), nil
+}
+
+func (s *S3) objectURL(objectKey string, q url.Values) *url.URL {
+	u := *s.endpoint
+	path := strings.TrimRight(u.Path, "/") + "/" + s.cfg.Bucket + "/" + objectKey
+	u.Path = path
+	u.RawPath = awsEscapePath(path)
+	u.RawQuery = canonicalQuery(q)
+	return &u
+}
+
+func (s *S3) send(ctx context.Context, method, objectKey string, q url.Values, body io.Reader, size int64) (*http.Response, error) {
+	u := s.objectURL(objectKey, q)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = size
+	if body == nil {
+		req.Body = http.NoBody
+	}
+	signV4(req, s.cfg.AccessKey, s.cfg.SecretKey, s.cfg.Region, s.now())
+	return s.client.Do(req)
+}
+
+func drain(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	_ = resp.Body.Close()
+}
+
+// signV4 adds a Signature Version 4 Authorization header. Payloads are sent
+// unsigned so large bodies can stream without hashing them first.
+func signV4(req *http.Request, accessKey, secretKey, region string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", unsignedPayload)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + req.URL.Host + "\n" +
+		"x-amz-content-sha256:" + unsignedPayload + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		unsignedPayload,
+	}, "\n")
+	scope := now.Format("20060102") + "/" + region + "/s3/aws4_request"
+	signature := signatureV4(secretKey, now, region, amzDate, scope, canonicalRequest)
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+// presignV4 returns u signed with query parameters for a GET request.
+func presignV4(u *url.URL, accessKey, secretKey, region string, now time.Time, ttl time.Duration) string {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	scope := now.Format("20060102") + "/" + region + "/s3/aws4_request"
+	q := u.Query()
+	q.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	q.Set("X-Amz-Credential", accessKey+"/"+scope)
+	q.Set("X-Amz-Date", amzDate)
+	q.Set("X-Amz-Expires", strconv.FormatInt(int64(ttl/time.Second), 10))
+	q.Set("X-Amz-SignedHeaders", "host")
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		u.EscapedPath(),
+		canonicalQuery(q),
+		"host:" + u.Host + "\n",
+		"host",
+		unsignedPayload,
+	}, "\n")
+	q.Set("X-Amz-Signature", signatureV4(secretKey, now, region, amzDate, scope, canonicalRequest))
+	signed := *u
+	signed.RawQuery = canonicalQuery(q)
+	return signed.String()
+}
+
+func signatureV4(secretKey string, now time.Time, region, amzDate, scope, canonicalRequest string) string {
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+	key := hmacSHA256([]byte("AWS4"+secretKey), now.Format("20060102"))
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// canonicalQuery encodes q sorted by key with AWS percent-encoding.
+func canonicalQuery(q url.Values) string {
+	if len(q) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		vals := append([]string(nil), q[k]...)
+		sort.Strings(vals)
+		for _, v := range vals {
+			if b.Len() > 0 {
+				b.WriteByte('&')
+			}
+			b.WriteString(awsEscape(k, true))
+			b.WriteByte('=')
+			b.WriteString(awsEscape(v, true))
+		}
+	}
+	return b.String()
+}
+
+func awsEscapePath(p string) string { return awsEscape(p, false) }
+
+// awsEscape percent-encodes everything except RFC 3986 unreserved characters
+// (and '/' in paths), as SigV4 requires.
+func awsEscape(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,195 @@
+// Package sink uploads completed downloads to remote storage (S3-compatible
+// object stores or WebDAV) and hands out links to the uploaded copies.
+package sink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sink is a remote destination for completed files.
+type Sink interface {
+	// Name identifies the sink kind recorded on rows ("s3", "webdav").
+	Name() string
+	// Upload copies the local file at path to key. progress receives the
+	// number of bytes sent so far and may be nil.
+	Upload(ctx context.Context, key, path string, progress func(sent int64)) error
+	// URL returns a link clients can fetch key from, valid for at least ttl
+	// when the sink supports expiring links.
+	URL(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
+
+// Streamer is implemented by sinks whose URLs need the sink's credentials,
+// so clients cannot follow them on their own. Open fetches key, sending
+// byteRange as the Range header when set; the caller closes the body.
+type Streamer interface {
+	Open(ctx context.Context, key, byteRange string) (*http.Response, error)
+}
+
+// ErrUnsupportedSink is returned by FromURL for unknown schemes.
+var ErrUnsupportedSink = errors.New("unsupported upload sink")
+
+// FromURL builds a sink from a --upload-sink value:
+//
+//	s3://bucket/prefix?endpoint=https://s3.example.com&region=us-east-1&part-size-mb=16
+//	webdav+https://user@dav.example.com/archive
+//
+// Credentials are read through env: AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY
+// for S3, VIDEOFETCH_WEBDAV_USER and VIDEOFETCH_WEBDAV_PASSWORD for WebDAV.
+func FromURL(spec string, env func(string) string) (Sink, error) {
+	u, err := url.Parse(spec)
+	if err != nil {
+		return nil, fmt.Errorf("parse upload sink: %w", err)
+	}
+	if env == nil {
+		env = func(string) string { return "" }
+	}
+	switch u.Scheme {
+	case "s3":
+		return s3FromURL(u, env)
+	case "webdav+http", "webdav+https":
+		return webDAVFromURL(u, env)
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnsupportedSink, u.Scheme)
+}
+
+func s3FromURL(u *url.URL, env func(string) string) (*S3, error) {
+	q := u.Query()
+	cfg := S3Config{
+		Endpoint:  q.Get("endpoint"),
+		Region:    q.Get("region"),
+		Bucket:    u.Host,
+		Prefix:    strings.Trim(u.Path, "/"),
+		AccessKey: env("AWS_ACCESS_KEY_ID"),
+		SecretKey: env("AWS_SECRET_ACCESS_KEY"),
+	}
+	if v := q.Get("part-size-mb"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid part-size-mb: %q", v)
+		}
+		cfg.PartSize = n << 20
+	}
+	return NewS3(cfg)
+}
+
+func webDAVFromURL(u *url.URL, env func(string) string) (*WebDAV, error) {
+	base := *u
+	base.Scheme = strings.TrimPrefix(u.Scheme, "webdav+")
+	base.User = nil
+	cfg := WebDAVConfig{BaseURL: base.String()}
+	if u.User != nil {
+		cfg.Username = u.User.Username()
+		cfg.Password, _ = u.User.Password()
+	}
+	if v := env("VIDEOFETCH_WEBDAV_USER"); v != "" {
+		cfg.Username = v
+	}
+	if v := env("VIDEOFETCH_WEBDAV_PASSWORD"); v != "" {
+		cfg.Password = v
+	}
+	return NewWebDAV(cfg)
+}
+
+// StatusError is a non-success HTTP response from the remote.
+type StatusError struct {
+	Op     string
+	Status int
+	Body   string
+}
+
+func (e *StatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("%s: http %d", e.Op, e.Status)
+	}
+	return fmt.Sprintf("%s: http %d: %s", e.Op, e.Status, e.Body)
+}
+
+func statusError(op string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return &StatusError{Op: op, Status: resp.StatusCode, Body: strings.TrimSpace(string(body))}
+}
+
+// retryable reports errors worth another attempt: network failures, 429 and 5xx.
+func retryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var se *StatusError
+	if errors.As(err, &se) {
+		return se.Status == http.StatusTooManyRequests || se.Status >= 500
+	}
+	var ne net.Error
+	if errors.As(err, &ne) {
+		return true
+	}
+	return errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// retryPolicy retries a request with exponential backoff.
+type retryPolicy struct {
+	attempts int
+	backoff  time.Duration
+}
+
+var defaultRetry = retryPolicy{attempts: 4, backoff: 500 * time.Millisecond}
+
+func (p retryPolicy) do(ctx context.Context, fn func() error) error {
+	attempts := p.attempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	delay := p.backoff
+	var err error
+	for i := 0; i < attempts; i++ {
+		if err = fn(); err == nil || !retryable(err) {
+			return err
+		}
+		if i == attempts-1 {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+	return err
+}
+
+// countingReader reports bytes read to fn, offset by base.
+type countingReader struct {
+	r    io.Reader
+	base int64
+	n    int64
+	fn   func(int64)
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	if n > 0 {
+		c.n += int64(n)
+		if c.fn != nil {
+			c.fn(c.base + c.n)
+		}
+	}
+	return n, err
+}
+
+// joinKey joins a prefix and a slash-separated key.
+func joinKey(prefix, key string) string {
+	key = strings.TrimLeft(key, "/")
+	if prefix == "" {
+		return key
+	}
+	return strings.TrimRight(prefix, "/") + "/" + key
+}
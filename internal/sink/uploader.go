@@ -0,0 +1,313 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"videofetch/internal/logging"
+	"videofetch/internal/store"
+)
+
+// UploadStore is the subset of store operations the uploader needs.
+type UploadStore interface {
+	GetDownloadByID(ctx context.Context, id int64) (store.Download, bool, error)
+	UpdateUpload(ctx context.Context, id int64, phase string, progress float64, errMsg string) error
+	MarkUploaded(ctx context.Context, id int64, sink, key string) error
+	ListUploadsByPhase(ctx context.Context, phase string) ([]int64, error)
+}
+
+// UploaderOptions tunes the uploader.
+type UploaderOptions struct {
+	// DeleteLocal removes local files once every file of a row is uploaded.
+	DeleteLocal bool
+	// Workers is the number of concurrent uploads; 0 uses 2.
+	Workers int
+	// URLTTL is how long links from RemoteURL stay valid; 0 uses one hour.
+	URLTTL time.Duration
+}
+
+// progressInterval throttles upload progress writes to the store.
+const progressInterval = 500 * time.Millisecond
+
+// Uploader copies completed downloads to a Sink and records the remote location.
+type Uploader struct {
+	store     UploadStore
+	sink      Sink
+	outputDir string
+	opts      UploaderOptions
+
+	queue   chan int64
+	mu      sync.Mutex
+	pending map[int64]struct{}
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewUploader creates an uploader. Rows without a recorded library root
+// resolve their files against outputDir.
+func NewUploader(st UploadStore, s Sink, outputDir string, opts UploaderOptions) *Uploader {
+	if opts.Workers <= 0 {
+		opts.Workers = 2
+	}
+	if opts.URLTTL <= 0 {
+		opts.URLTTL = time.Hour
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Uploader{
+		store:     st,
+		sink:      s,
+		outputDir: outputDir,
+		opts:      opts,
+		queue:     make(chan int64, 1024),
+		pending:   make(map[int64]struct{}),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// Start launches the workers and requeues rows whose upload was interrupted.
+func (u *Uploader) Start() {
+	for i := 0; i < u.opts.Workers; i++ {
+		u.wg.Add(1)
+		go u.worker()
+	}
+	ids, err := u.store.ListUploadsByPhase(u.ctx, store.UploadPhaseUploading)
+	if err != nil {
+		slog.Warn("failed to list interrupted uploads", "event", "upload_resume_error", "error", err)
+		return
+	}
+	for _, id := range ids {
+		u.Enqueue(id)
+	}
+}
+
+// Stop cancels in-flight uploads and waits for workers to exit. Interrupted
+// rows stay in the uploading phase and are picked up by the next Start.
+func (u *Uploader) Stop() {
+	u.cancel()
+	u.wg.Wait()
+}
+
+// Enqueue schedules a completed row for upload. Rows already queued are skipped.
+func (u *Uploader) Enqueue(dbID int64) {
+	if dbID <= 0 {
+		return
+	}
+	u.mu.Lock()
+	if _, dup := u.pending[dbID]; dup {
+		u.mu.Unlock()
+		return
+	}
+	u.pending[dbID] = struct{}{}
+	u.mu.Unlock()
+
+	// Mark the row first so a restart resumes it even if it never left the queue.
+	if err := u.store.UpdateUpload(context.Background(), dbID, store.UploadPhaseUploading, 0, ""); err != nil {
+		logging.LogDBOperation("update_upload", dbID, err)
+	}
+	select {
+	case u.queue <- dbID:
+	case <-u.ctx.Done():
+	}
+}
+
+// RemoteURL returns a link to an uploaded file for the configured sink.
+func (u *Uploader) RemoteURL(ctx context.Context, sinkName, key string) (string, error) {
+	if sinkName != u.sink.Name() {
+		return "", fmt.Errorf("upload sink %q is not configured", sinkName)
+	}
+	return u.sink.URL(ctx, key, u.opts.URLTTL)
+}
+
+// OpenRemote fetches an uploaded file through the configured sink when the
+// sink is a Streamer; streamed is false when clients can follow RemoteURL
+// themselves. The caller closes the response body.
+func (u *Uploader) OpenRemote(ctx context.Context, sinkName, key, byteRange string) (*http.Response, bool, error) {
+	st, ok := u.sink.(Streamer)
+	if !ok {
+		return nil, false, nil
+	}
+	if sinkName != u.sink.Name() {
+		return nil, true, fmt.Errorf("upload sink %q is not configured", sinkName)
+	}
+	resp, err := st.Open(ctx, key, byteRange)
+	return resp, true, err
+}
+
+func (u *Uploader) worker() {
+	defer u.wg.Done()
+	for {
+		select {
+		case <-u.ctx.Done():
+			return
+		case id := <-u.queue:
+			u.upload(id)
+			u.mu.Lock()
+			delete(u.pending, id)
+			u.mu.Unlock()
+		}
+	}
+}
+
+type uploadFile struct {
+	path string
+	key  string
+	size int64
+}
+
+func (u *Uploader) upload(id int64) {
+	ctx := u.ctx
+	row, found, err := u.store.GetDownloadByID(ctx, id)
+	if err != nil || !found || row.Status != "completed" {
+		if err != nil {
+			logging.LogDBOperation("get_download", id, err)
+		}
+		return
+	}
+	files, primary, err := u.collect(row)
+	if err != nil {
+		u.fail(id, err)
+		return
+	}
+
+	var total int64
+	for _, f := range files {
+		total += f.size
+	}
+	// report runs on the HTTP transport's body-writing goroutine.
+	var (
+		progressMu sync.Mutex
+		done       int64
+		lastWrite  time.Time
+	)
+	report := func(sent int64) {
+		progressMu.Lock()
+		defer progressMu.Unlock()
+		if total <= 0 || time.Since(lastWrite) < progressInterval {
+			return
+		}
+		lastWrite = time.Now()
+		pct := float64(done+sent) * 100 / float64(total)
+		if err := u.store.UpdateUpload(ctx, id, store.UploadPhaseUploading, pct, ""); err != nil {
+			logging.LogDBOperation("update_upload", id, err)
+		}
+	}
+
+	start := time.Now()
+	for _, f := range files {
+		if err := u.sink.Upload(ctx, f.key, f.path, report); err != nil {
+			if errors.Is(err, context.Canceled) {
+				return // leave the row in the uploading phase for the next start
+			}
+			u.fail(id, err)
+			return
+		}
+		progressMu.Lock()
+		done += f.size
+		progressMu.Unlock()
+	}
+	if err := u.store.MarkUploaded(ctx, id, u.sink.Name(), primary); err != nil {
+		logging.LogDBOperation("mark_uploaded", id, err)
+		return
+	}
+	slog.Info("upload completed",
+		"event", "upload_done",
+		"db_id", id,
+		"sink", u.sink.Name(),
+		"key", primary,
+		"files", len(files),
+		"bytes", total,
+		"duration_ms", time.Since(start).Milliseconds())
+
+	if u.opts.DeleteLocal {
+		for _, f := range files {
+			if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+				slog.Warn("failed to delete local copy after upload", "event", "upload_delete_local_error", "db_id", id, "error", err)
+			}
+		}
+	}
+}
+
+func (u *Uploader) fail(id int64, err error) {
+	slog.Error("upload failed", "event", "upload_error", "db_id", id, "sink", u.sink.Name(), "error", err)
+	if uerr := u.store.UpdateUpload(u.ctx, id, store.UploadPhaseFailed, 0, err.Error()); uerr != nil {
+		logging.LogDBOperation("update_upload", id, uerr)
+	}
+}
+
+// collect lists the row's finished files under its library root. Keys mirror
+// the path relative to the root; primary is the key of the main file.
+func (u *Uploader) collect(row store.Download) ([]uploadFile, string, error) {
+	root := row.LibraryRoot
+	if root == "" {
+		root = u.outputDir
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, "", err
+	}
+	if strings.TrimSpace(row.Filename) == "" {
+		return nil, "", errors.New("row has no file to upload")
+	}
+	mainPath := filepath.Join(absRoot, row.Filename)
+
+	seen := make(map[string]struct{})
+	var files []uploadFile
+	primary := ""
+	for _, p := range append([]string{mainPath}, row.ArtifactPaths...) {
+		full := strings.TrimSpace(p)
+		if full == "" {
+			continue
+		}
+		if !filepath.IsAbs(full) {
+			full = filepath.Join(absRoot, full)
+		}
+		full = filepath.Clean(full)
+		if _, dup := seen[full]; dup {
+			continue
+		}
+		seen[full] = struct{}{}
+		rel, err := filepath.Rel(absRoot, full)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if isTempArtifact(rel) {
+			continue
+		}
+		info, err := os.Stat(full)
+		if err == nil && !info.Mode().IsRegular() {
+			err = errors.New("not a regular file")
+		}
+		if err != nil {
+			if full == mainPath {
+				return nil, "", fmt.Errorf("%s: %w", row.Filename, err)
+			}
+			continue
+		}
+		key := filepath.ToSlash(rel)
+		if full == mainPath {
+			primary = key
+		}
+		files = append(files, uploadFile{path: full, key: key, size: info.Size()})
+	}
+	return files, primary, nil
+}
+
+// isTempArtifact skips yt-dlp working files that may still be tracked.
+func isTempArtifact(rel string) bool {
+	name := filepath.Base(rel)
+	return strings.HasPrefix(filepath.ToSlash(rel), ".yt-dlp-tmp/") ||
+		strings.HasSuffix(name, ".part") ||
+		strings.HasSuffix(name, ".ytdl") ||
+		strings.Contains(name, ".part-Frag")
+}
@@ -0,0 +1,151 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"videofetch/internal/store"
+)
+
+type recordingSink struct {
+	mu   sync.Mutex
+	keys []string
+	err  error
+}
+
+func (s *recordingSink) Name() string { return "fake" }
+
+func (s *recordingSink) Upload(ctx context.Context, key, path string, progress func(int64)) error {
+	if s.err != nil {
+		return s.err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if progress != nil {
+		progress(info.Size())
+	}
+	s.mu.Lock()
+	s.keys = append(s.keys, key)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *recordingSink) URL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	return "https://remote.example/" + key, nil
+}
+
+func newUploadStore(t *testing.T) *store.Store {
+	t.Helper()
+	st, err := store.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { _ = st.Close() })
+	return st
+}
+
+func addCompletedRow(t *testing.T, st *store.Store, root, filename string, artifacts ...string) int64 {
+	t.Helper()
+	ctx := context.Background()
+	id, err := st.CreateDownloadInLibrary(ctx, "https://example.com/"+filename, filename, 0, "", "completed", 100, "default", root)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	_ = st.UpdateFilename(ctx, id, filename)
+	_ = st.UpdateArtifacts(ctx, id, artifacts)
+	return id
+}
+
+func waitForPhase(t *testing.T, st *store.Store, id int64, phase string) store.Download {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		row, _, _ := st.GetDownloadByID(context.Background(), id)
+		if row.UploadPhase == phase {
+			return row
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	row, _, _ := st.GetDownloadByID(context.Background(), id)
+	t.Fatalf("row %d never reached %s: %+v", id, phase, row)
+	return store.Download{}
+}
+
+func TestUploader_UploadsFilesRecordsLocationAndDeletesLocal(t *testing.T) {
+	st := newUploadStore(t)
+	root := t.TempDir()
+	main := writeTestFile(t, root, "Artist/song.opus", 100)
+	thumb := writeTestFile(t, root, "Artist/song.jpg", 10)
+	partial := writeTestFile(t, root, "Artist/song.opus.part", 10)
+	outside := writeTestFile(t, t.TempDir(), "elsewhere.txt", 10)
+	id := addCompletedRow(t, st, root, "Artist/song.opus", main, thumb, partial, outside)
+
+	snk := &recordingSink{}
+	u := NewUploader(st, snk, t.TempDir(), UploaderOptions{DeleteLocal: true, Workers: 1})
+	u.Start()
+	defer u.Stop()
+	u.Enqueue(id)
+
+	row := waitForPhase(t, st, id, store.UploadPhaseUploaded)
+	if row.RemoteSink != "fake" || row.RemoteKey != "Artist/song.opus" || row.UploadProgress != 100 {
+		t.Fatalf("expected remote location recorded, got %+v", row)
+	}
+	snk.mu.Lock()
+	keys := append([]string(nil), snk.keys...)
+	snk.mu.Unlock()
+	sort.Strings(keys)
+	if len(keys) != 2 || keys[0] != "Artist/song.jpg" || keys[1] != "Artist/song.opus" {
+		t.Fatalf("expected main file and thumbnail uploaded, got %v", keys)
+	}
+	for _, p := range []string{main, thumb} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Fatalf("expected %s deleted after upload, got %v", p, err)
+		}
+	}
+	if _, err := os.Stat(outside); err != nil {
+		t.Fatalf("file outside the library root must be left alone: %v", err)
+	}
+
+	link, err := u.RemoteURL(context.Background(), row.RemoteSink, row.RemoteKey)
+	if err != nil || link != "https://remote.example/Artist/song.opus" {
+		t.Fatalf("unexpected remote link %q (%v)", link, err)
+	}
+	if _, err := u.RemoteURL(context.Background(), "s3", row.RemoteKey); err == nil {
+		t.Fatalf("expected error for a sink that is not configured")
+	}
+}
+
+func TestUploader_RecordsFailureAndResumesInterruptedRows(t *testing.T) {
+	st := newUploadStore(t)
+	root := t.TempDir()
+	writeTestFile(t, root, "a.mp4", 10)
+	failing := addCompletedRow(t, st, root, "a.mp4")
+
+	u := NewUploader(st, &recordingSink{err: errors.New("remote down")}, root, UploaderOptions{Workers: 1})
+	u.Start()
+	u.Enqueue(failing)
+	row := waitForPhase(t, st, failing, store.UploadPhaseFailed)
+	u.Stop()
+	if row.UploadError != "remote down" {
+		t.Fatalf("expected upload error recorded, got %q", row.UploadError)
+	}
+
+	// A row left in the uploading phase by a previous run is picked up on start.
+	writeTestFile(t, root, "b.mp4", 10)
+	interrupted := addCompletedRow(t, st, root, "b.mp4")
+	if err := st.UpdateUpload(context.Background(), interrupted, store.UploadPhaseUploading, 40, ""); err != nil {
+		t.Fatalf("update upload: %v", err)
+	}
+	u = NewUploader(st, &recordingSink{}, root, UploaderOptions{Workers: 1})
+	u.Start()
+	defer u.Stop()
+	waitForPhase(t, st, interrupted, store.UploadPhaseUploaded)
+}
@@ -0,0 +1,170 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// WebDAVConfig configures a WebDAV collection that receives uploads.
+type WebDAVConfig struct {
+	BaseURL  string // collection URL, e.g. https://dav.example.com/archive
+	Username string
+	Password string
+	Client   *http.Client
+}
+
+// WebDAV uploads files with PUT, creating parent collections as needed.
+type WebDAV struct {
+	cfg    WebDAVConfig
+	base   *url.URL
+	client *http.Client
+	retry  retryPolicy
+}
+
+// NewWebDAV validates cfg and returns a WebDAV sink.
+func NewWebDAV(cfg WebDAVConfig) (*WebDAV, error) {
+	base, err := url.Parse(cfg.BaseURL)
+	if err != nil || (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+		return nil, fmt.Errorf("webdav sink: invalid base URL %q", cfg.BaseURL)
+	}
+	base.Path = strings.TrimRight(base.Path, "/")
+	base.RawPath = ""
+	client := cfg.Client
+	if client == nil {
+		client = &http.Client{Timeout: 15 * time.Minute}
+	}
+	return &WebDAV{cfg: cfg, base: base, client: client, retry: defaultRetry}, nil
+}
+
+// Name implements Sink.
+func (d *WebDAV) Name() string { return "webdav" }
+
+// Upload implements Sink.
+func (d *WebDAV) Upload(ctx context.Context, key, path string, progress func(sent int64)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	key = strings.Trim(key, "/")
+	if err := d.mkcolParents(ctx, key); err != nil {
+		return err
+	}
+	return d.retry.do(ctx, func() error {
+		body := &countingReader{r: io.NewSectionReader(f, 0, info.Size()), fn: progress}
+		req, err := d.request(ctx, http.MethodPut, key, body)
+		if err != nil {
+			return err
+		}
+		req.ContentLength = info.Size()
+		resp, err := d.client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer drain(resp)
+		switch resp.StatusCode {
+		case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+			return nil
+		}
+		return statusError("webdav put", resp)
+	})
+}
+
+// mkcolParents creates each parent collection of key. 405 means it exists.
+func (d *WebDAV) mkcolParents(ctx context.Context, key string) error {
+	segs := strings.Split(key, "/")
+	for i := 1; i < len(segs); i++ {
+		dir := strings.Join(segs[:i], "/")
+		err := d.retry.do(ctx, func() error {
+			req, err := d.request(ctx, "MKCOL", dir+"/", nil)
+			if err != nil {
+				return err
+			}
+			resp, err := d.client.Do(req)
+			if err != nil {
+				return err
+			}
+			defer drain(resp)
+			switch resp.StatusCode {
+			case http.StatusCreated, http.StatusOK, http.StatusMethodNotAllowed:
+				return nil
+			}
+			return statusError("webdav mkcol", resp)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// URL implements Sink. WebDAV has no expiring links, so this is the plain
+// resource URL; clients need the collection's credentials to fetch it, which
+// is why WebDAV also implements Streamer.
+func (d *WebDAV) URL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	if strings.Trim(key, "/") == "" {
+		return "", errors.New("webdav sink: empty key")
+	}
+	return d.resourceURL(strings.Trim(key, "/")).String(), nil
+}
+
+// Open implements Streamer. Ranged requests are passed through, so a 206 or
+// 416 response is returned as is.
+func (d *WebDAV) Open(ctx context.Context, key, byteRange string) (*http.Response, error) {
+	key = strings.Trim(key, "/")
+	if key == "" {
+		return nil, errors.New("webdav sink: empty key")
+	}
+	req, err := d.request(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	if byteRange != "" {
+		req.Header.Set("Range", byteRange)
+	}
+	// The body is streamed to a client at its own pace, so the upload
+	// client's overall timeout does not apply.
+	client := *d.client
+	client.Timeout = 0
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusPartialContent, http.StatusRequestedRangeNotSatisfiable:
+		return resp, nil
+	}
+	defer drain(resp)
+	return nil, statusError("webdav get", resp)
+}
+
+func (d *WebDAV) resourceURL(key string) *url.URL {
+	u := *d.base
+	u.Path = u.Path + "/" + key
+	return &u
+}
+
+func (d *WebDAV) request(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, d.resourceURL(key).String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if body == nil {
+		req.Body = http.NoBody
+	}
+	if d.cfg.Username != "" || d.cfg.Password != "" {
+		req.SetBasicAuth(d.cfg.Username, d.cfg.Password)
+	}
+	return req, nil
+}
@@ -0,0 +1,132 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeDAV is an in-memory WebDAV server that, like real ones, refuses PUT and
+// MKCOL when the parent collection is missing.
+type fakeDAV struct {
+	mu    sync.Mutex
+	dirs  map[string]bool
+	files map[string][]byte
+	puts  int
+	fail  int // PUTs to reject with 502 before accepting
+}
+
+func newFakeDAV() *fakeDAV {
+	return &fakeDAV{dirs: map[string]bool{"/dav": true}, files: map[string][]byte{}}
+}
+
+func (f *fakeDAV) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if user, pass, ok := r.BasicAuth(); !ok || user != "alice" || pass != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	p := strings.TrimRight(r.URL.Path, "/")
+	if !f.dirs[path.Dir(p)] {
+		w.WriteHeader(http.StatusConflict)
+		return
+	}
+	switch r.Method {
+	case "MKCOL":
+		if f.dirs[p] {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		f.dirs[p] = true
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodPut:
+		f.puts++
+		if f.fail > 0 {
+			f.fail--
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		f.files[p] = body
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodGet:
+		body, ok := f.files[p]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(body))
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func TestWebDAV_UploadCreatesCollectionsAndRetries(t *testing.T) {
+	fake := newFakeDAV()
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	d, err := NewWebDAV(WebDAVConfig{BaseURL: srv.URL + "/dav/", Username: "alice", Password: "secret"})
+	if err != nil {
+		t.Fatalf("new webdav: %v", err)
+	}
+	d.retry = retryPolicy{attempts: 3, backoff: time.Millisecond}
+	fake.fail = 1
+
+	file := writeTestFile(t, t.TempDir(), "song.opus", 4096)
+	var last int64
+	if err := d.Upload(context.Background(), "Artist/Album/song.opus", file, func(sent int64) { last = sent }); err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+	if got := fake.files["/dav/Artist/Album/song.opus"]; len(got) != 4096 || fake.puts != 2 {
+		t.Fatalf("expected file stored after one retry, got %d bytes in %d puts", len(got), fake.puts)
+	}
+	if last != 4096 {
+		t.Fatalf("expected progress to reach 4096, got %d", last)
+	}
+	// Existing collections answer 405, which must not fail a second upload.
+	if err := d.Upload(context.Background(), "Artist/Album/song2.opus", file, nil); err != nil {
+		t.Fatalf("second upload: %v", err)
+	}
+
+	link, err := d.URL(context.Background(), "Artist/Album/song.opus", time.Hour)
+	if err != nil || link != srv.URL+"/dav/Artist/Album/song.opus" || strings.Contains(link, "secret") {
+		t.Fatalf("unexpected link %q (%v)", link, err)
+	}
+}
+
+func TestWebDAV_OpenStreamsWithCredentialsAndRanges(t *testing.T) {
+	fake := newFakeDAV()
+	fake.files["/dav/a/clip.mp4"] = []byte("0123456789")
+	fake.dirs["/dav/a"] = true
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	d, err := NewWebDAV(WebDAVConfig{BaseURL: srv.URL + "/dav", Username: "alice", Password: "secret"})
+	if err != nil {
+		t.Fatalf("new webdav: %v", err)
+	}
+
+	resp, err := d.Open(context.Background(), "a/clip.mp4", "bytes=2-4")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent || string(body) != "234" {
+		t.Fatalf("expected ranged body, got %d %q", resp.StatusCode, body)
+	}
+
+	var se *StatusError
+	if _, err := d.Open(context.Background(), "a/missing.mp4", ""); !errors.As(err, &se) || se.Status != http.StatusNotFound {
+		t.Fatalf("expected a 404 status error, got %v", err)
+	}
+}
@@ -5,4 +5,6 @@ import "errors"
 var (
 	// ErrEmptyURL indicates a URL parameter is missing or empty
 	ErrEmptyURL = errors.New("empty_url")
+	// ErrInvalidTag indicates a tag name that is too long or has forbidden characters
+	ErrInvalidTag = errors.New("invalid_tag")
 )
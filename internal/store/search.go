@@ -0,0 +1,140 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"html"
+	"strings"
+	"unicode"
+
+	"videofetch/internal/logging"
+)
+
+// MaxSearchTerms caps the number of words of a search query; extra words are ignored.
+const MaxSearchTerms = 16
+
+// snippet() wraps matches in these bytes; snippetHTML turns them into <mark> tags
+// after escaping the text around them.
+const (
+	snippetOpen  = '\x02'
+	snippetClose = '\x03'
+)
+
+// searchDDL keeps downloads_fts in sync with the searchable downloads columns.
+// The index is external-content: it stores only tokens and reads text back from downloads.
+const searchDDL = `
+CREATE TRIGGER IF NOT EXISTS downloads_fts_ai AFTER INSERT ON downloads BEGIN
+    INSERT INTO downloads_fts (rowid, title, url, uploader, description)
+    VALUES (new.id, new.title, new.url, new.uploader, new.description);
+END;
+CREATE TRIGGER IF NOT EXISTS downloads_fts_ad AFTER DELETE ON downloads BEGIN
+    INSERT INTO downloads_fts (downloads_fts, rowid, title, url, uploader, description)
+    VALUES ('delete', old.id, old.title, old.url, old.uploader, old.description);
+END;
+CREATE TRIGGER IF NOT EXISTS downloads_fts_au AFTER UPDATE OF title, url, uploader, description ON downloads BEGIN
+    INSERT INTO downloads_fts (downloads_fts, rowid, title, url, uploader, description)
+    VALUES ('delete', old.id, old.title, old.url, old.uploader, old.description);
+    INSERT INTO downloads_fts (rowid, title, url, uploader, description)
+    VALUES (new.id, new.title, new.url, new.uploader, new.description);
+END;
+`
+
+// searchHitsQuery selects matching rowids with their bm25 rank and a snippet
+// from the best matching column. It binds the MATCH expression.
+const searchHitsQuery = `SELECT rowid, rank, snippet(downloads_fts, -1, char(2), char(3), '…', 16) AS snippet FROM downloads_fts WHERE downloads_fts MATCH ?`
+
+// ensureSearchIndex creates the full-text index and its triggers, and indexes
+// existing rows the first time it runs on a database.
+func ensureSearchIndex(db *sql.DB) error {
+	var existing int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'downloads_fts'`).Scan(&existing); err != nil {
+		return err
+	}
+	if existing == 0 {
+		if _, err := db.Exec(`
+CREATE VIRTUAL TABLE downloads_fts USING fts5(
+    title, url, uploader, description,
+    content = 'downloads', content_rowid = 'id',
+    tokenize = 'unicode61 remove_diacritics 2'
+)`); err != nil {
+			return err
+		}
+		if _, err := db.Exec(`INSERT INTO downloads_fts (downloads_fts) VALUES ('rebuild')`); err != nil {
+			return err
+		}
+	}
+	_, err := db.Exec(searchDDL)
+	return err
+}
+
+// ftsQuery turns free text into an FTS5 expression: every word must match,
+// each as a prefix. FTS5 operators and punctuation are not passed through.
+// It returns "" when the text has no words.
+func ftsQuery(q string) string {
+	words := strings.FieldsFunc(q, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsNumber(r) })
+	if len(words) > MaxSearchTerms {
+		words = words[:MaxSearchTerms]
+	}
+	terms := make([]string, 0, len(words))
+	for _, w := range words {
+		terms = append(terms, `"`+w+`"*`)
+	}
+	return strings.Join(terms, " ")
+}
+
+// snippetHTML escapes a raw FTS5 snippet and wraps the marked matches in <mark>.
+func snippetHTML(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	var b strings.Builder
+	for {
+		start := strings.IndexRune(raw, snippetOpen)
+		if start < 0 {
+			break
+		}
+		end := strings.IndexRune(raw[start:], snippetClose)
+		if end < 0 {
+			break
+		}
+		end += start
+		b.WriteString(html.EscapeString(raw[:start]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(raw[start+1 : end]))
+		b.WriteString("</mark>")
+		raw = raw[end+1:]
+	}
+	raw = strings.Map(func(r rune) rune {
+		if r == snippetOpen || r == snippetClose {
+			return -1
+		}
+		return r
+	}, raw)
+	b.WriteString(html.EscapeString(raw))
+	return b.String()
+}
+
+// UpdateDetails stores the uploader and description of a download if non-empty values are provided.
+func (s *Store) UpdateDetails(ctx context.Context, id int64, uploader, description string) error {
+	sets := make([]string, 0, 2)
+	args := make([]any, 0, 4)
+	if uploader != "" {
+		sets = append(sets, "uploader = ?")
+		args = append(args, uploader)
+	}
+	if description != "" {
+		sets = append(sets, "description = ?")
+		args = append(args, description)
+	}
+	if len(sets) == 0 {
+		return nil
+	}
+	sets = append(sets, "updated_at = ?")
+	args = append(args, sqliteTimestampNow(), id)
+	if _, err := s.db.ExecContext(ctx, "UPDATE downloads SET "+strings.Join(sets, ", ")+" WHERE id = ?", args...); err != nil {
+		return err
+	}
+	logging.LogDBUpdate("update_details", id, map[string]any{"uploader": uploader, "description_len": len(description)})
+	s.emitChange(ChangeEvent{Type: ChangeUpsert, ID: id})
+	return nil
+}
@@ -0,0 +1,97 @@
+package store
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestFTSQuery(t *testing.T) {
+	cases := map[string]string{
+		"":                          "",
+		"  ":                        "",
+		"Go talk":                   `"Go"* "talk"*`,
+		`title:"x" OR NEAR(a b) -c`: `"title"* "x"* "OR"* "NEAR"* "a"* "b"* "c"*`,
+		"youtube.com/watch":         `"youtube"* "com"* "watch"*`,
+		"café":                      `"café"*`,
+	}
+	for in, want := range cases {
+		if got := ftsQuery(in); got != want {
+			t.Errorf("ftsQuery(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSnippetHTML(t *testing.T) {
+	got := snippetHTML("a <b> \x02Go\x03 talk & \x02more\x03 \x02stray")
+	want := "a &lt;b&gt; <mark>Go</mark> talk &amp; <mark>more</mark> stray"
+	if got != want {
+		t.Fatalf("snippetHTML = %q, want %q", got, want)
+	}
+}
+
+func TestSearch_RanksFiltersAndFollowsUpdates(t *testing.T) {
+	st := setupTestStore(t)
+	defer st.Close()
+	ctx := context.Background()
+
+	mk := func(url, title, status string) int64 {
+		id, err := st.CreateDownload(ctx, url, title, 0, "", status, 0)
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		return id
+	}
+	talk := mk("https://example.com/v/1", "Concurrency in Go", "completed")
+	cooking := mk("https://example.com/v/2", "Pasta night", "completed")
+	mention := mk("https://videos.example.org/v/3", "Weekly update", "error")
+	if err := st.UpdateDetails(ctx, cooking, "Chef Café", "How to cook pasta while learning Go"); err != nil {
+		t.Fatalf("details: %v", err)
+	}
+	if err := st.UpdateDetails(ctx, mention, "", "Mentions concurrency briefly"); err != nil {
+		t.Fatalf("details: %v", err)
+	}
+
+	rows, err := st.ListDownloads(ctx, ListFilter{Query: "concurrency"})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(rows) != 2 || rows[0].ID != talk || rows[1].ID != mention {
+		t.Fatalf("expected title match ranked above description match, got %+v", rows)
+	}
+	if rows[0].Snippet != "<mark>Concurrency</mark> in Go" {
+		t.Fatalf("unexpected snippet %q", rows[0].Snippet)
+	}
+
+	// Prefixes, diacritics, uploader and URL are searchable.
+	for q, want := range map[string]int64{"cafe": cooking, "past": cooking, "videos.example.org": mention} {
+		rows, _ := st.ListDownloads(ctx, ListFilter{Query: q})
+		if len(rows) != 1 || rows[0].ID != want {
+			t.Fatalf("query %q: expected row %d, got %+v", q, want, rows)
+		}
+	}
+	if rows, _ := st.ListDownloads(ctx, ListFilter{Query: "go", Status: "completed", Sort: "title", Order: "asc"}); len(rows) != 2 || rows[0].ID != talk {
+		t.Fatalf("expected filters and explicit sort applied to search, got %+v", rows)
+	}
+	if rows, _ := st.ListDownloads(ctx, ListFilter{Query: `"`}); len(rows) != 3 || rows[0].Snippet != "" {
+		t.Fatalf("expected a query without words to list everything, got %d rows", len(rows))
+	}
+
+	// The index follows title changes and deletions.
+	if err := st.UpdateMeta(ctx, talk, "Parallel programming", 0, ""); err != nil {
+		t.Fatalf("meta: %v", err)
+	}
+	if rows, _ := st.ListDownloads(ctx, ListFilter{Query: "concurrency"}); len(rows) != 1 || rows[0].ID != mention {
+		t.Fatalf("expected renamed row dropped from results, got %+v", rows)
+	}
+	if err := st.DeleteDownload(ctx, mention); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if rows, _ := st.ListDownloads(ctx, ListFilter{Query: "concurrency"}); len(rows) != 0 {
+		t.Fatalf("expected deleted row gone from results, got %+v", rows)
+	}
+	row, _, _ := st.GetDownloadByID(ctx, cooking)
+	if row.Uploader != "Chef Café" || !strings.Contains(row.Title, "Pasta") {
+		t.Fatalf("expected uploader on row, got %+v", row)
+	}
+}
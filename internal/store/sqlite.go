@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -16,18 +17,58 @@ import (
 
 // Download represents a row in the downloads table.
 type Download struct {
-	ID            int64     `json:"id"`
-	URL           string    `json:"url"`
-	Title         string    `json:"title"`
-	Duration      int64     `json:"duration"` // seconds
-	ThumbnailURL  string    `json:"thumbnail_url"`
-	Status        string    `json:"status"`
-	Progress      float64   `json:"progress"`
-	Filename      string    `json:"filename"`
-	ArtifactPaths []string  `json:"artifact_paths,omitempty"`
-	ErrorMessage  string    `json:"error_message,omitempty"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID            int64    `json:"id"`
+	URL           string   `json:"url"`
+	Title         string   `json:"title"`
+	Duration      int64    `json:"duration"` // seconds
+	ThumbnailURL  string   `json:"thumbnail_url"`
+	Status        string   `json:"status"`
+	Progress      float64  `json:"progress"`
+	Filename      string   `json:"filename"`
+	ArtifactPaths []string `json:"artifact_paths,omitempty"`
+	ErrorMessage  string   `json:"error_message,omitempty"`
+	// ErrorClass is the stable class of a failed row's error, such as "timeout";
+	// empty for unclassified failures and rows that are not failed.
+	ErrorClass string `json:"error_class,omitempty"`
+	// Pinned rows are exempt from retention and quota eviction.
+	Pinned bool `json:"pinned"`
+	// RetentionReason is set by a retention dry-run for rows that would be removed.
+	RetentionReason string     `json:"retention_reason,omitempty"`
+	LastAccessedAt  *time.Time `json:"last_accessed_at,omitempty"`
+	// Library names the storage library the row downloads into; LibraryRoot is
+	// that library's root when the row was queued. Empty root means the default output directory.
+	Library     string `json:"library"`
+	LibraryRoot string `json:"-"`
+	// Upload state for remote sinks: UploadPhase is uploading|uploaded|upload_failed,
+	// and RemoteSink/RemoteKey locate the uploaded copy once it exists.
+	UploadPhase    string  `json:"upload_phase,omitempty"`
+	UploadProgress float64 `json:"upload_progress,omitempty"`
+	UploadError    string  `json:"upload_error,omitempty"`
+	RemoteSink     string  `json:"remote_sink,omitempty"`
+	RemoteKey      string  `json:"remote_key,omitempty"`
+	// Tags are the user-assigned labels of the row, sorted by name.
+	Tags []string `json:"tags,omitempty"`
+	// Uploader is the channel or account that published the media, from yt-dlp metadata.
+	// The description is stored for search only and not returned.
+	Uploader string `json:"uploader,omitempty"`
+	// TranscriptLang is the language of the indexed subtitle track; empty when none is indexed.
+	TranscriptLang string `json:"transcript_lang,omitempty"`
+	// MaxDurationSec is the per-request job time limit; 0 uses the library's or the global one.
+	MaxDurationSec int64 `json:"max_duration_sec,omitempty"`
+	// FormatID is the yt-dlp format pinned at enqueue time; empty uses the default selection.
+	FormatID string `json:"format_id,omitempty"`
+	// ProbeToken refers to a cached /api/probe result the DB worker may use
+	// instead of probing again. The cache is in memory, so it is not returned.
+	ProbeToken string `json:"-"`
+	// Sections are the canonical specs of the parts to download, such as
+	// "12:00-18:00" or "chapter:Intro"; empty for the whole video.
+	Sections []string `json:"sections,omitempty"`
+	// SplitChapters rows also write each chapter to its own file, tracked as artifacts.
+	SplitChapters bool `json:"split_chapters,omitempty"`
+	// Snippet is set on search results: an HTML-escaped excerpt with matches wrapped in <mark>.
+	Snippet   string    `json:"snippet,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 // Implement IncompleteDownload interface for Download
@@ -43,8 +84,9 @@ func (d *Download) GetProgress() float64    { return d.Progress }
 type Store struct {
 	db *sql.DB
 
-	subMu sync.RWMutex
-	subs  map[chan ChangeEvent]struct{}
+	subMu       sync.RWMutex
+	subs        map[chan ChangeEvent]struct{}
+	pendingSubs map[chan struct{}]struct{}
 }
 
 type ChangeType string
@@ -55,14 +97,16 @@ const (
 )
 
 type ChangeEvent struct {
-	Type ChangeType
-	ID   int64 // 0 means "resync needed"
+	Type   ChangeType
+	ID     int64  // 0 means "resync needed"
+	Status string // the row's new status when the change set it; empty otherwise
 }
 
 // Open opens or creates a SQLite database at the given path and ensures schema.
 func Open(path string) (*Store, error) {
 	// Pragmas: busy timeout and WAL for better concurrency.
-	dsn := fmt.Sprintf("file:%s?_pragma=busy_timeout(5000)&_journal_mode=WAL", path)
+	// foreign_keys lets tag links cascade when a download or tag is deleted.
+	dsn := fmt.Sprintf("file:%s?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)&_journal_mode=WAL", path)
 	db, err := sql.Open("sqlite", dsn)
 	if err != nil {
 		return nil, err
@@ -75,8 +119,9 @@ func Open(path string) (*Store, error) {
 		return nil, err
 	}
 	return &Store{
-		db:   db,
-		subs: make(map[chan ChangeEvent]struct{}),
+		db:          db,
+		subs:        make(map[chan ChangeEvent]struct{}),
+		pendingSubs: make(map[chan struct{}]struct{}),
 	}, nil
 }
 
@@ -101,6 +146,22 @@ CREATE INDEX IF NOT EXISTS idx_downloads_status ON downloads(status);
 CREATE INDEX IF NOT EXISTS idx_downloads_created_at ON downloads(created_at);
 CREATE INDEX IF NOT EXISTS idx_downloads_updated_at ON downloads(updated_at);
 CREATE INDEX IF NOT EXISTS idx_downloads_url_status ON downloads(url, status);
+CREATE TABLE IF NOT EXISTS settings (
+    key TEXT PRIMARY KEY,
+    value TEXT NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE IF NOT EXISTS tags (
+    id INTEGER PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE IF NOT EXISTS download_tags (
+    download_id INTEGER NOT NULL REFERENCES downloads(id) ON DELETE CASCADE,
+    tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (download_id, tag_id)
+);
+CREATE INDEX IF NOT EXISTS idx_download_tags_tag ON download_tags(tag_id);
 `
 	_, err := db.Exec(ddl)
 	if err != nil {
@@ -116,6 +177,69 @@ CREATE INDEX IF NOT EXISTS idx_downloads_url_status ON downloads(url, status);
 	if err := ensureColumn(db, "downloads", "error_message", "TEXT"); err != nil {
 		return err
 	}
+	if err := ensureColumn(db, "downloads", "pinned", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "downloads", "retention_reason", "TEXT"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "downloads", "last_accessed_at", "TIMESTAMP"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "downloads", "library", "TEXT NOT NULL DEFAULT '"+DefaultLibrary+"'"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "downloads", "library_root", "TEXT"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "downloads", "upload_phase", "TEXT"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "downloads", "upload_progress", "REAL NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "downloads", "upload_error", "TEXT"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "downloads", "remote_sink", "TEXT"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "downloads", "remote_key", "TEXT"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "downloads", "uploader", "TEXT"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "downloads", "description", "TEXT"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "downloads", "transcript_lang", "TEXT"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "downloads", "max_duration_sec", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "downloads", "format_id", "TEXT"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "downloads", "probe_token", "TEXT"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "downloads", "sections", "TEXT"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "downloads", "split_chapters", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "downloads", "error_class", "TEXT"); err != nil {
+		return err
+	}
+	if err := ensureSearchIndex(db); err != nil {
+		return err
+	}
+	if _, err := db.Exec(transcriptDDL); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -187,14 +311,46 @@ func (s *Store) SubscribeChanges(buffer int) (<-chan ChangeEvent, func()) {
 	return ch, unsubscribe
 }
 
+// SubscribePending returns a channel that receives a value whenever rows may
+// have become pending: a row created or set to pending, or a bulk change.
+// Notifications coalesce, so receivers should scan for all pending rows.
+// The returned unsubscribe function must be called to avoid leaks.
+func (s *Store) SubscribePending() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	s.subMu.Lock()
+	s.pendingSubs[ch] = struct{}{}
+	s.subMu.Unlock()
+
+	unsubscribe := func() {
+		s.subMu.Lock()
+		delete(s.pendingSubs, ch)
+		s.subMu.Unlock()
+	}
+	return ch, unsubscribe
+}
+
 func (s *Store) emitChange(evt ChangeEvent) {
+	notifyPending := evt.ID == 0 || evt.Status == "pending"
 	s.subMu.RLock()
 	targets := make([]chan ChangeEvent, 0, len(s.subs))
 	for ch := range s.subs {
 		targets = append(targets, ch)
 	}
+	var pendingTargets []chan struct{}
+	if notifyPending {
+		for ch := range s.pendingSubs {
+			pendingTargets = append(pendingTargets, ch)
+		}
+	}
 	s.subMu.RUnlock()
 
+	for _, ch := range pendingTargets {
+		select {
+		case ch <- struct{}{}:
+		default: // a notification is already waiting
+		}
+	}
+
 	for _, ch := range targets {
 		select {
 		case ch <- evt:
@@ -214,14 +370,45 @@ func (s *Store) emitChange(evt ChangeEvent) {
 
 // CreateDownload inserts a new download row and returns its ID.
 func (s *Store) CreateDownload(ctx context.Context, url, title string, duration int64, thumbnail string, status string, progress float64) (int64, error) {
+	return s.CreateDownloadInLibrary(ctx, url, title, duration, thumbnail, status, progress, DefaultLibrary, "")
+}
+
+// CreateDownloadInLibrary inserts a row bound to a named library. root is the
+// library's directory; empty means the default output directory.
+func (s *Store) CreateDownloadInLibrary(ctx context.Context, url, title string, duration int64, thumbnail string, status string, progress float64, library, root string) (int64, error) {
+	return s.insertDownload(ctx, url, title, duration, thumbnail, status, progress, library, root, JobOptions{})
+}
+
+// JobOptions are per-request job settings saved together with a new row, so
+// the DB worker never picks the row up without them.
+type JobOptions struct {
+	MaxDuration time.Duration // 0 uses the library's or the global limit
+	FormatID    string        // yt-dlp format to download; empty uses the default selection
+	ProbeToken  string        // cached /api/probe result to use instead of probing again
+	Sections    []string      // canonical section specs; empty downloads the whole video
+	// SplitChapters also writes each chapter to its own file.
+	SplitChapters bool
+}
+
+// CreatePendingDownload inserts a pending row titled by its URL until the
+// metadata probe fills it in.
+func (s *Store) CreatePendingDownload(ctx context.Context, url, library, root string, opts JobOptions) (int64, error) {
+	return s.insertDownload(ctx, url, url, 0, "", "pending", 0, library, root, opts)
+}
+
+func (s *Store) insertDownload(ctx context.Context, url, title string, duration int64, thumbnail string, status string, progress float64, library, root string, opts JobOptions) (int64, error) {
 	if url == "" {
 		return 0, ErrEmptyURL
 	}
+	if library == "" {
+		library = DefaultLibrary
+	}
 	// normalize status
 	st := normalizeStatus(status)
 	res, err := s.db.ExecContext(ctx, `
-INSERT INTO downloads (url, title, duration, thumbnail_url, status, progress, artifact_paths)
-VALUES (?, ?, ?, ?, ?, ?, ?)`, url, title, duration, thumbnail, st, progress, "[]")
+INSERT INTO downloads (url, title, duration, thumbnail_url, status, progress, artifact_paths, library, library_root, max_duration_sec, format_id, probe_token, sections, split_chapters)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, url, title, duration, thumbnail, st, progress, "[]", library, nullIfEmpty(root),
+		int64(max(opts.MaxDuration, 0)/time.Second), nullIfEmpty(opts.FormatID), nullIfEmpty(opts.ProbeToken), nullIfEmpty(encodeSections(opts.Sections)), opts.SplitChapters)
 	if err != nil {
 		return 0, err
 	}
@@ -230,10 +417,37 @@ VALUES (?, ?, ?, ?, ?, ?, ?)`, url, title, duration, thumbnail, st, progress, "[
 		return 0, fmt.Errorf("get insert id: %w", err)
 	}
 	logging.LogDBCreate(id, url, title, int(duration), st, progress)
-	s.emitChange(ChangeEvent{Type: ChangeUpsert, ID: id})
+	s.emitChange(ChangeEvent{Type: ChangeUpsert, ID: id, Status: st})
 	return id, nil
 }
 
+// SetMaxDuration stores a per-request time limit for the row's job; 0 clears it.
+func (s *Store) SetMaxDuration(ctx context.Context, id int64, limit time.Duration) error {
+	sec := int64(max(limit, 0) / time.Second)
+	if _, err := s.db.ExecContext(ctx, `UPDATE downloads SET max_duration_sec = ?, updated_at = ? WHERE id = ?`, sec, sqliteTimestampNow(), id); err != nil {
+		return err
+	}
+	logging.LogDBUpdate("set_max_duration", id, map[string]any{"max_duration_sec": sec})
+	return nil
+}
+
+// UpdateLibraryRoot records the directory the row's files are written under.
+func (s *Store) UpdateLibraryRoot(ctx context.Context, id int64, root string) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE downloads SET library_root = ?, updated_at = ? WHERE id = ?`, nullIfEmpty(root), sqliteTimestampNow(), id)
+	if err != nil {
+		return err
+	}
+	logging.LogDBUpdate("update_library_root", id, map[string]any{"library_root": root})
+	return nil
+}
+
+func nullIfEmpty(v string) any {
+	if strings.TrimSpace(v) == "" {
+		return nil
+	}
+	return v
+}
+
 // UpdateArtifacts stores tracked artifact paths for deterministic cleanup.
 func (s *Store) UpdateArtifacts(ctx context.Context, id int64, paths []string) error {
 	cleaned := cleanArtifactPaths(paths)
@@ -266,17 +480,18 @@ func (s *Store) UpdateStatus(ctx context.Context, id int64, status string, errMs
 	st := normalizeStatus(status)
 	var err error
 	now := sqliteTimestampNow()
-	if st == "error" {
+	if st == "error" || st == "paused" || st == "deferred" {
+		// Paused and deferred rows keep an optional machine reason (e.g. disk_low) in error_message.
 		trimmedErr := strings.TrimSpace(errMsg)
 		if trimmedErr == "" {
-			_, err = s.db.ExecContext(ctx, `UPDATE downloads SET status = ?, error_message = NULL, updated_at = ? WHERE id = ?`, st, now, id)
+			_, err = s.db.ExecContext(ctx, `UPDATE downloads SET status = ?, error_message = NULL, error_class = NULL, updated_at = ? WHERE id = ?`, st, now, id)
 		} else {
-			_, err = s.db.ExecContext(ctx, `UPDATE downloads SET status = ?, error_message = ?, updated_at = ? WHERE id = ?`, st, trimmedErr, now, id)
+			_, err = s.db.ExecContext(ctx, `UPDATE downloads SET status = ?, error_message = ?, error_class = NULL, updated_at = ? WHERE id = ?`, st, trimmedErr, now, id)
 		}
 	} else if st == "downloading" {
-		_, err = s.db.ExecContext(ctx, `UPDATE downloads SET status = ?, error_message = NULL, updated_at = ? WHERE id = ? AND status NOT IN ('completed', 'canceled')`, st, now, id)
+		_, err = s.db.ExecContext(ctx, `UPDATE downloads SET status = ?, error_message = NULL, error_class = NULL, updated_at = ? WHERE id = ? AND status NOT IN ('completed', 'canceled')`, st, now, id)
 	} else {
-		_, err = s.db.ExecContext(ctx, `UPDATE downloads SET status = ?, error_message = NULL, updated_at = ? WHERE id = ?`, st, now, id)
+		_, err = s.db.ExecContext(ctx, `UPDATE downloads SET status = ?, error_message = NULL, error_class = NULL, updated_at = ? WHERE id = ?`, st, now, id)
 	}
 	if err != nil {
 		return err
@@ -286,6 +501,18 @@ func (s *Store) UpdateStatus(ctx context.Context, id int64, status string, errMs
 		fields["error_message"] = errMsg
 	}
 	logging.LogDBUpdate("update_status", id, fields)
+	s.emitChange(ChangeEvent{Type: ChangeUpsert, ID: id, Status: st})
+	return nil
+}
+
+// UpdateErrorClass records the class of a failed row's error. Any later status
+// change clears it.
+func (s *Store) UpdateErrorClass(ctx context.Context, id int64, class string) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE downloads SET error_class = ? WHERE id = ? AND status = 'error'`, class, id)
+	if err != nil {
+		return err
+	}
+	logging.LogDBUpdate("update_error_class", id, map[string]any{"error_class": class})
 	s.emitChange(ChangeEvent{Type: ChangeUpsert, ID: id})
 	return nil
 }
@@ -331,7 +558,7 @@ func (s *Store) UpdateMeta(ctx context.Context, id int64, title string, duration
 // TryClaimPending atomically transitions a pending download to downloading.
 // Returns true when claim succeeds, false when the row was not pending.
 func (s *Store) TryClaimPending(ctx context.Context, id int64) (bool, error) {
-	res, err := s.db.ExecContext(ctx, `UPDATE downloads SET status = 'downloading', error_message = NULL, updated_at = ? WHERE id = ? AND status = 'pending'`, sqliteTimestampNow(), id)
+	res, err := s.db.ExecContext(ctx, `UPDATE downloads SET status = 'downloading', error_message = NULL, error_class = NULL, updated_at = ? WHERE id = ? AND status = 'pending'`, sqliteTimestampNow(), id)
 	if err != nil {
 		return false, err
 	}
@@ -348,7 +575,7 @@ func (s *Store) TryClaimPending(ctx context.Context, id int64) (bool, error) {
 // TryCancel transitions a download to canceled unless it is already completed/canceled.
 // Returns true when the transition was applied.
 func (s *Store) TryCancel(ctx context.Context, id int64) (bool, error) {
-	res, err := s.db.ExecContext(ctx, `UPDATE downloads SET status = 'canceled', error_message = NULL, updated_at = ? WHERE id = ? AND status NOT IN ('completed', 'canceled')`, sqliteTimestampNow(), id)
+	res, err := s.db.ExecContext(ctx, `UPDATE downloads SET status = 'canceled', error_message = NULL, error_class = NULL, updated_at = ? WHERE id = ? AND status NOT IN ('completed', 'canceled')`, sqliteTimestampNow(), id)
 	if err != nil {
 		return false, err
 	}
@@ -366,7 +593,7 @@ func (s *Store) TryCancel(ctx context.Context, id int64) (bool, error) {
 // TryCancelNotDownloading transitions a download to canceled only when it is not downloading.
 // Returns true when the transition was applied.
 func (s *Store) TryCancelNotDownloading(ctx context.Context, id int64) (bool, error) {
-	res, err := s.db.ExecContext(ctx, `UPDATE downloads SET status = 'canceled', error_message = NULL, updated_at = ? WHERE id = ? AND status NOT IN ('completed', 'canceled', 'downloading')`, sqliteTimestampNow(), id)
+	res, err := s.db.ExecContext(ctx, `UPDATE downloads SET status = 'canceled', error_message = NULL, error_class = NULL, updated_at = ? WHERE id = ? AND status NOT IN ('completed', 'canceled', 'downloading')`, sqliteTimestampNow(), id)
 	if err != nil {
 		return false, err
 	}
@@ -384,7 +611,7 @@ func (s *Store) TryCancelNotDownloading(ctx context.Context, id int64) (bool, er
 // TryPause transitions a download to paused only when it is not downloading/completed/canceled.
 // Returns true when the transition was applied.
 func (s *Store) TryPause(ctx context.Context, id int64) (bool, error) {
-	res, err := s.db.ExecContext(ctx, `UPDATE downloads SET status = 'paused', error_message = NULL, updated_at = ? WHERE id = ? AND status IN ('pending', 'error')`, sqliteTimestampNow(), id)
+	res, err := s.db.ExecContext(ctx, `UPDATE downloads SET status = 'paused', error_message = NULL, error_class = NULL, updated_at = ? WHERE id = ? AND status IN ('pending', 'error')`, sqliteTimestampNow(), id)
 	if err != nil {
 		return false, err
 	}
@@ -402,7 +629,7 @@ func (s *Store) TryPause(ctx context.Context, id int64) (bool, error) {
 // TryPauseUnlessTerminal transitions a download to paused unless it is in a terminal state.
 // Returns true when the transition was applied.
 func (s *Store) TryPauseUnlessTerminal(ctx context.Context, id int64) (bool, error) {
-	res, err := s.db.ExecContext(ctx, `UPDATE downloads SET status = 'paused', error_message = NULL, updated_at = ? WHERE id = ? AND status NOT IN ('completed', 'canceled')`, sqliteTimestampNow(), id)
+	res, err := s.db.ExecContext(ctx, `UPDATE downloads SET status = 'paused', error_message = NULL, error_class = NULL, updated_at = ? WHERE id = ? AND status NOT IN ('completed', 'canceled')`, sqliteTimestampNow(), id)
 	if err != nil {
 		return false, err
 	}
@@ -423,6 +650,7 @@ func (s *Store) TryMarkResumed(ctx context.Context, id int64) (bool, error) {
 	res, err := s.db.ExecContext(ctx, `UPDATE downloads
 SET status = 'downloading',
     error_message = NULL,
+    error_class = NULL,
     progress = CASE WHEN status IN ('canceled', 'error') THEN 0 ELSE progress END,
     filename = CASE WHEN status IN ('canceled', 'error') THEN NULL ELSE filename END,
     artifact_paths = CASE WHEN status IN ('canceled', 'error') THEN NULL ELSE artifact_paths END,
@@ -448,6 +676,7 @@ func (s *Store) TryMarkPendingFromDownloading(ctx context.Context, id int64) (bo
 	res, err := s.db.ExecContext(ctx, `UPDATE downloads
 SET status = 'pending',
     error_message = NULL,
+    error_class = NULL,
     updated_at = ?
 WHERE id = ? AND status = 'downloading'`, sqliteTimestampNow(), id)
 	if err != nil {
@@ -459,15 +688,66 @@ WHERE id = ? AND status = 'downloading'`, sqliteTimestampNow(), id)
 	}
 	if affected == 1 {
 		logging.LogDBUpdate("try_mark_pending_from_downloading", id, map[string]any{"status": "pending"})
-		s.emitChange(ChangeEvent{Type: ChangeUpsert, ID: id})
+		s.emitChange(ChangeEvent{Type: ChangeUpsert, ID: id, Status: "pending"})
 	}
 	return affected == 1, nil
 }
 
+// downloadColumns is the column list understood by scanDownload.
+// Tags are folded into one column joined with tagSeparator.
+const downloadColumns = "id, url, title, duration, thumbnail_url, status, progress, filename, artifact_paths, error_message, pinned, retention_reason, last_accessed_at, library, library_root, upload_phase, upload_progress, upload_error, remote_sink, remote_key, uploader, transcript_lang, max_duration_sec, format_id, probe_token, sections, split_chapters, error_class, " +
+	"(SELECT group_concat(t.name, char(31)) FROM download_tags dt JOIN tags t ON t.id = dt.tag_id WHERE dt.download_id = downloads.id), created_at, updated_at"
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanDownload reads a row selected with downloadColumns, followed by any extra columns.
+func scanDownload(row rowScanner, extra ...any) (Download, error) {
+	var d Download
+	var filename sql.NullString
+	var artifactPaths sql.NullString
+	var errorMessage sql.NullString
+	var retentionReason sql.NullString
+	var lastAccessed sql.NullTime
+	var libraryRoot sql.NullString
+	var uploadPhase, uploadError, remoteSink, remoteKey sql.NullString
+	var uploader, transcriptLang, formatID, probeToken, sections, errorClass, tags sql.NullString
+	dest := []any{&d.ID, &d.URL, &d.Title, &d.Duration, &d.ThumbnailURL, &d.Status, &d.Progress,
+		&filename, &artifactPaths, &errorMessage, &d.Pinned, &retentionReason, &lastAccessed, &d.Library, &libraryRoot,
+		&uploadPhase, &d.UploadProgress, &uploadError, &remoteSink, &remoteKey, &uploader, &transcriptLang, &d.MaxDurationSec, &formatID, &probeToken, &sections, &d.SplitChapters, &errorClass, &tags, &d.CreatedAt, &d.UpdatedAt}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return Download{}, err
+	}
+	d.Filename = filename.String
+	d.ArtifactPaths = parseArtifactPaths(artifactPaths.String)
+	d.ErrorMessage = errorMessage.String
+	d.ErrorClass = errorClass.String
+	d.RetentionReason = retentionReason.String
+	d.LibraryRoot = libraryRoot.String
+	d.UploadPhase = uploadPhase.String
+	d.UploadError = uploadError.String
+	d.RemoteSink = remoteSink.String
+	d.RemoteKey = remoteKey.String
+	d.Tags = splitTags(tags.String)
+	d.Uploader = uploader.String
+	d.TranscriptLang = transcriptLang.String
+	d.FormatID = formatID.String
+	d.ProbeToken = probeToken.String
+	d.Sections = decodeSections(sections.String)
+	if lastAccessed.Valid {
+		t := lastAccessed.Time
+		d.LastAccessedAt = &t
+	}
+	return d, nil
+}
+
 // ListDownloads returns downloads filtered and sorted.
 type ListFilter struct {
 	Status string // optional: active|history|pending|downloading|paused|completed|error|canceled
-	Sort   string // created_at|updated_at|title|status
+	Tag    string // optional: only rows carrying this tag
+	Query  string // optional: full-text search over title, URL, uploader and description
+	Sort   string // rank|created_at|updated_at|title|status; rank (the default with Query) orders by relevance
 	Order  string // asc|desc
 	Limit  int    // optional
 	Offset int    // optional
@@ -491,23 +771,43 @@ func (s *Store) ListDownloads(ctx context.Context, f ListFilter) ([]Download, er
 	}
 	var args []any
 	sb := strings.Builder{}
-	sb.WriteString("SELECT id, url, title, duration, thumbnail_url, status, progress, filename, artifact_paths, error_message, created_at, updated_at FROM downloads")
+	match := ftsQuery(f.Query)
+	if match != "" {
+		sb.WriteString("WITH hits AS (" + searchHitsQuery + ") SELECT " + downloadColumns + ", hits.snippet FROM downloads JOIN hits ON hits.rowid = downloads.id")
+		args = append(args, match)
+	} else {
+		sb.WriteString("SELECT " + downloadColumns + " FROM downloads")
+	}
+	var where []string
 	switch strings.ToLower(strings.TrimSpace(f.Status)) {
 	case "":
 	case "active":
-		sb.WriteString(" WHERE status IN ('pending', 'downloading', 'paused')")
+		where = append(where, "status IN ('pending', 'downloading', 'paused', 'deferred')")
 	case "history", "terminal":
-		sb.WriteString(" WHERE status IN ('completed', 'error', 'canceled')")
+		where = append(where, "status IN ('completed', 'error', 'canceled')")
 	default:
-		sb.WriteString(" WHERE status = ?")
+		where = append(where, "status = ?")
 		args = append(args, normalizeStatus(f.Status))
 	}
+	if tag := strings.TrimSpace(f.Tag); tag != "" {
+		where = append(where, taggedClause)
+		args = append(args, tag)
+	}
+	if len(where) > 0 {
+		sb.WriteString(" WHERE ")
+		sb.WriteString(strings.Join(where, " AND "))
+	}
 	sb.WriteString(" ORDER BY ")
-	sb.WriteString(sortCol)
-	sb.WriteByte(' ')
-	sb.WriteString(order)
-	sb.WriteString(", id ")
-	sb.WriteString(order)
+	if sortBy := strings.ToLower(f.Sort); match != "" && (sortBy == "" || sortBy == "rank") {
+		// Best match first regardless of order; bm25 ranks are lower for better matches.
+		sb.WriteString("hits.rank, id DESC")
+	} else {
+		sb.WriteString(sortCol)
+		sb.WriteByte(' ')
+		sb.WriteString(order)
+		sb.WriteString(", id ")
+		sb.WriteString(order)
+	}
 	if f.Limit > 0 {
 		sb.WriteString(" LIMIT ?")
 		args = append(args, f.Limit)
@@ -526,16 +826,16 @@ func (s *Store) ListDownloads(ctx context.Context, f ListFilter) ([]Download, er
 	defer rows.Close()
 	out := make([]Download, 0, 64)
 	for rows.Next() {
-		var d Download
-		var filename sql.NullString
-		var artifactPaths sql.NullString
-		var errorMessage sql.NullString
-		if err := rows.Scan(&d.ID, &d.URL, &d.Title, &d.Duration, &d.ThumbnailURL, &d.Status, &d.Progress, &filename, &artifactPaths, &errorMessage, &d.CreatedAt, &d.UpdatedAt); err != nil {
+		var extra []any
+		var snippet sql.NullString
+		if match != "" {
+			extra = append(extra, &snippet)
+		}
+		d, err := scanDownload(rows, extra...)
+		if err != nil {
 			return nil, err
 		}
-		d.Filename = filename.String
-		d.ArtifactPaths = parseArtifactPaths(artifactPaths.String)
-		d.ErrorMessage = errorMessage.String
+		d.Snippet = snippetHTML(snippet.String)
 		out = append(out, d)
 	}
 	return out, rows.Err()
@@ -543,25 +843,13 @@ func (s *Store) ListDownloads(ctx context.Context, f ListFilter) ([]Download, er
 
 // GetDownloadByID returns a single download by ID.
 func (s *Store) GetDownloadByID(ctx context.Context, id int64) (Download, bool, error) {
-	var d Download
-	var filename sql.NullString
-	var artifactPaths sql.NullString
-	var errorMessage sql.NullString
-	err := s.db.QueryRowContext(ctx, `
-SELECT id, url, title, duration, thumbnail_url, status, progress, filename, artifact_paths, error_message, created_at, updated_at
-FROM downloads
-WHERE id = ?`, id).Scan(
-		&d.ID, &d.URL, &d.Title, &d.Duration, &d.ThumbnailURL, &d.Status, &d.Progress, &filename, &artifactPaths, &errorMessage, &d.CreatedAt, &d.UpdatedAt,
-	)
+	d, err := scanDownload(s.db.QueryRowContext(ctx, `SELECT `+downloadColumns+` FROM downloads WHERE id = ?`, id))
 	if errors.Is(err, sql.ErrNoRows) {
 		return Download{}, false, nil
 	}
 	if err != nil {
 		return Download{}, false, err
 	}
-	d.Filename = filename.String
-	d.ArtifactPaths = parseArtifactPaths(artifactPaths.String)
-	d.ErrorMessage = errorMessage.String
 	return d, true, nil
 }
 
@@ -576,6 +864,215 @@ func (s *Store) UpdateFilename(ctx context.Context, id int64, filename string) e
 	return nil
 }
 
+// ListLiveArtifactPaths returns tracked artifact paths and filenames of rows that
+// may still be resumed, so partial files belonging to them are not garbage collected.
+// Filenames of rows in a named library are joined with that library's root.
+func (s *Store) ListLiveArtifactPaths(ctx context.Context) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT filename, artifact_paths, library_root FROM downloads WHERE status IN ('pending', 'downloading', 'paused', 'deferred', 'error')`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var filename, artifactPaths, libraryRoot sql.NullString
+		if err := rows.Scan(&filename, &artifactPaths, &libraryRoot); err != nil {
+			return nil, err
+		}
+		if name := filename.String; strings.TrimSpace(name) != "" {
+			if libraryRoot.String != "" && !filepath.IsAbs(name) {
+				name = filepath.Join(libraryRoot.String, name)
+			}
+			out = append(out, name)
+		}
+		out = append(out, parseArtifactPaths(artifactPaths.String)...)
+	}
+	return out, rows.Err()
+}
+
+// Upload phases recorded while a completed download is copied to a remote sink.
+const (
+	UploadPhaseUploading = "uploading"
+	UploadPhaseUploaded  = "uploaded"
+	UploadPhaseFailed    = "upload_failed"
+)
+
+// UpdateUpload records the upload phase and progress of a completed row.
+func (s *Store) UpdateUpload(ctx context.Context, id int64, phase string, progress float64, errMsg string) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE downloads SET upload_phase = ?, upload_progress = ?, upload_error = ?, updated_at = ? WHERE id = ?`,
+		phase, progress, nullIfEmpty(errMsg), sqliteTimestampNow(), id)
+	if err != nil {
+		return err
+	}
+	s.emitChange(ChangeEvent{Type: ChangeUpsert, ID: id})
+	return nil
+}
+
+// MarkUploaded records where the row's file was uploaded.
+func (s *Store) MarkUploaded(ctx context.Context, id int64, sink, key string) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE downloads SET upload_phase = ?, upload_progress = 100, upload_error = NULL, remote_sink = ?, remote_key = ?, updated_at = ? WHERE id = ?`,
+		UploadPhaseUploaded, sink, key, sqliteTimestampNow(), id)
+	if err != nil {
+		return err
+	}
+	logging.LogDBUpdate("mark_uploaded", id, map[string]any{"remote_sink": sink, "remote_key": key})
+	s.emitChange(ChangeEvent{Type: ChangeUpsert, ID: id})
+	return nil
+}
+
+// ListUploadsByPhase returns IDs of completed rows in the given upload phase, oldest first.
+func (s *Store) ListUploadsByPhase(ctx context.Context, phase string) ([]int64, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id FROM downloads WHERE status = 'completed' AND upload_phase = ? ORDER BY updated_at ASC`, phase)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// ListStaleMetadata returns IDs of rows whose title was never resolved, oldest
+// first. Pending rows are left to the metadata worker.
+func (s *Store) ListStaleMetadata(ctx context.Context, limit int) ([]int64, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	rows, err := s.db.QueryContext(ctx, `SELECT id FROM downloads WHERE status != 'pending' AND (title IS NULL OR title = '' OR title = url) ORDER BY id ASC LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// DefaultLibrary is the library name of rows downloaded into the default output directory.
+const DefaultLibrary = "default"
+
+// Keys for runtime settings persisted in the settings table.
+const (
+	SettingWorkers       = "workers"
+	SettingQueueCapacity = "queue_capacity"
+	SettingMaintenance   = "maintenance"
+)
+
+// GetSetting returns a persisted runtime setting.
+func (s *Store) GetSetting(ctx context.Context, key string) (string, bool, error) {
+	var value string
+	err := s.db.QueryRowContext(ctx, `SELECT value FROM settings WHERE key = ?`, key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+// SetSetting persists a runtime setting, replacing any previous value.
+func (s *Store) SetSetting(ctx context.Context, key, value string) error {
+	_, err := s.db.ExecContext(ctx, `INSERT INTO settings (key, value, updated_at) VALUES (?, ?, ?)
+ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at`, key, value, sqliteTimestampNow())
+	if err != nil {
+		return err
+	}
+	logging.LogDBOperation("set_setting", 0, nil)
+	return nil
+}
+
+// ResumePausedByReason returns rows paused automatically for reason to
+// pending so the DB worker picks them up again. Rows paused by a user carry
+// no reason and are left alone, as are the rows listed in skip, which the
+// caller still manages itself.
+func (s *Store) ResumePausedByReason(ctx context.Context, reason string, skip []int64) (int64, error) {
+	query := `UPDATE downloads SET status = 'pending', error_message = NULL, error_class = NULL, updated_at = ? WHERE status = 'paused' AND error_message = ?`
+	args := []any{sqliteTimestampNow(), reason}
+	if len(skip) > 0 {
+		query += ` AND id NOT IN (?` + strings.Repeat(`, ?`, len(skip)-1) + `)`
+		for _, id := range skip {
+			args = append(args, id)
+		}
+	}
+	res, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if n > 0 {
+		logging.LogDBUpdate("resume_paused_by_reason", 0, map[string]any{"reason": reason, "rows": n})
+		s.emitChange(ChangeEvent{Type: ChangeUpsert, ID: 0})
+	}
+	return n, nil
+}
+
+// SetPinned marks a row as pinned (exempt from retention) or clears the flag.
+// Returns false when the row does not exist.
+func (s *Store) SetPinned(ctx context.Context, id int64, pinned bool) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `UPDATE downloads SET pinned = ?, retention_reason = CASE WHEN ? THEN NULL ELSE retention_reason END, updated_at = ? WHERE id = ?`,
+		pinned, pinned, sqliteTimestampNow(), id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if affected == 1 {
+		logging.LogDBUpdate("set_pinned", id, map[string]any{"pinned": pinned})
+		s.emitChange(ChangeEvent{Type: ChangeUpsert, ID: id})
+	}
+	return affected == 1, nil
+}
+
+// TouchAccessed records that a row's file was served or played, for LRU eviction.
+// updated_at is left alone so list ordering does not shift on playback.
+func (s *Store) TouchAccessed(ctx context.Context, id int64) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE downloads SET last_accessed_at = ? WHERE id = ?`, sqliteTimestampNow(), id)
+	return err
+}
+
+// MarkRetention replaces every recorded retention reason with reasons.
+// Rows missing from reasons have their reason cleared.
+func (s *Store) MarkRetention(ctx context.Context, reasons map[int64]string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `UPDATE downloads SET retention_reason = NULL WHERE retention_reason IS NOT NULL`); err != nil {
+		return err
+	}
+	for id, reason := range reasons {
+		if _, err := tx.ExecContext(ctx, `UPDATE downloads SET retention_reason = ? WHERE id = ?`, reason, id); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	logging.LogDBOperation("mark_retention", 0, nil)
+	s.emitChange(ChangeEvent{Type: ChangeUpsert, ID: 0})
+	return nil
+}
+
 // DeleteDownload removes a download record from the database.
 func (s *Store) DeleteDownload(ctx context.Context, id int64) error {
 	_, err := s.db.ExecContext(ctx, `DELETE FROM downloads WHERE id = ?`, id)
@@ -589,20 +1086,42 @@ func (s *Store) DeleteDownload(ctx context.Context, id int64) error {
 
 // DeleteHistory removes terminal history rows (completed/error/canceled) and returns the deleted count.
 func (s *Store) DeleteHistory(ctx context.Context) (int64, error) {
-	result, err := s.db.ExecContext(ctx, `DELETE FROM downloads WHERE status IN ('completed', 'error', 'canceled')`)
-	if err != nil {
-		return 0, err
+	ids, err := s.DeleteHistoryByTag(ctx, "")
+	return int64(len(ids)), err
+}
+
+// DeleteHistoryByTag is DeleteHistory limited to rows carrying tag; empty
+// means all. It returns the IDs of the deleted rows, so callers can drop
+// state keyed by them.
+func (s *Store) DeleteHistoryByTag(ctx context.Context, tag string) ([]int64, error) {
+	query := `DELETE FROM downloads WHERE status IN ('completed', 'error', 'canceled')`
+	var args []any
+	if tag = strings.TrimSpace(tag); tag != "" {
+		query += " AND " + taggedClause
+		args = append(args, tag)
 	}
-	affected, err := result.RowsAffected()
+	rows, err := s.db.QueryContext(ctx, query+` RETURNING id`, args...)
 	if err != nil {
-		return 0, err
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 	logging.LogDBOperation("delete_history", 0, nil)
-	if affected > 0 {
+	if len(ids) > 0 {
 		// Trigger a list resync for subscribers; bulk deletes may involve many rows.
 		s.emitChange(ChangeEvent{Type: ChangeUpsert, ID: 0})
 	}
-	return affected, nil
+	return ids, nil
 }
 
 // IsURLCompleted checks if a URL already exists with status "completed"
@@ -624,27 +1143,41 @@ func (s *Store) GetLatestDownloadByURL(ctx context.Context, inputURL string) (Do
 		return Download{}, false, ErrEmptyURL
 	}
 
-	var d Download
-	var filename sql.NullString
-	var artifactPaths sql.NullString
-	var errorMessage sql.NullString
-	err := s.db.QueryRowContext(ctx, `
-SELECT id, url, title, duration, thumbnail_url, status, progress, filename, artifact_paths, error_message, created_at, updated_at
+	d, err := scanDownload(s.db.QueryRowContext(ctx, `
+SELECT `+downloadColumns+`
 FROM downloads
 WHERE url = ?
 ORDER BY updated_at DESC, id DESC
-LIMIT 1`, inputURL).Scan(
-		&d.ID, &d.URL, &d.Title, &d.Duration, &d.ThumbnailURL, &d.Status, &d.Progress, &filename, &artifactPaths, &errorMessage, &d.CreatedAt, &d.UpdatedAt,
-	)
+LIMIT 1`, inputURL))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Download{}, false, nil
+	}
+	if err != nil {
+		return Download{}, false, err
+	}
+	return d, true, nil
+}
+
+// GetLatestDownloadBySections is GetLatestDownloadByURL for one selection of
+// sections, so clips of a URL and its whole video are told apart. Sections
+// must be canonical; nil selects the whole video.
+func (s *Store) GetLatestDownloadBySections(ctx context.Context, inputURL string, sections []string) (Download, bool, error) {
+	if strings.TrimSpace(inputURL) == "" {
+		return Download{}, false, ErrEmptyURL
+	}
+
+	d, err := scanDownload(s.db.QueryRowContext(ctx, `
+SELECT `+downloadColumns+`
+FROM downloads
+WHERE url = ? AND COALESCE(sections, '') = ?
+ORDER BY updated_at DESC, id DESC
+LIMIT 1`, inputURL, encodeSections(sections)))
 	if errors.Is(err, sql.ErrNoRows) {
 		return Download{}, false, nil
 	}
 	if err != nil {
 		return Download{}, false, err
 	}
-	d.Filename = filename.String
-	d.ArtifactPaths = parseArtifactPaths(artifactPaths.String)
-	d.ErrorMessage = errorMessage.String
 	return d, true, nil
 }
 
@@ -653,7 +1186,7 @@ func (s *Store) GetPendingDownloads(ctx context.Context, limit int) ([]Download,
 	if limit <= 0 {
 		limit = 10
 	}
-	query := `SELECT id, url, title, duration, thumbnail_url, status, progress, filename, artifact_paths, error_message, created_at, updated_at
+	query := `SELECT ` + downloadColumns + `
 			  FROM downloads 
 			  WHERE status = 'pending' 
 			  ORDER BY created_at ASC 
@@ -667,24 +1200,10 @@ func (s *Store) GetPendingDownloads(ctx context.Context, limit int) ([]Download,
 
 	var downloads []Download
 	for rows.Next() {
-		var d Download
-		var filename sql.NullString
-		var artifactPaths sql.NullString
-		var errorMessage sql.NullString
-		err := rows.Scan(&d.ID, &d.URL, &d.Title, &d.Duration, &d.ThumbnailURL,
-			&d.Status, &d.Progress, &filename, &artifactPaths, &errorMessage, &d.CreatedAt, &d.UpdatedAt)
+		d, err := scanDownload(rows)
 		if err != nil {
 			return nil, err
 		}
-		if filename.Valid {
-			d.Filename = filename.String
-		}
-		if artifactPaths.Valid {
-			d.ArtifactPaths = parseArtifactPaths(artifactPaths.String)
-		}
-		if errorMessage.Valid {
-			d.ErrorMessage = errorMessage.String
-		}
 		downloads = append(downloads, d)
 	}
 	return downloads, rows.Err()
@@ -692,7 +1211,8 @@ func (s *Store) GetPendingDownloads(ctx context.Context, limit int) ([]Download,
 
 // GetIncompleteDownloads returns startup-retriable downloads.
 // Paused and canceled rows are intentionally excluded because they are terminal
-// until explicit user action.
+// until explicit user action, except rows paused automatically for low disk
+// space, which resume on their own.
 func (s *Store) GetIncompleteDownloads(ctx context.Context, limit int) ([]interface {
 	GetID() int64
 	GetURL() string
@@ -705,9 +1225,10 @@ func (s *Store) GetIncompleteDownloads(ctx context.Context, limit int) ([]interf
 	if limit <= 0 {
 		limit = 50 // reasonable default for startup retry
 	}
-	query := `SELECT id, url, title, duration, thumbnail_url, status, progress, filename, artifact_paths, error_message, created_at, updated_at
+	query := `SELECT ` + downloadColumns + `
 			  FROM downloads
-			  WHERE status IN ('pending', 'downloading', 'error')
+			  WHERE status IN ('pending', 'downloading', 'deferred', 'error')
+			     OR (status = 'paused' AND error_message = 'disk_low')
 			  ORDER BY created_at ASC
 			  LIMIT ?`
 
@@ -727,24 +1248,10 @@ func (s *Store) GetIncompleteDownloads(ctx context.Context, limit int) ([]interf
 		GetProgress() float64
 	}
 	for rows.Next() {
-		var d Download
-		var filename sql.NullString
-		var artifactPaths sql.NullString
-		var errorMessage sql.NullString
-		err := rows.Scan(&d.ID, &d.URL, &d.Title, &d.Duration, &d.ThumbnailURL,
-			&d.Status, &d.Progress, &filename, &artifactPaths, &errorMessage, &d.CreatedAt, &d.UpdatedAt)
+		d, err := scanDownload(rows)
 		if err != nil {
 			return nil, err
 		}
-		if filename.Valid {
-			d.Filename = filename.String
-		}
-		if artifactPaths.Valid {
-			d.ArtifactPaths = parseArtifactPaths(artifactPaths.String)
-		}
-		if errorMessage.Valid {
-			d.ErrorMessage = errorMessage.String
-		}
 		downloads = append(downloads, &d)
 	}
 	return downloads, rows.Err()
@@ -761,12 +1268,19 @@ func (s *Store) GetPendingDownloadsForWorker(ctx context.Context, limit int) ([]
 	result := make([]interface{}, len(downloads))
 	for i, d := range downloads {
 		result[i] = map[string]interface{}{
-			"id":            d.ID,
-			"url":           d.URL,
-			"title":         d.Title,
-			"duration":      d.Duration,
-			"thumbnail_url": d.ThumbnailURL,
-			"status":        d.Status,
+			"id":             d.ID,
+			"url":            d.URL,
+			"title":          d.Title,
+			"duration":       d.Duration,
+			"thumbnail_url":  d.ThumbnailURL,
+			"status":         d.Status,
+			"library":        d.Library,
+			"max_duration":   time.Duration(d.MaxDurationSec) * time.Second,
+			"created_at":     d.CreatedAt,
+			"format_id":      d.FormatID,
+			"probe_token":    d.ProbeToken,
+			"sections":       d.Sections,
+			"split_chapters": d.SplitChapters,
 		}
 	}
 	return result, nil
@@ -784,7 +1298,18 @@ func (s *Store) CountDownloadsByStatus(ctx context.Context, status string) (int6
 
 // RetryFailedDownloads resets all failed downloads back to pending status for retry
 func (s *Store) RetryFailedDownloads(ctx context.Context) (int64, error) {
-	result, err := s.db.ExecContext(ctx, `UPDATE downloads SET status = 'pending', progress = 0, error_message = NULL, updated_at = ? WHERE status = 'error'`, sqliteTimestampNow())
+	return s.RetryFailedDownloadsByTag(ctx, "")
+}
+
+// RetryFailedDownloadsByTag is RetryFailedDownloads limited to rows carrying tag; empty means all.
+func (s *Store) RetryFailedDownloadsByTag(ctx context.Context, tag string) (int64, error) {
+	query := `UPDATE downloads SET status = 'pending', progress = 0, error_message = NULL, error_class = NULL, updated_at = ? WHERE status = 'error'`
+	args := []any{sqliteTimestampNow()}
+	if tag = strings.TrimSpace(tag); tag != "" {
+		query += " AND " + taggedClause
+		args = append(args, tag)
+	}
+	result, err := s.db.ExecContext(ctx, query, args...)
 	if err != nil {
 		return 0, err
 	}
@@ -804,7 +1329,7 @@ func normalizeStatus(s string) string {
 	switch s {
 	case "queued":
 		return "pending"
-	case "downloading", "completed", "pending", "paused", "canceled":
+	case "downloading", "completed", "pending", "paused", "canceled", "deferred":
 		return s
 	case "failed", "error":
 		return "error"
@@ -813,6 +1338,29 @@ func normalizeStatus(s string) string {
 	}
 }
 
+// encodeSections stores section specs as a JSON array; no sections encode as "".
+func encodeSections(sections []string) string {
+	if len(sections) == 0 {
+		return ""
+	}
+	payload, err := json.Marshal(sections)
+	if err != nil {
+		return ""
+	}
+	return string(payload)
+}
+
+func decodeSections(input string) []string {
+	if input == "" {
+		return nil
+	}
+	var parsed []string
+	if err := json.Unmarshal([]byte(input), &parsed); err != nil || len(parsed) == 0 {
+		return nil
+	}
+	return parsed
+}
+
 func parseArtifactPaths(input string) []string {
 	trimmed := strings.TrimSpace(input)
 	if trimmed == "" {
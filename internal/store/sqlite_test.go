@@ -87,6 +87,57 @@ func TestCreateDownload_EmptyURL(t *testing.T) {
 	}
 }
 
+func TestCreateDownloadInLibrary_PersistsLibraryAndRoot(t *testing.T) {
+	store := setupTestStore(t)
+	defer store.Close()
+
+	ctx := context.Background()
+	plainID, err := store.CreateDownload(ctx, "https://example.com/plain", "Plain", 0, "", "pending", 0)
+	if err != nil {
+		t.Fatalf("CreateDownload() failed: %v", err)
+	}
+	musicID, err := store.CreateDownloadInLibrary(ctx, "https://example.com/song", "Song", 0, "", "pending", 0, "music", "/srv/music")
+	if err != nil {
+		t.Fatalf("CreateDownloadInLibrary() failed: %v", err)
+	}
+	if err := store.UpdateFilename(ctx, musicID, "Artist/song.opus.part"); err != nil {
+		t.Fatalf("UpdateFilename() failed: %v", err)
+	}
+
+	plain, _, _ := store.GetDownloadByID(ctx, plainID)
+	if plain.Library != DefaultLibrary || plain.LibraryRoot != "" {
+		t.Fatalf("expected default library without root, got %q %q", plain.Library, plain.LibraryRoot)
+	}
+	music, _, _ := store.GetDownloadByID(ctx, musicID)
+	if music.Library != "music" || music.LibraryRoot != "/srv/music" {
+		t.Fatalf("expected music library row, got %q %q", music.Library, music.LibraryRoot)
+	}
+
+	if err := store.UpdateLibraryRoot(ctx, musicID, "/mnt/music"); err != nil {
+		t.Fatalf("UpdateLibraryRoot() failed: %v", err)
+	}
+	live, err := store.ListLiveArtifactPaths(ctx)
+	if err != nil {
+		t.Fatalf("ListLiveArtifactPaths() failed: %v", err)
+	}
+	if len(live) != 1 || live[0] != filepath.Join("/mnt/music", "Artist/song.opus.part") {
+		t.Fatalf("expected filename joined with library root, got %v", live)
+	}
+
+	pending, err := store.GetPendingDownloadsForWorker(ctx, 10)
+	if err != nil {
+		t.Fatalf("GetPendingDownloadsForWorker() failed: %v", err)
+	}
+	libs := map[int64]string{}
+	for _, p := range pending {
+		m := p.(map[string]interface{})
+		libs[m["id"].(int64)] = m["library"].(string)
+	}
+	if libs[plainID] != DefaultLibrary || libs[musicID] != "music" {
+		t.Fatalf("expected library in worker maps, got %v", libs)
+	}
+}
+
 func TestUpdateProgress(t *testing.T) {
 	store := setupTestStore(t)
 	defer store.Close()
@@ -251,6 +302,33 @@ func TestUpdateStatus_PersistErrorAndClear(t *testing.T) {
 	}
 }
 
+func TestUpdateErrorClass(t *testing.T) {
+	store := setupTestStore(t)
+	defer store.Close()
+
+	ctx := context.Background()
+	id, err := store.CreateDownload(ctx, "https://example.com/video", "Test Video", 300, "", "downloading", 0.0)
+	if err != nil {
+		t.Fatalf("CreateDownload() failed: %v", err)
+	}
+	if err := store.UpdateStatus(ctx, id, "error", "timeout: job exceeded 1h0m0s"); err != nil {
+		t.Fatalf("UpdateStatus(error) failed: %v", err)
+	}
+	if err := store.UpdateErrorClass(ctx, id, "timeout"); err != nil {
+		t.Fatalf("UpdateErrorClass() failed: %v", err)
+	}
+	if d, _, _ := store.GetDownloadByID(ctx, id); d.ErrorClass != "timeout" {
+		t.Fatalf("expected error_class=timeout, got %q", d.ErrorClass)
+	}
+
+	if _, err := store.RetryFailedDownloads(ctx); err != nil {
+		t.Fatalf("RetryFailedDownloads() failed: %v", err)
+	}
+	if d, _, _ := store.GetDownloadByID(ctx, id); d.Status != "pending" || d.ErrorClass != "" {
+		t.Fatalf("expected a retried row to drop its error class, got status=%s class=%q", d.Status, d.ErrorClass)
+	}
+}
+
 func TestGetDownloadByID(t *testing.T) {
 	store := setupTestStore(t)
 	defer store.Close()
@@ -981,6 +1059,121 @@ func TestGetIncompleteDownloads_ExcludesPausedAndCanceled(t *testing.T) {
 	}
 }
 
+func TestGetIncompleteDownloads_IncludesDiskLowPaused(t *testing.T) {
+	store := setupTestStore(t)
+	defer store.Close()
+
+	ctx := context.Background()
+	userPausedID, _ := store.CreateDownload(ctx, "https://example.com/user-paused", "User", 0, "", "paused", 10)
+	diskPausedID, _ := store.CreateDownload(ctx, "https://example.com/disk-paused", "Disk", 0, "", "downloading", 20)
+	if err := store.UpdateStatus(ctx, diskPausedID, "paused", "disk_low"); err != nil {
+		t.Fatalf("UpdateStatus(paused, disk_low) failed: %v", err)
+	}
+
+	row, _, err := store.GetDownloadByID(ctx, diskPausedID)
+	if err != nil {
+		t.Fatalf("GetDownloadByID() failed: %v", err)
+	}
+	if row.Status != "paused" || row.ErrorMessage != "disk_low" {
+		t.Fatalf("expected paused row with disk_low reason, got status=%q error=%q", row.Status, row.ErrorMessage)
+	}
+
+	rows, err := store.GetIncompleteDownloads(ctx, 100)
+	if err != nil {
+		t.Fatalf("GetIncompleteDownloads() failed: %v", err)
+	}
+	if len(rows) != 1 || rows[0].GetID() != diskPausedID {
+		t.Fatalf("expected only disk-paused row %d to be retryable (user paused %d), got %d rows", diskPausedID, userPausedID, len(rows))
+	}
+}
+
+func TestResumePausedByReason_LeavesUserPausedRows(t *testing.T) {
+	store := setupTestStore(t)
+	defer store.Close()
+
+	ctx := context.Background()
+	userPausedID, _ := store.CreateDownload(ctx, "https://example.com/user-paused", "User", 0, "", "paused", 10)
+	heldID, _ := store.CreateDownload(ctx, "https://example.com/held", "Held", 0, "", "downloading", 20)
+	if err := store.UpdateStatus(ctx, heldID, "paused", "maintenance"); err != nil {
+		t.Fatalf("UpdateStatus(paused, maintenance) failed: %v", err)
+	}
+
+	managedID, _ := store.CreateDownload(ctx, "https://example.com/managed", "Managed", 0, "", "downloading", 30)
+	if err := store.UpdateStatus(ctx, managedID, "paused", "maintenance"); err != nil {
+		t.Fatalf("UpdateStatus(paused, maintenance) failed: %v", err)
+	}
+
+	n, err := store.ResumePausedByReason(ctx, "maintenance", []int64{managedID})
+	if err != nil || n != 1 {
+		t.Fatalf("ResumePausedByReason() = %d, %v; want 1 row", n, err)
+	}
+	held, _, _ := store.GetDownloadByID(ctx, heldID)
+	if held.Status != "pending" || held.ErrorMessage != "" {
+		t.Fatalf("expected held row back to pending, got status=%q error=%q", held.Status, held.ErrorMessage)
+	}
+	user, _, _ := store.GetDownloadByID(ctx, userPausedID)
+	if user.Status != "paused" {
+		t.Fatalf("expected user-paused row to stay paused, got %q", user.Status)
+	}
+	managed, _, _ := store.GetDownloadByID(ctx, managedID)
+	if managed.Status != "paused" {
+		t.Fatalf("expected skipped row to stay paused, got %q", managed.Status)
+	}
+}
+
+func TestMarkRetention_ReplacesReasonsAndPinClears(t *testing.T) {
+	store := setupTestStore(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	a, _ := store.CreateDownload(ctx, "https://example.com/a", "A", 0, "", "completed", 100)
+	b, _ := store.CreateDownload(ctx, "https://example.com/b", "B", 0, "", "completed", 100)
+
+	if err := store.MarkRetention(ctx, map[int64]string{a: "max_age", b: "quota"}); err != nil {
+		t.Fatalf("MarkRetention() failed: %v", err)
+	}
+	if err := store.MarkRetention(ctx, map[int64]string{b: "quota"}); err != nil {
+		t.Fatalf("MarkRetention() failed: %v", err)
+	}
+	rowA, _, _ := store.GetDownloadByID(ctx, a)
+	rowB, _, _ := store.GetDownloadByID(ctx, b)
+	if rowA.RetentionReason != "" || rowB.RetentionReason != "quota" {
+		t.Fatalf("expected stale reason cleared, got a=%q b=%q", rowA.RetentionReason, rowB.RetentionReason)
+	}
+
+	ok, err := store.SetPinned(ctx, b, true)
+	if err != nil || !ok {
+		t.Fatalf("SetPinned() = %v, %v", ok, err)
+	}
+	rowB, _, _ = store.GetDownloadByID(ctx, b)
+	if !rowB.Pinned || rowB.RetentionReason != "" {
+		t.Fatalf("expected pinned row with cleared reason, got %+v", rowB)
+	}
+	if ok, _ := store.SetPinned(ctx, 9999, true); ok {
+		t.Fatalf("expected SetPinned on missing row to report false")
+	}
+}
+
+func TestSettings_RoundTrip(t *testing.T) {
+	store := setupTestStore(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	if _, found, err := store.GetSetting(ctx, SettingWorkers); err != nil || found {
+		t.Fatalf("expected missing setting, found=%v err=%v", found, err)
+	}
+	if err := store.SetSetting(ctx, SettingWorkers, "4"); err != nil {
+		t.Fatalf("SetSetting() failed: %v", err)
+	}
+	if err := store.SetSetting(ctx, SettingWorkers, "8"); err != nil {
+		t.Fatalf("SetSetting() overwrite failed: %v", err)
+	}
+	value, found, err := store.GetSetting(ctx, SettingWorkers)
+	if err != nil || !found || value != "8" {
+		t.Fatalf("GetSetting() = %q, %v, %v; want 8", value, found, err)
+	}
+}
+
 func TestDeleteHistory_RemovesOnlyTerminalStatuses(t *testing.T) {
 	store := setupTestStore(t)
 	defer store.Close()
@@ -1126,6 +1319,68 @@ func TestSubscribeChanges_ReceivesUpsertAndDelete(t *testing.T) {
 	}
 }
 
+func TestSubscribePending_NotifiesOnlyWhenRowsMayBePending(t *testing.T) {
+	store := setupTestStore(t)
+	defer store.Close()
+	ctx := context.Background()
+	pending, unsubscribe := store.SubscribePending()
+	defer unsubscribe()
+
+	notified := func() bool {
+		select {
+		case <-pending:
+			return true
+		default:
+			return false
+		}
+	}
+
+	id, err := store.CreateDownload(ctx, "https://example.com/watch?v=1", "Video", 0, "", "pending", 0)
+	if err != nil {
+		t.Fatalf("CreateDownload() failed: %v", err)
+	}
+	if !notified() {
+		t.Fatalf("expected a notification for a new pending row")
+	}
+	if _, err := store.CreateDownload(ctx, "https://example.com/watch?v=2", "Video", 0, "", "pending", 0); err != nil {
+		t.Fatalf("CreateDownload() failed: %v", err)
+	}
+	if _, err := store.CreateDownload(ctx, "https://example.com/watch?v=3", "Video", 0, "", "pending", 0); err != nil {
+		t.Fatalf("CreateDownload() failed: %v", err)
+	}
+	if !notified() || notified() {
+		t.Fatalf("expected notifications for two new rows to coalesce into one")
+	}
+
+	if _, err := store.TryClaimPending(ctx, id); err != nil {
+		t.Fatalf("TryClaimPending() failed: %v", err)
+	}
+	if err := store.UpdateProgress(ctx, id, 50); err != nil {
+		t.Fatalf("UpdateProgress() failed: %v", err)
+	}
+	if err := store.UpdateStatus(ctx, id, "error", "boom"); err != nil {
+		t.Fatalf("UpdateStatus() failed: %v", err)
+	}
+	if notified() {
+		t.Fatalf("expected no notification for claim, progress or failure")
+	}
+
+	if _, err := store.RetryFailedDownloads(ctx); err != nil {
+		t.Fatalf("RetryFailedDownloads() failed: %v", err)
+	}
+	if !notified() {
+		t.Fatalf("expected a notification after failed rows went back to pending")
+	}
+
+	unsubscribe()
+	if _, err := store.CreateDownload(ctx, "https://example.com/watch?v=4", "Video", 0, "", "pending", 0); err != nil {
+		t.Fatalf("CreateDownload() failed: %v", err)
+	}
+	if notified() {
+		t.Fatalf("expected no notification after unsubscribe")
+	}
+}
+
 func TestSubscribeChanges_UnsubscribeDuringEmitDoesNotPanic(t *testing.T) {
 	store := setupTestStore(t)
 	defer store.Close()
@@ -1160,3 +1415,37 @@ func setupTestStore(t *testing.T) *Store {
 
 	return store
 }
+
+func TestCreatePendingDownload_PersistsJobOptions(t *testing.T) {
+	store := setupTestStore(t)
+	defer store.Close()
+
+	ctx := context.Background()
+	id, err := store.CreatePendingDownload(ctx, "https://example.com/v", "music", "/srv/music",
+		JobOptions{MaxDuration: 90 * time.Second, FormatID: "137+140", ProbeToken: "tok", Sections: []string{"12:00-18:00", "chapter:Intro"}})
+	if err != nil {
+		t.Fatalf("CreatePendingDownload() failed: %v", err)
+	}
+	d, _, err := store.GetDownloadByID(ctx, id)
+	if err != nil {
+		t.Fatalf("GetDownloadByID() failed: %v", err)
+	}
+	if d.Status != "pending" || d.Library != "music" || d.FormatID != "137+140" || d.ProbeToken != "tok" || len(d.Sections) != 2 {
+		t.Fatalf("unexpected row %+v", d)
+	}
+	if _, found, _ := store.GetLatestDownloadBySections(ctx, "https://example.com/v", nil); found {
+		t.Fatalf("expected a clip not to match the whole video")
+	}
+	if clip, found, _ := store.GetLatestDownloadBySections(ctx, "https://example.com/v", d.Sections); !found || clip.ID != id {
+		t.Fatalf("expected the clip to match its own sections, got %v %d", found, clip.ID)
+	}
+
+	pending, err := store.GetPendingDownloadsForWorker(ctx, 10)
+	if err != nil || len(pending) != 1 {
+		t.Fatalf("GetPendingDownloadsForWorker() = %v, %v", pending, err)
+	}
+	m := pending[0].(map[string]interface{})
+	if secs, _ := m["sections"].([]string); m["max_duration"] != 90*time.Second || m["format_id"] != "137+140" || m["probe_token"] != "tok" || len(secs) != 2 {
+		t.Fatalf("expected job options in worker map, got %v", m)
+	}
+}
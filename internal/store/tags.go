@@ -0,0 +1,184 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"videofetch/internal/logging"
+)
+
+// MaxTagLength is the longest tag name accepted, in characters.
+const MaxTagLength = 64
+
+// tagSeparator joins tag names in the tags column of downloadColumns (char(31)).
+const tagSeparator = "\x1f"
+
+// taggedClause matches rows carrying the tag bound to the next placeholder.
+// Tag names compare case-insensitively.
+const taggedClause = "id IN (SELECT dt.download_id FROM download_tags dt JOIN tags t ON t.id = dt.tag_id WHERE t.name = ?)"
+
+// TagCount is a tag with the number of downloads carrying it.
+type TagCount struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// NormalizeTags trims tags, collapses inner whitespace, drops empty entries
+// and case-insensitive duplicates, and rejects names that are too long or
+// contain commas or control characters.
+func NormalizeTags(tags []string) ([]string, error) {
+	out := make([]string, 0, len(tags))
+	seen := make(map[string]struct{}, len(tags))
+	for _, raw := range tags {
+		name := strings.Join(strings.Fields(raw), " ")
+		if name == "" {
+			continue
+		}
+		if utf8.RuneCountInString(name) > MaxTagLength || strings.ContainsRune(name, ',') ||
+			strings.IndexFunc(name, unicode.IsControl) >= 0 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidTag, name)
+		}
+		key := strings.ToLower(name)
+		if _, dup := seen[key]; dup {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, name)
+	}
+	return out, nil
+}
+
+func splitTags(joined string) []string {
+	if joined == "" {
+		return nil
+	}
+	tags := strings.Split(joined, tagSeparator)
+	sort.Slice(tags, func(i, j int) bool { return strings.ToLower(tags[i]) < strings.ToLower(tags[j]) })
+	return tags
+}
+
+// AddTags attaches tags to each download, creating tags that do not exist yet.
+// IDs without a download row are skipped.
+func (s *Store) AddTags(ctx context.Context, ids []int64, tags []string) error {
+	tags, err := NormalizeTags(tags)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 || len(tags) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, name := range tags {
+		if _, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO tags (name) VALUES (?)`, name); err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if _, err := tx.ExecContext(ctx, `
+INSERT OR IGNORE INTO download_tags (download_id, tag_id)
+SELECT d.id, t.id FROM downloads d, tags t WHERE d.id = ? AND t.name = ?`, id, name); err != nil {
+				return err
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		logging.LogDBUpdate("add_tags", id, map[string]any{"tags": tags})
+		s.emitChange(ChangeEvent{Type: ChangeUpsert, ID: id})
+	}
+	return nil
+}
+
+// RemoveTags detaches tags from each download. Tags left without downloads are dropped.
+func (s *Store) RemoveTags(ctx context.Context, ids []int64, tags []string) error {
+	tags, err := NormalizeTags(tags)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 || len(tags) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, name := range tags {
+		for _, id := range ids {
+			if _, err := tx.ExecContext(ctx, `
+DELETE FROM download_tags
+WHERE download_id = ? AND tag_id = (SELECT id FROM tags WHERE name = ?)`, id, name); err != nil {
+				return err
+			}
+		}
+	}
+	if err := pruneTags(ctx, tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		logging.LogDBUpdate("remove_tags", id, map[string]any{"tags": tags})
+		s.emitChange(ChangeEvent{Type: ChangeUpsert, ID: id})
+	}
+	return nil
+}
+
+// DeleteTag removes a tag from every download. It reports whether the tag existed.
+func (s *Store) DeleteTag(ctx context.Context, name string) (bool, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM tags WHERE name = ?`, strings.TrimSpace(name))
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if affected > 0 {
+		logging.LogDBOperation("delete_tag", 0, nil)
+		// Links cascade away on many rows; ask subscribers to resync.
+		s.emitChange(ChangeEvent{Type: ChangeUpsert, ID: 0})
+	}
+	return affected > 0, nil
+}
+
+// ListTags returns every tag in use with its download count, sorted by name.
+func (s *Store) ListTags(ctx context.Context) ([]TagCount, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT t.name, COUNT(*)
+FROM tags t JOIN download_tags dt ON dt.tag_id = t.id
+GROUP BY t.id
+ORDER BY t.name COLLATE NOCASE`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make([]TagCount, 0, 16)
+	for rows.Next() {
+		var tc TagCount
+		if err := rows.Scan(&tc.Name, &tc.Count); err != nil {
+			return nil, err
+		}
+		out = append(out, tc)
+	}
+	return out, rows.Err()
+}
+
+// pruneTags drops tags no download carries anymore.
+func pruneTags(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `DELETE FROM tags WHERE id NOT IN (SELECT tag_id FROM download_tags)`)
+	return err
+}
@@ -0,0 +1,100 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	got, err := NormalizeTags([]string{"  Course   X ", "", "for Mom", "course x", "reference"})
+	if err != nil {
+		t.Fatalf("normalize: %v", err)
+	}
+	if strings.Join(got, "|") != "Course X|for Mom|reference" {
+		t.Fatalf("unexpected tags %q", got)
+	}
+	for _, bad := range []string{"a,b", "bell\aname", strings.Repeat("x", MaxTagLength+1)} {
+		if _, err := NormalizeTags([]string{bad}); !errors.Is(err, ErrInvalidTag) {
+			t.Errorf("expected ErrInvalidTag for %q, got %v", bad, err)
+		}
+	}
+}
+
+func TestTags_AssignFilterAndBulkOperations(t *testing.T) {
+	st := setupTestStore(t)
+	defer st.Close()
+	ctx := context.Background()
+
+	mk := func(url, status string) int64 {
+		id, err := st.CreateDownload(ctx, url, url, 0, "", status, 0)
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		return id
+	}
+	a := mk("https://example.com/a", "error")
+	b := mk("https://example.com/b", "error")
+	c := mk("https://example.com/c", "completed")
+
+	if err := st.AddTags(ctx, []int64{a, c, 999}, []string{"Course X", "reference"}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if err := st.AddTags(ctx, []int64{b}, []string{"for Mom"}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if err := st.AddTags(ctx, []int64{a}, []string{"course x"}); err != nil {
+		t.Fatalf("re-add with other case: %v", err)
+	}
+
+	row, _, _ := st.GetDownloadByID(ctx, a)
+	if strings.Join(row.Tags, "|") != "Course X|reference" {
+		t.Fatalf("expected row tags, got %q", row.Tags)
+	}
+	rows, err := st.ListDownloads(ctx, ListFilter{Tag: "course x", Sort: "created_at", Order: "asc"})
+	if err != nil || len(rows) != 2 || rows[0].ID != a || rows[1].ID != c {
+		t.Fatalf("expected tag filter to match a and c, got %+v (%v)", rows, err)
+	}
+	rows, _ = st.ListDownloads(ctx, ListFilter{Tag: "reference", Status: "error"})
+	if len(rows) != 1 || rows[0].ID != a {
+		t.Fatalf("expected tag and status filters combined, got %+v", rows)
+	}
+
+	tags, err := st.ListTags(ctx)
+	if err != nil || len(tags) != 3 || tags[0] != (TagCount{Name: "Course X", Count: 2}) || tags[1].Name != "for Mom" {
+		t.Fatalf("unexpected tag list %+v (%v)", tags, err)
+	}
+
+	// Bulk operations scoped by tag leave other rows alone.
+	if n, err := st.RetryFailedDownloadsByTag(ctx, "Course X"); err != nil || n != 1 {
+		t.Fatalf("expected one tagged failed row retried, got %d (%v)", n, err)
+	}
+	if row, _, _ := st.GetDownloadByID(ctx, b); row.Status != "error" {
+		t.Fatalf("untagged row must stay failed, got %s", row.Status)
+	}
+	if ids, err := st.DeleteHistoryByTag(ctx, "reference"); err != nil || len(ids) != 1 {
+		t.Fatalf("expected one tagged history row deleted, got %v (%v)", ids, err)
+	}
+	tags, _ = st.ListTags(ctx)
+	if len(tags) != 3 || tags[0].Count != 1 {
+		t.Fatalf("expected links of deleted row cascaded away, got %+v", tags)
+	}
+
+	if err := st.RemoveTags(ctx, []int64{b}, []string{"FOR MOM"}); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if row, _, _ := st.GetDownloadByID(ctx, b); len(row.Tags) != 0 {
+		t.Fatalf("expected tag removed, got %q", row.Tags)
+	}
+	if found, err := st.DeleteTag(ctx, "reference"); err != nil || !found {
+		t.Fatalf("delete tag: %v %v", found, err)
+	}
+	tags, _ = st.ListTags(ctx)
+	if len(tags) != 1 || tags[0].Name != "Course X" {
+		t.Fatalf("expected only Course X left, got %+v", tags)
+	}
+	if err := st.AddTags(ctx, []int64{a}, []string{"bad,tag"}); !errors.Is(err, ErrInvalidTag) {
+		t.Fatalf("expected invalid tag rejected, got %v", err)
+	}
+}
@@ -0,0 +1,178 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+
+	"videofetch/internal/logging"
+)
+
+// transcriptDDL stores subtitle cues per download with an FTS5 index over their text.
+const transcriptDDL = `
+CREATE TABLE IF NOT EXISTS transcript_cues (
+    id INTEGER PRIMARY KEY,
+    download_id INTEGER NOT NULL REFERENCES downloads(id) ON DELETE CASCADE,
+    start_ms INTEGER NOT NULL,
+    end_ms INTEGER NOT NULL,
+    text TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_transcript_cues_download ON transcript_cues(download_id, start_ms);
+CREATE VIRTUAL TABLE IF NOT EXISTS transcript_fts USING fts5(
+    text,
+    content = 'transcript_cues', content_rowid = 'id',
+    tokenize = 'unicode61 remove_diacritics 2'
+);
+CREATE TRIGGER IF NOT EXISTS transcript_fts_ai AFTER INSERT ON transcript_cues BEGIN
+    INSERT INTO transcript_fts (rowid, text) VALUES (new.id, new.text);
+END;
+CREATE TRIGGER IF NOT EXISTS transcript_fts_ad AFTER DELETE ON transcript_cues BEGIN
+    INSERT INTO transcript_fts (transcript_fts, rowid, text) VALUES ('delete', old.id, old.text);
+END;
+`
+
+// Limits for SearchTranscripts: cues scanned per query and cues returned per download.
+const (
+	maxTranscriptCueScan    = 1000
+	MaxTranscriptCuesPerHit = 20
+)
+
+// TranscriptCue is one timed line of a subtitle track.
+type TranscriptCue struct {
+	StartMS int64  `json:"start_ms"`
+	EndMS   int64  `json:"end_ms"`
+	Text    string `json:"text"`
+}
+
+// CueMatch is a cue matched by a transcript search.
+type CueMatch struct {
+	StartMS int64 `json:"start_ms"`
+	EndMS   int64 `json:"end_ms"`
+	// Snippet is HTML-escaped cue text with matched words wrapped in <mark>.
+	Snippet string `json:"snippet"`
+}
+
+// TranscriptHit is a download whose transcript matched, with its matching cues in time order.
+type TranscriptHit struct {
+	Download Download   `json:"download"`
+	Cues     []CueMatch `json:"cues"`
+}
+
+// ReplaceTranscript stores the cues of a download's transcript, replacing any earlier one.
+func (s *Store) ReplaceTranscript(ctx context.Context, id int64, lang string, cues []TranscriptCue) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM transcript_cues WHERE download_id = ?`, id); err != nil {
+		return err
+	}
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO transcript_cues (download_id, start_ms, end_ms, text) VALUES (?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, c := range cues {
+		if _, err := stmt.ExecContext(ctx, id, c.StartMS, c.EndMS, c.Text); err != nil {
+			return err
+		}
+	}
+	result, err := tx.ExecContext(ctx, `UPDATE downloads SET transcript_lang = ?, updated_at = ? WHERE id = ?`, nullIfEmpty(lang), sqliteTimestampNow(), id)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return sql.ErrNoRows
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	logging.LogDBUpdate("replace_transcript", id, map[string]any{"lang": lang, "cues": len(cues)})
+	s.emitChange(ChangeEvent{Type: ChangeUpsert, ID: id})
+	return nil
+}
+
+// TranscriptCues returns the stored cues of a download in time order.
+func (s *Store) TranscriptCues(ctx context.Context, id int64) ([]TranscriptCue, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT start_ms, end_ms, text FROM transcript_cues WHERE download_id = ? ORDER BY start_ms, id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make([]TranscriptCue, 0, 256)
+	for rows.Next() {
+		var c TranscriptCue
+		if err := rows.Scan(&c.StartMS, &c.EndMS, &c.Text); err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// SearchTranscripts finds downloads whose transcripts match q. Downloads are
+// ordered by their best matching cue; each lists up to MaxTranscriptCuesPerHit
+// cues in time order. A query without words returns no hits.
+func (s *Store) SearchTranscripts(ctx context.Context, q string, limit int) ([]TranscriptHit, error) {
+	match := ftsQuery(q)
+	if match == "" {
+		return nil, nil
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	rows, err := s.db.QueryContext(ctx, `
+SELECT c.download_id, c.start_ms, c.end_ms, snippet(transcript_fts, 0, char(2), char(3), '…', 16)
+FROM transcript_fts JOIN transcript_cues c ON c.id = transcript_fts.rowid
+WHERE transcript_fts MATCH ?
+ORDER BY rank
+LIMIT ?`, match, maxTranscriptCueScan)
+	if err != nil {
+		return nil, err
+	}
+	order := make([]int64, 0, limit)
+	cues := make(map[int64][]CueMatch, limit)
+	for rows.Next() {
+		var id int64
+		var m CueMatch
+		var raw string
+		if err := rows.Scan(&id, &m.StartMS, &m.EndMS, &raw); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if _, seen := cues[id]; !seen {
+			if len(order) == limit {
+				continue
+			}
+			order = append(order, id)
+		}
+		if len(cues[id]) < MaxTranscriptCuesPerHit {
+			m.Snippet = snippetHTML(raw)
+			cues[id] = append(cues[id], m)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return nil, err
+	}
+	rows.Close()
+
+	hits := make([]TranscriptHit, 0, len(order))
+	for _, id := range order {
+		d, found, err := s.GetDownloadByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+		matched := cues[id]
+		sort.Slice(matched, func(i, j int) bool { return matched[i].StartMS < matched[j].StartMS })
+		hits = append(hits, TranscriptHit{Download: d, Cues: matched})
+	}
+	return hits, nil
+}
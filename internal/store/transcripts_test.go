@@ -0,0 +1,60 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func TestTranscripts_ReplaceSearchAndCascade(t *testing.T) {
+	st := setupTestStore(t)
+	defer st.Close()
+	ctx := context.Background()
+
+	a, _ := st.CreateDownload(ctx, "https://example.com/a", "Streams", 0, "", "completed", 100)
+	b, _ := st.CreateDownload(ctx, "https://example.com/b", "Queues", 0, "", "completed", 100)
+	if err := st.ReplaceTranscript(ctx, a, "en", []TranscriptCue{
+		{StartMS: 90000, EndMS: 92000, Text: "and backpressure again"},
+		{StartMS: 1000, EndMS: 2000, Text: "backpressure backpressure everywhere"},
+		{StartMS: 5000, EndMS: 6000, Text: "unrelated"},
+	}); err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+	if err := st.ReplaceTranscript(ctx, b, "en", []TranscriptCue{{StartMS: 0, EndMS: 1000, Text: "a note on back pressure"}}); err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+	if err := st.ReplaceTranscript(ctx, 999, "en", nil); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected ErrNoRows for a missing download, got %v", err)
+	}
+
+	hits, err := st.SearchTranscripts(ctx, "backpressure", 10)
+	if err != nil || len(hits) != 1 || hits[0].Download.ID != a || hits[0].Download.TranscriptLang != "en" {
+		t.Fatalf("unexpected hits %+v (%v)", hits, err)
+	}
+	if cues := hits[0].Cues; len(cues) != 2 || cues[0].StartMS != 1000 || cues[1].StartMS != 90000 ||
+		cues[1].Snippet != "and <mark>backpressure</mark> again" {
+		t.Fatalf("expected matching cues in time order, got %+v", cues)
+	}
+	if hits, _ := st.SearchTranscripts(ctx, "pressure", 10); len(hits) != 1 || hits[0].Download.ID != b {
+		t.Fatalf("expected word match on the other row, got %+v", hits)
+	}
+
+	// Replacing drops the old cues from the index.
+	if err := st.ReplaceTranscript(ctx, a, "de", []TranscriptCue{{StartMS: 0, EndMS: 1, Text: "Gegendruck"}}); err != nil {
+		t.Fatalf("replace again: %v", err)
+	}
+	if hits, _ := st.SearchTranscripts(ctx, "backpressure", 10); len(hits) != 0 {
+		t.Fatalf("expected old cues gone, got %+v", hits)
+	}
+	if err := st.DeleteDownload(ctx, b); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if hits, _ := st.SearchTranscripts(ctx, "pressure", 10); len(hits) != 0 {
+		t.Fatalf("expected cues of deleted download gone, got %+v", hits)
+	}
+	var n int
+	if err := st.db.QueryRow(`SELECT COUNT(*) FROM transcript_fts WHERE transcript_fts MATCH 'pressure'`).Scan(&n); err != nil || n != 0 {
+		t.Fatalf("expected index entries removed on cascade, got %d (%v)", n, err)
+	}
+}
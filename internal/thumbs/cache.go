@@ -0,0 +1,498 @@
+// Package thumbs keeps local copies of download thumbnails so the dashboard
+// never hotlinks source sites.
+package thumbs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // register decoders for source thumbnails
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"videofetch/internal/logging"
+)
+
+// ErrNotCached reports that no thumbnail is stored for a download.
+var ErrNotCached = errors.New("thumbnail not cached")
+
+// Size names a stored variant.
+type Size string
+
+const (
+	SizeSmall    Size = "small"
+	SizeMedium   Size = "medium"
+	SizeOriginal Size = "original"
+)
+
+// variantWidths are the resized variants generated next to the original.
+var variantWidths = map[Size]int{
+	SizeSmall:  160,
+	SizeMedium: 480,
+}
+
+// ParseSize maps a query value to a Size; empty means original.
+func ParseSize(s string) (Size, bool) {
+	switch Size(strings.ToLower(strings.TrimSpace(s))) {
+	case "", SizeOriginal:
+		return SizeOriginal, true
+	case SizeSmall:
+		return SizeSmall, true
+	case SizeMedium:
+		return SizeMedium, true
+	}
+	return "", false
+}
+
+const (
+	maxThumbnailBytes = 10 << 20
+	// maxDecodePixels bounds images decoded in memory; a small file can
+	// declare dimensions that would take gigabytes once decoded.
+	maxDecodePixels = 4096 * 4096
+	fetchTimeout    = 30 * time.Second
+	ffmpegTimeout   = time.Minute
+	// failureBackoff stops the dashboard from refetching broken thumbnails on every poll.
+	failureBackoff = 10 * time.Minute
+	// warmQueueSize bounds thumbnails waiting for the background worker;
+	// requests beyond it are dropped and made again on a later view.
+	warmQueueSize = 256
+)
+
+// Options configures a Cache.
+type Options struct {
+	// Client fetches remote thumbnails; nil uses a client with a short timeout.
+	Client *http.Client
+	// FFmpeg is the ffmpeg binary; empty looks it up on PATH. Without it,
+	// frames are not extracted and formats the standard library cannot
+	// decode (such as WebP) are served unresized.
+	FFmpeg string
+}
+
+// Cache stores one directory per download ID holding the original image and
+// its resized variants.
+type Cache struct {
+	dir    string
+	client *http.Client
+	ffmpeg string
+
+	mu     sync.Mutex
+	locks  map[int64]*sync.Mutex
+	failed map[int64]time.Time
+	now    func() time.Time
+
+	// Background warming requested through Enqueue.
+	queue   chan warmRequest
+	pending map[int64]struct{}
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+type warmRequest struct {
+	id        int64
+	remote    string
+	videoPath string
+}
+
+// New creates the cache directory and returns a Cache rooted at dir.
+func New(dir string, opts Options) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create thumbnail cache: %w", err)
+	}
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{Timeout: fetchTimeout}
+	}
+	ffmpeg := opts.FFmpeg
+	if ffmpeg == "" {
+		if p, err := exec.LookPath("ffmpeg"); err == nil {
+			ffmpeg = p
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Cache{
+		dir:     dir,
+		client:  client,
+		ffmpeg:  ffmpeg,
+		locks:   make(map[int64]*sync.Mutex),
+		failed:  make(map[int64]time.Time),
+		now:     time.Now,
+		queue:   make(chan warmRequest, warmQueueSize),
+		pending: make(map[int64]struct{}),
+		ctx:     ctx,
+		cancel:  cancel,
+	}, nil
+}
+
+// Start launches the worker that serves Enqueue.
+func (c *Cache) Start() {
+	c.wg.Add(1)
+	go c.worker()
+}
+
+// Stop cancels an in-flight warm and waits for the worker to exit.
+func (c *Cache) Stop() {
+	c.cancel()
+	c.wg.Wait()
+}
+
+// Enqueue schedules Warm to run in the background and never blocks. Entries
+// already queued are skipped; when the queue is full the request is dropped
+// and false is returned.
+func (c *Cache) Enqueue(id int64, remote, videoPath string) bool {
+	if id <= 0 || (remote == "" && videoPath == "") {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, dup := c.pending[id]; dup {
+		return true
+	}
+	select {
+	case c.queue <- warmRequest{id: id, remote: remote, videoPath: videoPath}:
+		c.pending[id] = struct{}{}
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *Cache) worker() {
+	defer c.wg.Done()
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case req := <-c.queue:
+			_ = c.Warm(c.ctx, req.id, req.remote, req.videoPath)
+			c.mu.Lock()
+			delete(c.pending, req.id)
+			c.mu.Unlock()
+		}
+	}
+}
+
+// Path returns the file for the requested variant. Variants that were not
+// generated (small sources, undecodable formats) fall back to the original.
+func (c *Cache) Path(id int64, size Size) (string, error) {
+	dir := c.entryDir(id)
+	if size != SizeOriginal {
+		p := filepath.Join(dir, string(size)+".jpg")
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+	p := filepath.Join(dir, string(SizeOriginal))
+	if _, err := os.Stat(p); err != nil {
+		if os.IsNotExist(err) {
+			return "", ErrNotCached
+		}
+		return "", err
+	}
+	return p, nil
+}
+
+// Has reports whether a thumbnail is cached for id.
+func (c *Cache) Has(id int64) bool {
+	_, err := c.Path(id, SizeOriginal)
+	return err == nil
+}
+
+// Warm caches a thumbnail for id if none is stored yet: remote is fetched
+// first, and a frame is extracted from videoPath when that is missing or
+// fails. Recent failures are not retried until failureBackoff passes.
+func (c *Cache) Warm(ctx context.Context, id int64, remote, videoPath string) error {
+	if id <= 0 || (remote == "" && videoPath == "") {
+		return ErrNotCached
+	}
+	lock := c.lockFor(id)
+	lock.Lock()
+	defer lock.Unlock()
+	if c.Has(id) {
+		return nil
+	}
+	if c.recentlyFailed(id) {
+		return ErrNotCached
+	}
+
+	var err error
+	if remote != "" {
+		if err = c.fetch(ctx, id, remote); err == nil {
+			c.clearFailure(id)
+			return nil
+		}
+		slog.Debug("thumbnail fetch failed", "event", "thumbnail_fetch_error", "db_id", id, "url", logging.RedactURL(remote), "error", err)
+	}
+	if videoPath != "" {
+		if err = c.extract(ctx, id, videoPath); err == nil {
+			c.clearFailure(id)
+			return nil
+		}
+		slog.Debug("thumbnail frame extraction failed", "event", "thumbnail_extract_error", "db_id", id, "error", err)
+	}
+	if ctx.Err() == nil {
+		c.markFailure(id)
+	}
+	return err
+}
+
+// Remove deletes every cached file for id.
+func (c *Cache) Remove(id int64) error {
+	c.mu.Lock()
+	delete(c.failed, id)
+	c.mu.Unlock()
+	return os.RemoveAll(c.entryDir(id))
+}
+
+func (c *Cache) entryDir(id int64) string {
+	return filepath.Join(c.dir, strconv.FormatInt(id, 10))
+}
+
+func (c *Cache) lockFor(id int64) *sync.Mutex {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	l, ok := c.locks[id]
+	if !ok {
+		l = &sync.Mutex{}
+		c.locks[id] = l
+	}
+	return l
+}
+
+func (c *Cache) recentlyFailed(id int64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	at, ok := c.failed[id]
+	return ok && c.now().Sub(at) < failureBackoff
+}
+
+func (c *Cache) markFailure(id int64) {
+	c.mu.Lock()
+	c.failed[id] = c.now()
+	c.mu.Unlock()
+}
+
+func (c *Cache) clearFailure(id int64) {
+	c.mu.Lock()
+	delete(c.failed, id)
+	c.mu.Unlock()
+}
+
+// fetch downloads remote into the entry directory and builds the variants.
+func (c *Cache) fetch(ctx context.Context, id int64, remote string) error {
+	u, err := url.Parse(remote)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("unsupported thumbnail URL")
+	}
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("thumbnail fetch: unexpected status %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "" && !strings.HasPrefix(ct, "image/") {
+		return fmt.Errorf("thumbnail fetch: unexpected content type %q", ct)
+	}
+
+	tmp, err := c.tempFile(id)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	n, err := io.Copy(tmp, io.LimitReader(resp.Body, maxThumbnailBytes+1))
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if n > maxThumbnailBytes {
+		return fmt.Errorf("thumbnail larger than %d bytes", maxThumbnailBytes)
+	}
+	return c.install(ctx, id, tmp.Name())
+}
+
+// extract grabs a frame from a finished file with ffmpeg. Audio files with
+// embedded cover art yield the cover.
+func (c *Cache) extract(ctx context.Context, id int64, videoPath string) error {
+	if c.ffmpeg == "" {
+		return errors.New("ffmpeg not available")
+	}
+	if _, err := os.Stat(videoPath); err != nil {
+		return err
+	}
+	tmp, err := c.tempFile(id)
+	if err != nil {
+		return err
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	ctx, cancel := context.WithTimeout(ctx, ffmpegTimeout)
+	defer cancel()
+	// Skip intros and black leaders when the file is long enough; retry from
+	// the start for short clips where seeking past the end writes nothing.
+	var lastErr error
+	for _, offset := range []string{"10", "0"} {
+		cmd := exec.CommandContext(ctx, c.ffmpeg, "-v", "error", "-y", "-ss", offset, "-i", videoPath,
+			"-frames:v", "1", "-f", "image2", "-c:v", "mjpeg", tmp.Name())
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			lastErr = fmt.Errorf("ffmpeg: %w: %s", err, strings.TrimSpace(string(out)))
+			continue
+		}
+		if info, err := os.Stat(tmp.Name()); err == nil && info.Size() > 0 {
+			return c.install(ctx, id, tmp.Name())
+		}
+		lastErr = errors.New("ffmpeg produced no frame")
+	}
+	return lastErr
+}
+
+func (c *Cache) tempFile(id int64) (*os.File, error) {
+	dir := c.entryDir(id)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return os.CreateTemp(dir, ".incoming-*")
+}
+
+// install writes the variants for src and then moves it into place as the
+// original, so a present original always has its variants ready.
+func (c *Cache) install(ctx context.Context, id int64, src string) error {
+	dir := c.entryDir(id)
+	for size, width := range variantWidths {
+		if err := c.resize(ctx, src, filepath.Join(dir, string(size)+".jpg"), width); err != nil {
+			slog.Debug("thumbnail variant skipped", "event", "thumbnail_resize_skipped", "db_id", id, "size", size, "error", err)
+		}
+	}
+	return os.Rename(src, filepath.Join(dir, string(SizeOriginal)))
+}
+
+// errNoResize means the source is already small enough to serve as is.
+var errNoResize = errors.New("source narrower than variant")
+
+// resize writes a JPEG of the given width. It decodes with the standard
+// library and falls back to ffmpeg for other formats and for images above
+// maxDecodePixels, whose decoded size would dwarf the file's.
+func (c *Cache) resize(ctx context.Context, src, dst string, width int) error {
+	_ = os.Remove(dst)
+	img, decodeErr := decodeBounded(src)
+	if decodeErr == nil {
+		if img.Bounds().Dx() <= width {
+			return errNoResize
+		}
+		return writeJPEG(dst, scaleToWidth(img, width))
+	}
+	if c.ffmpeg == "" {
+		return decodeErr
+	}
+	ctx, cancel := context.WithTimeout(ctx, ffmpegTimeout)
+	defer cancel()
+	tmp := dst + ".tmp"
+	defer os.Remove(tmp)
+	// min() keeps narrow sources from being upscaled; -2 keeps the height even.
+	cmd := exec.CommandContext(ctx, c.ffmpeg, "-v", "error", "-y", "-i", src,
+		"-vf", fmt.Sprintf("scale='min(%d,iw)':-2", width), "-frames:v", "1", "-f", "image2", "-c:v", "mjpeg", tmp)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("ffmpeg resize: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return os.Rename(tmp, dst)
+}
+
+// decodeBounded decodes src after checking from its header that the image
+// stays within maxDecodePixels.
+func decodeBounded(src string) (image.Image, error) {
+	f, err := os.Open(src)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	cfg, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return nil, err
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > maxDecodePixels {
+		return nil, fmt.Errorf("image of %dx%d exceeds %d pixels", cfg.Width, cfg.Height, maxDecodePixels)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(f)
+	return img, err
+}
+
+func writeJPEG(dst string, img image.Image) error {
+	tmp := dst + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	err = jpeg.Encode(f, img, &jpeg.Options{Quality: 85})
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
+
+// scaleToWidth downsamples src with a box filter, preserving aspect ratio.
+func scaleToWidth(src image.Image, width int) image.Image {
+	b := src.Bounds()
+	height := b.Dy() * width / b.Dx()
+	if height < 1 {
+		height = 1
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := b.Min.Y + y*b.Dy()/height
+		y1 := b.Min.Y + (y+1)*b.Dy()/height
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < width; x++ {
+			x0 := b.Min.X + x*b.Dx()/width
+			x1 := b.Min.X + (x+1)*b.Dx()/width
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, bl, a = r+uint64(cr), g+uint64(cg), bl+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			i := dst.PixOffset(x, y)
+			dst.Pix[i+0] = uint8(r / n >> 8)
+			dst.Pix[i+1] = uint8(g / n >> 8)
+			dst.Pix[i+2] = uint8(bl / n >> 8)
+			dst.Pix[i+3] = uint8(a / n >> 8)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,235 @@
+package thumbs
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 200, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func jpegWidth(t *testing.T, path string) int {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open %s: %v", path, err)
+	}
+	defer f.Close()
+	cfg, err := jpeg.DecodeConfig(f)
+	if err != nil {
+		t.Fatalf("decode %s: %v", path, err)
+	}
+	return cfg.Width
+}
+
+func TestCache_WarmFetchesAndResizes(t *testing.T) {
+	body := testPNG(t, 800, 450)
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+
+	c, err := New(t.TempDir(), Options{})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	c.ffmpeg = ""
+	if _, err := c.Path(1, SizeSmall); err != ErrNotCached {
+		t.Fatalf("expected ErrNotCached before warm, got %v", err)
+	}
+	if err := c.Warm(context.Background(), 1, srv.URL+"/hq.png", ""); err != nil {
+		t.Fatalf("warm: %v", err)
+	}
+	for size, want := range map[Size]int{SizeSmall: 160, SizeMedium: 480} {
+		p, err := c.Path(1, size)
+		if err != nil {
+			t.Fatalf("path %s: %v", size, err)
+		}
+		if got := jpegWidth(t, p); got != want {
+			t.Fatalf("%s variant width = %d, want %d", size, got, want)
+		}
+	}
+	orig, _ := c.Path(1, SizeOriginal)
+	if data, _ := os.ReadFile(orig); !bytes.Equal(data, body) {
+		t.Fatalf("expected original stored unchanged")
+	}
+
+	// Cached entries are not fetched again.
+	if err := c.Warm(context.Background(), 1, srv.URL+"/hq.png", ""); err != nil || hits.Load() != 1 {
+		t.Fatalf("expected cached thumbnail reused, err=%v hits=%d", err, hits.Load())
+	}
+	if err := c.Remove(1); err != nil || c.Has(1) {
+		t.Fatalf("expected entry removed, err=%v", err)
+	}
+}
+
+func TestCache_SmallSourceServesOriginalForVariants(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(testPNG(t, 120, 90))
+	}))
+	defer srv.Close()
+	c, _ := New(t.TempDir(), Options{})
+	c.ffmpeg = ""
+	if err := c.Warm(context.Background(), 2, srv.URL, ""); err != nil {
+		t.Fatalf("warm: %v", err)
+	}
+	small, _ := c.Path(2, SizeSmall)
+	if filepath.Base(small) != string(SizeOriginal) {
+		t.Fatalf("expected original served for a source narrower than the variant, got %s", small)
+	}
+}
+
+func TestDecodeBounded_RefusesOversizedImages(t *testing.T) {
+	// A GIF header declaring 65535x65535 pixels in a few bytes.
+	bomb := filepath.Join(t.TempDir(), "bomb.gif")
+	if err := os.WriteFile(bomb, []byte("GIF89a\xff\xff\xff\xff\x00\x00\x00;"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := decodeBounded(bomb); err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Fatalf("expected the pixel budget to refuse the image, got %v", err)
+	}
+
+	small := filepath.Join(t.TempDir(), "small.png")
+	if err := os.WriteFile(small, testPNG(t, 32, 16), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	img, err := decodeBounded(small)
+	if err != nil || img.Bounds().Dx() != 32 {
+		t.Fatalf("expected a small image decoded, got %v", err)
+	}
+}
+
+func TestCache_EnqueueWarmsInBackgroundAndIsBounded(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(testPNG(t, 64, 36))
+	}))
+	defer srv.Close()
+	c, _ := New(t.TempDir(), Options{})
+	c.ffmpeg = ""
+
+	for id := int64(1); id <= warmQueueSize; id++ {
+		if !c.Enqueue(id, srv.URL, "") {
+			t.Fatalf("expected %d accepted", id)
+		}
+	}
+	if !c.Enqueue(1, srv.URL, "") {
+		t.Fatalf("expected a queued entry to be accepted again")
+	}
+	if c.Enqueue(warmQueueSize+1, srv.URL, "") {
+		t.Fatalf("expected a request beyond the queue to be dropped")
+	}
+
+	c.Start()
+	defer c.Stop()
+	deadline := time.Now().Add(5 * time.Second)
+	for !c.Has(1) && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !c.Has(1) {
+		t.Fatalf("expected the queued thumbnail warmed in the background")
+	}
+}
+
+func TestCache_FailuresBackOff(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if r.URL.Path == "/page" {
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<html></html>"))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+	c, _ := New(t.TempDir(), Options{})
+	c.ffmpeg = ""
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	if err := c.Warm(context.Background(), 3, srv.URL+"/gone.jpg", ""); err == nil {
+		t.Fatalf("expected fetch error")
+	}
+	if err := c.Warm(context.Background(), 3, srv.URL+"/gone.jpg", ""); err != ErrNotCached || hits.Load() != 1 {
+		t.Fatalf("expected retry suppressed, err=%v hits=%d", err, hits.Load())
+	}
+	now = now.Add(failureBackoff)
+	_ = c.Warm(context.Background(), 3, srv.URL+"/gone.jpg", "")
+	if hits.Load() != 2 {
+		t.Fatalf("expected retry after backoff, hits=%d", hits.Load())
+	}
+
+	if err := c.Warm(context.Background(), 4, srv.URL+"/page", ""); err == nil || c.Has(4) {
+		t.Fatalf("expected non-image response rejected, err=%v", err)
+	}
+	if err := c.Warm(context.Background(), 5, "file:///etc/passwd", ""); err == nil || c.Has(5) {
+		t.Fatalf("expected non-http URL rejected, err=%v", err)
+	}
+}
+
+func TestCache_ExtractsFrameWithFFmpeg(t *testing.T) {
+	dir := t.TempDir()
+	frame := filepath.Join(dir, "frame.png")
+	if err := os.WriteFile(frame, testPNG(t, 640, 360), 0o644); err != nil {
+		t.Fatalf("write frame: %v", err)
+	}
+	// The fake ffmpeg copies a fixed frame to its last argument.
+	fake := filepath.Join(dir, "ffmpeg")
+	script := "#!/bin/sh\nfor a; do last=\"$a\"; done\ncp '" + frame + "' \"$last\"\n"
+	if err := os.WriteFile(fake, []byte(script), 0o755); err != nil {
+		t.Fatalf("write fake ffmpeg: %v", err)
+	}
+	video := filepath.Join(dir, "clip.mp4")
+	if err := os.WriteFile(video, []byte("not really a video"), 0o644); err != nil {
+		t.Fatalf("write video: %v", err)
+	}
+
+	c, _ := New(filepath.Join(dir, "cache"), Options{FFmpeg: fake})
+	if err := c.Warm(context.Background(), 6, "", video); err != nil {
+		t.Fatalf("warm from video: %v", err)
+	}
+	if p, err := c.Path(6, SizeSmall); err != nil || jpegWidth(t, p) != 160 {
+		t.Fatalf("expected small variant from extracted frame, got %s (%v)", p, err)
+	}
+	if err := c.Warm(context.Background(), 7, "", filepath.Join(dir, "missing.mp4")); err == nil {
+		t.Fatalf("expected error for a missing file")
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	for in, want := range map[string]Size{"": SizeOriginal, "small": SizeSmall, "MEDIUM": SizeMedium, "original": SizeOriginal} {
+		if got, ok := ParseSize(in); !ok || got != want {
+			t.Errorf("ParseSize(%q) = %q, %v", in, got, ok)
+		}
+	}
+	if _, ok := ParseSize("huge"); ok {
+		t.Errorf("expected unknown size rejected")
+	}
+}
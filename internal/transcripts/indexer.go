@@ -0,0 +1,271 @@
+package transcripts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"videofetch/internal/download"
+	"videofetch/internal/logging"
+	"videofetch/internal/store"
+)
+
+// ErrNoSubtitles reports that neither the download nor the source site has a usable subtitle track.
+var ErrNoSubtitles = errors.New("no subtitles available")
+
+// DefaultLanguages is the yt-dlp --sub-langs value used when none is configured.
+const DefaultLanguages = "en.*,en"
+
+// fetchTimeout bounds one yt-dlp subtitle fetch.
+const fetchTimeout = 2 * time.Minute
+
+// IndexStore is the subset of store operations the indexer needs.
+type IndexStore interface {
+	GetDownloadByID(ctx context.Context, id int64) (store.Download, bool, error)
+	ReplaceTranscript(ctx context.Context, id int64, lang string, cues []store.TranscriptCue) error
+}
+
+// Options tunes the indexer.
+type Options struct {
+	// Languages is passed to yt-dlp --sub-langs; empty uses DefaultLanguages.
+	Languages string
+	// Workers is the number of concurrent fetches; 0 uses 1.
+	Workers int
+	// YTDLP is the yt-dlp binary; empty uses the binary the download manager runs.
+	YTDLP string
+}
+
+// Indexer fetches subtitles or auto-captions for completed downloads and
+// stores their cues. Subtitle files the download already produced are used
+// before asking yt-dlp for captions.
+type Indexer struct {
+	store     IndexStore
+	outputDir string
+	opts      Options
+
+	queue   chan int64
+	mu      sync.Mutex
+	pending map[int64]struct{}
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewIndexer creates an indexer. Rows without a recorded library root
+// resolve their files against outputDir.
+func NewIndexer(st IndexStore, outputDir string, opts Options) *Indexer {
+	if opts.Workers <= 0 {
+		opts.Workers = 1
+	}
+	if opts.Languages == "" {
+		opts.Languages = DefaultLanguages
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Indexer{
+		store:     st,
+		outputDir: outputDir,
+		opts:      opts,
+		queue:     make(chan int64, 1024),
+		pending:   make(map[int64]struct{}),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// Start launches the workers.
+func (ix *Indexer) Start() {
+	for i := 0; i < ix.opts.Workers; i++ {
+		ix.wg.Add(1)
+		go ix.worker()
+	}
+}
+
+// Stop cancels in-flight fetches and waits for workers to exit.
+func (ix *Indexer) Stop() {
+	ix.cancel()
+	ix.wg.Wait()
+}
+
+// Enqueue schedules a row for indexing. Rows already queued are skipped.
+func (ix *Indexer) Enqueue(dbID int64) {
+	if dbID <= 0 {
+		return
+	}
+	ix.mu.Lock()
+	if _, dup := ix.pending[dbID]; dup {
+		ix.mu.Unlock()
+		return
+	}
+	ix.pending[dbID] = struct{}{}
+	ix.mu.Unlock()
+	select {
+	case ix.queue <- dbID:
+	case <-ix.ctx.Done():
+	}
+}
+
+func (ix *Indexer) worker() {
+	defer ix.wg.Done()
+	for {
+		select {
+		case <-ix.ctx.Done():
+			return
+		case id := <-ix.queue:
+			if err := ix.Index(ix.ctx, id); err != nil && !errors.Is(err, context.Canceled) {
+				level := slog.LevelWarn
+				if errors.Is(err, ErrNoSubtitles) {
+					level = slog.LevelInfo
+				}
+				slog.Log(ix.ctx, level, "transcript not indexed", "event", "transcript_error", "db_id", id, "error", err)
+			}
+			ix.mu.Lock()
+			delete(ix.pending, id)
+			ix.mu.Unlock()
+		}
+	}
+}
+
+// Index fetches and stores the transcript of one download. Rows that are
+// missing or not completed are skipped.
+func (ix *Indexer) Index(ctx context.Context, id int64) error {
+	row, found, err := ix.store.GetDownloadByID(ctx, id)
+	if err != nil {
+		logging.LogDBOperation("get_download", id, err)
+		return err
+	}
+	if !found || row.Status != "completed" {
+		return nil
+	}
+
+	tracks := ix.localTracks(row)
+	if len(tracks) == 0 {
+		dir, err := os.MkdirTemp("", "videofetch-subs-*")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+		if tracks, err = ix.fetch(ctx, row.URL, dir); err != nil {
+			return err
+		}
+	}
+
+	start := time.Now()
+	for _, path := range tracks {
+		cues, err := parseFile(path)
+		if err != nil {
+			slog.Warn("failed to parse subtitle track", "event", "transcript_parse_error", "db_id", id, "path", path, "error", err)
+			continue
+		}
+		if len(cues) == 0 {
+			continue
+		}
+		lang := trackLanguage(path)
+		if err := ix.store.ReplaceTranscript(ctx, id, lang, cues); err != nil {
+			logging.LogDBOperation("replace_transcript", id, err)
+			return err
+		}
+		slog.Info("transcript indexed",
+			"event", "transcript_indexed",
+			"db_id", id,
+			"lang", lang,
+			"cues", len(cues),
+			"duration_ms", time.Since(start).Milliseconds())
+		return nil
+	}
+	return ErrNoSubtitles
+}
+
+// localTracks lists subtitle files recorded as artifacts of the row.
+func (ix *Indexer) localTracks(row store.Download) []string {
+	root := row.LibraryRoot
+	if root == "" {
+		root = ix.outputDir
+	}
+	var out []string
+	for _, p := range row.ArtifactPaths {
+		if !isSubtitleFile(p) {
+			continue
+		}
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(root, p)
+		}
+		if _, err := os.Stat(p); err == nil {
+			out = append(out, p)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// fetch asks yt-dlp for subtitles, falling back to auto-captions, without downloading the media.
+func (ix *Indexer) fetch(ctx context.Context, url, dir string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+	bin := ix.opts.YTDLP
+	if bin == "" {
+		bin = download.YTDLPPath()
+	}
+	cmd := exec.CommandContext(ctx, bin,
+		"--skip-download", "--write-subs", "--write-auto-subs",
+		"--sub-langs", ix.opts.Languages, "--sub-format", "vtt/srt/best",
+		"--no-playlist", "-o", filepath.Join(dir, "track.%(ext)s"),
+		"--", url)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("yt-dlp subtitles: %w: %s", err, lastLine(string(out)))
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	for _, e := range entries {
+		if !e.IsDir() && isSubtitleFile(e.Name()) {
+			out = append(out, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func parseFile(path string) ([]store.TranscriptCue, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+func isSubtitleFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".vtt", ".srt":
+		return true
+	}
+	return false
+}
+
+// trackLanguage reads the language from yt-dlp's "<name>.<lang>.<ext>" naming.
+func trackLanguage(path string) string {
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if i := strings.LastIndexByte(base, '.'); i >= 0 {
+		return base[i+1:]
+	}
+	return ""
+}
+
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,93 @@
+package transcripts
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"videofetch/internal/store"
+)
+
+func newIndexStore(t *testing.T) *store.Store {
+	t.Helper()
+	st, err := store.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { _ = st.Close() })
+	return st
+}
+
+func completedRow(t *testing.T, st *store.Store, root, url string, artifacts ...string) int64 {
+	t.Helper()
+	ctx := context.Background()
+	id, err := st.CreateDownloadInLibrary(ctx, url, "Lecture", 0, "", "completed", 100, "default", root)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	_ = st.UpdateFilename(ctx, id, "lecture.mp4")
+	_ = st.UpdateArtifacts(ctx, id, artifacts)
+	return id
+}
+
+// fakeYTDLP writes a script that saves a caption track where -o points.
+func fakeYTDLP(t *testing.T, track string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "track.vtt"), []byte(track), 0o644); err != nil {
+		t.Fatalf("write track: %v", err)
+	}
+	script := "#!/bin/sh\nwhile [ $# -gt 0 ]; do\n  if [ \"$1\" = \"-o\" ]; then out=\"$2\"; fi\n  shift\ndone\n" +
+		"[ -n \"$out\" ] || exit 2\n" +
+		"cp '" + filepath.Join(dir, "track.vtt") + "' \"$(dirname \"$out\")/track.en-orig.vtt\"\n"
+	bin := filepath.Join(dir, "yt-dlp")
+	if err := os.WriteFile(bin, []byte(script), 0o755); err != nil {
+		t.Fatalf("write fake yt-dlp: %v", err)
+	}
+	return bin
+}
+
+func TestIndexer_FetchesCaptionsAndMakesThemSearchable(t *testing.T) {
+	st := newIndexStore(t)
+	root := t.TempDir()
+	id := completedRow(t, st, root, "https://example.com/lecture")
+	bin := fakeYTDLP(t, "WEBVTT\n\n00:12:00.000 --> 00:12:04.000\nhandling backpressure in streams\n")
+
+	ix := NewIndexer(st, root, Options{YTDLP: bin})
+	if err := ix.Index(context.Background(), id); err != nil {
+		t.Fatalf("index: %v", err)
+	}
+	row, _, _ := st.GetDownloadByID(context.Background(), id)
+	if row.TranscriptLang != "en-orig" {
+		t.Fatalf("expected language recorded, got %q", row.TranscriptLang)
+	}
+	hits, err := st.SearchTranscripts(context.Background(), "backpressure", 10)
+	if err != nil || len(hits) != 1 || hits[0].Download.ID != id || hits[0].Cues[0].StartMS != 720000 {
+		t.Fatalf("expected cue at 12:00 found, got %+v (%v)", hits, err)
+	}
+}
+
+func TestIndexer_PrefersLocalTracksAndReportsMissingSubtitles(t *testing.T) {
+	st := newIndexStore(t)
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "lecture.de.srt"), []byte("1\n00:00:01,000 --> 00:00:02,000\nGegendruck\n"), 0o644); err != nil {
+		t.Fatalf("write srt: %v", err)
+	}
+	local := completedRow(t, st, root, "https://example.com/a", "lecture.mp4", "lecture.de.srt")
+	// A binary that cannot run proves the local track was used.
+	ix := NewIndexer(st, root, Options{YTDLP: filepath.Join(root, "missing-yt-dlp")})
+	if err := ix.Index(context.Background(), local); err != nil {
+		t.Fatalf("index local: %v", err)
+	}
+	if cues, _ := st.TranscriptCues(context.Background(), local); len(cues) != 1 || cues[0].Text != "Gegendruck" {
+		t.Fatalf("expected local track indexed, got %+v", cues)
+	}
+
+	bare := completedRow(t, st, root, "https://example.com/b")
+	ix = NewIndexer(st, root, Options{YTDLP: fakeYTDLP(t, "WEBVTT\n\n")})
+	if err := ix.Index(context.Background(), bare); !errors.Is(err, ErrNoSubtitles) {
+		t.Fatalf("expected ErrNoSubtitles, got %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+// Package transcripts fetches subtitle tracks for completed downloads and
+// indexes their cues for full-text search.
+package transcripts
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+
+	"videofetch/internal/store"
+)
+
+// Parse reads a WebVTT or SubRip track into cues. Markup is stripped, and
+// lines repeated from the previous cue (as in rolling auto-captions) are
+// dropped so each spoken line is indexed once.
+func Parse(r io.Reader) ([]store.TranscriptCue, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var cues []store.TranscriptCue
+	var cur *store.TranscriptCue
+	var text []string
+	last := ""
+	flush := func() {
+		if cur != nil && len(text) > 0 {
+			cur.Text = strings.Join(text, " ")
+			cues = append(cues, *cur)
+		}
+		cur, text = nil, nil
+	}
+	for sc.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(sc.Text(), "\uFEFF"))
+		if line == "" {
+			flush()
+			continue
+		}
+		if strings.Contains(line, "-->") {
+			flush()
+			start, end, err := parseTiming(line)
+			if err != nil {
+				return nil, err
+			}
+			cur = &store.TranscriptCue{StartMS: start, EndMS: end}
+			continue
+		}
+		if cur == nil {
+			// Header, NOTE/STYLE blocks and SubRip sequence numbers.
+			continue
+		}
+		line = cleanLine(line)
+		if line == "" || line == last {
+			continue
+		}
+		text = append(text, line)
+		last = line
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	return cues, nil
+}
+
+// parseTiming reads "start --> end [settings]".
+func parseTiming(line string) (int64, int64, error) {
+	left, right, _ := strings.Cut(line, "-->")
+	fields := strings.Fields(right)
+	if len(fields) == 0 {
+		return 0, 0, fmt.Errorf("invalid cue timing %q", line)
+	}
+	start, err := parseTimestamp(strings.TrimSpace(left))
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := parseTimestamp(fields[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	return start, end, nil
+}
+
+// parseTimestamp reads [hh:]mm:ss.mmm (WebVTT) or hh:mm:ss,mmm (SubRip) into milliseconds.
+func parseTimestamp(s string) (int64, error) {
+	parts := strings.Split(strings.Replace(s, ",", ".", 1), ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid timestamp %q", s)
+	}
+	var frac string
+	parts[len(parts)-1], frac, _ = strings.Cut(parts[len(parts)-1], ".")
+	var ms int64
+	for _, p := range parts {
+		n, err := strconv.ParseInt(p, 10, 64)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid timestamp %q", s)
+		}
+		ms = ms*60 + n
+	}
+	ms *= 1000
+	if frac != "" {
+		for len(frac) < 3 {
+			frac += "0"
+		}
+		n, err := strconv.ParseInt(frac[:3], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid timestamp %q", s)
+		}
+		ms += n
+	}
+	return ms, nil
+}
+
+// cleanLine drops tags such as <c>, <i> and inline <00:00:01.000> timestamps,
+// decodes entities and collapses whitespace.
+func cleanLine(line string) string {
+	var b strings.Builder
+	depth := 0
+	for _, r := range line {
+		switch {
+		case r == '<':
+			depth++
+		case r == '>' && depth > 0:
+			depth--
+		case depth == 0:
+			b.WriteRune(r)
+		}
+	}
+	return strings.Join(strings.Fields(html.UnescapeString(b.String())), " ")
+}
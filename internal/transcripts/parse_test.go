@@ -0,0 +1,54 @@
+package transcripts
+
+import (
+	"strings"
+	"testing"
+
+	"videofetch/internal/store"
+)
+
+func TestParse_WebVTTAutoCaptions(t *testing.T) {
+	vtt := "\uFEFFWEBVTT\nKind: captions\nLanguage: en\n\n" +
+		"NOTE generated\n\n" +
+		"00:00:01.000 --> 00:00:03.500 align:start position:0%\n" +
+		"we<00:00:01.500><c> talk</c><00:00:02.000><c> about</c>\n\n" +
+		"00:00:03.500 --> 00:00:06.000 align:start position:0%\n" +
+		"we talk about\n" +
+		"<c.colorE5E5E5>backpressure</c> &amp; queues\n\n" +
+		"1:02:03.250 --> 1:02:04.000\n" +
+		"<v Speaker>the end</v>\n"
+	cues, err := Parse(strings.NewReader(vtt))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	want := []store.TranscriptCue{
+		{StartMS: 1000, EndMS: 3500, Text: "we talk about"},
+		{StartMS: 3500, EndMS: 6000, Text: "backpressure & queues"},
+		{StartMS: 3723250, EndMS: 3724000, Text: "the end"},
+	}
+	if len(cues) != len(want) {
+		t.Fatalf("got %+v, want %+v", cues, want)
+	}
+	for i := range want {
+		if cues[i] != want[i] {
+			t.Errorf("cue %d = %+v, want %+v", i, cues[i], want[i])
+		}
+	}
+}
+
+func TestParse_SubRip(t *testing.T) {
+	srt := "1\r\n00:00:00,500 --> 00:00:02,000\r\n<i>Hello</i>\r\nthere\r\n\r\n2\r\n00:01:00,000 --> 00:01:01,000\r\nGeneral Kenobi\r\n"
+	cues, err := Parse(strings.NewReader(srt))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(cues) != 2 || cues[0] != (store.TranscriptCue{StartMS: 500, EndMS: 2000, Text: "Hello there"}) || cues[1].StartMS != 60000 {
+		t.Fatalf("unexpected cues %+v", cues)
+	}
+}
+
+func TestParse_InvalidTiming(t *testing.T) {
+	if _, err := Parse(strings.NewReader("WEBVTT\n\nxx:01 --> 00:02.000\nhi\n")); err == nil {
+		t.Fatalf("expected error for a malformed timestamp")
+	}
+}
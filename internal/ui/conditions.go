@@ -0,0 +1,7 @@
+package ui
+
+// Condition is a service-level warning rendered above the dashboard queue.
+type Condition struct {
+	Name    string
+	Message string
+}
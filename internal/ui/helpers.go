@@ -1,6 +1,11 @@
 package ui
 
-import "unicode/utf8"
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"videofetch/internal/download"
+)
 
 // ShortID trims a long hex ID for display in the dashboard table.
 // Handles UTF-8 properly by counting runes, not bytes.
@@ -20,3 +25,22 @@ func ShortID(id string) string {
 	}
 	return id
 }
+
+// ThumbnailSrc returns the locally cached thumbnail URL for an item, or ""
+// when the item has no database row or nothing to build a thumbnail from.
+func ThumbnailSrc(it *download.Item) string {
+	if it.DBID <= 0 || (it.ThumbnailURL == "" && it.State != download.StateCompleted) {
+		return ""
+	}
+	return fmt.Sprintf("/api/thumbnail/%d?size=small", it.DBID)
+}
+
+// ChapterURL links to one piece of a download split by chapters; inline
+// links play it in the browser instead of saving it.
+func ChapterURL(it *download.Item, c download.ChapterFile, inline bool) string {
+	link := fmt.Sprintf("/api/download_file?id=%d&chapter=%d", it.DBID, c.Number)
+	if inline {
+		link += "&inline=1"
+	}
+	return link
+}
@@ -1,6 +1,10 @@
 package ui
 
-import "testing"
+import (
+	"testing"
+
+	"videofetch/internal/download"
+)
 
 func TestShortID(t *testing.T) {
 	tests := []struct {
@@ -114,3 +118,34 @@ func TestShortID_Performance(t *testing.T) {
 		t.Errorf("ShortID result length should be 8, got %d", len(result))
 	}
 }
+
+func TestThumbnailSrc(t *testing.T) {
+	tests := []struct {
+		name string
+		it   download.Item
+		want string
+	}{
+		{"remote thumbnail", download.Item{DBID: 3, ThumbnailURL: "https://img.example/x.jpg"}, "/api/thumbnail/3?size=small"},
+		{"completed without thumbnail", download.Item{DBID: 4, State: download.StateCompleted}, "/api/thumbnail/4?size=small"},
+		{"pending without thumbnail", download.Item{DBID: 5, State: download.StateQueued}, ""},
+		{"no database row", download.Item{ThumbnailURL: "https://img.example/x.jpg"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ThumbnailSrc(&tt.it); got != tt.want {
+				t.Errorf("ThumbnailSrc() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatTimestampAndCueLink(t *testing.T) {
+	for in, want := range map[int64]string{0: "0:00", 65: "1:05", 720: "12:00", 3723: "1:02:03", -4: "0:00"} {
+		if got := FormatTimestamp(in); got != want {
+			t.Errorf("FormatTimestamp(%d) = %q, want %q", in, got, want)
+		}
+	}
+	if got := CueLink(7, 90); got != "/api/download_file?id=7&inline=1#t=90" {
+		t.Errorf("CueLink() = %q", got)
+	}
+}
@@ -0,0 +1,7 @@
+package ui
+
+// Tag is a label shown in the dashboard tag sidebar with its download count.
+type Tag struct {
+	Name  string
+	Count int64
+}
@@ -0,0 +1,34 @@
+package ui
+
+import "fmt"
+
+// TranscriptResult is a download whose transcript matched a dashboard search.
+type TranscriptResult struct {
+	DBID     int64
+	Title    string
+	Playable bool // the local file can be opened at a timestamp
+	Cues     []TranscriptCue
+}
+
+// TranscriptCue is a matched cue; Snippet is HTML-escaped apart from <mark> tags.
+type TranscriptCue struct {
+	Seconds int64
+	Snippet string
+}
+
+// FormatTimestamp renders seconds as m:ss, or h:mm:ss from one hour on.
+func FormatTimestamp(seconds int64) string {
+	if seconds < 0 {
+		seconds = 0
+	}
+	h, m, s := seconds/3600, seconds/60%60, seconds%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%d:%02d", m, s)
+}
+
+// CueLink deep-links into the download file at the cue's start.
+func CueLink(dbID, seconds int64) string {
+	return fmt.Sprintf("/api/download_file?id=%d&inline=1#t=%d", dbID, seconds)
+}